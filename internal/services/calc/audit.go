@@ -0,0 +1,52 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// audit.go tells which cells a formula reads and which formulas read a cell, for formula auditing
+
+package calc
+
+import "sort"
+
+// Precedents returns the cells the formula at key reads, in sheet and position order. Of the large
+// ranges kept as blocks, only the stored cells are returned.
+func (wb *Workbook) Precedents(key CellKey) []CellKey {
+	var keys []CellKey
+	seen := make(map[CellKey]bool)
+	wb.dropIndexes()
+	wb.graph.eachPrecedent(key, func(p CellKey) bool {
+		if !seen[p] {
+			seen[p] = true
+			keys = append(keys, p)
+		}
+		return true
+	})
+	wb.sortKeys(keys)
+	return keys
+}
+
+// Dependents returns the formula cells which read the cell at key, through a reference or a range, in
+// sheet and position order
+func (wb *Workbook) Dependents(key CellKey) []CellKey {
+	var keys []CellKey
+	wb.graph.eachDependent(key, func(dep CellKey) {
+		keys = append(keys, dep)
+	})
+	wb.sortKeys(keys)
+	return keys
+}
+
+// sortKeys orders cells by the position of their sheet in the workbook, then by row and column
+func (wb *Workbook) sortKeys(keys []CellKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.Sheet != b.Sheet {
+			return wb.sheetIndex(a.Sheet) < wb.sheetIndex(b.Sheet)
+		}
+		if a.Row != b.Row {
+			return a.Row < b.Row
+		}
+		return a.Col < b.Col
+	})
+}
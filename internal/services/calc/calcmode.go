@@ -0,0 +1,70 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// calcmode.go keeps track of the formulas a manual workbook has not recalculated yet and of the volatile ones
+
+package calc
+
+import "gosheet/internal/services/cell"
+
+// Stale reports whether formulas show results older than the cells they read, because the workbook is
+// calculated manually and was changed since its last recalculation
+func (wb *Workbook) Stale() bool {
+	return len(wb.pending) > 0
+}
+
+// RecalculateSheet evaluates every formula of sheet again, in dependency order, reading the cells of other
+// sheets as they are. A manual workbook recalculates the sheet in view this way.
+func (wb *Workbook) RecalculateSheet(sheet *Sheet) {
+	if sheet == nil {
+		return
+	}
+
+	keys := make(map[CellKey]bool)
+	for _, c := range sheet.Data {
+		if c.IsFormula() {
+			key := CellKey{Sheet: sheet, Row: c.Row, Col: c.Column}
+			delete(wb.cycles, key)
+			c.ClearFlag(cell.FlagEvaluated)
+			keys[key] = true
+		}
+	}
+
+	wb.evaluateOrdered(keys)
+	wb.settleSpills()
+}
+
+// volatileKeys returns the formulas of the workbook which call a volatile function
+func (wb *Workbook) volatileKeys() []CellKey {
+	var keys []CellKey
+	for key := range wb.graph.volatile {
+		if wb.sheetIndex(key.Sheet) >= 0 {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// holdDependents takes the formulas which are not among the changed cells out of dirty and keeps them
+// waiting for a recalculation, for manual mode
+func (wb *Workbook) holdDependents(changed []CellKey, dirty map[CellKey]bool) {
+	roots := make(map[CellKey]bool, len(changed))
+	for _, key := range changed {
+		roots[key] = true
+	}
+
+	for key := range dirty {
+		if roots[key] {
+			continue
+		}
+		delete(dirty, key)
+		if c := cellAt(key); c != nil && c.IsFormula() {
+			if wb.pending == nil {
+				wb.pending = make(map[CellKey]bool)
+			}
+			wb.pending[key] = true
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// dynamic.go resolves references computed while a formula runs (INDIRECT, OFFSET) and links them as dependencies,
+// draws the random numbers of RAND and RANDBETWEEN and describes the cells a formula is in and refers to
+
+package calc
+
+import (
+	"errors"
+	"gosheet/internal/utils"
+	"gosheet/internal/utils/evaluatefuncs"
+	"hash/fnv"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// contextParam is the formula parameter carrying the resolver of the cell being evaluated
+const contextParam = "EVAL_CONTEXT"
+
+// resolver lets the functions of one formula reach cells by computed references and remembers what they reached.
+// cell is where the formula is, when it is in a cell; random draws its numbers once the workbook fixed a seed.
+type resolver struct {
+	wb     *Workbook
+	home   *Sheet
+	cell   CellKey
+	cells  []CellKey
+	areas  []cellRange
+	cycle  *CycleError
+	random *rand.Rand
+}
+
+// Reference resolves reference text like "B2", "Sheet2!A1:C3" or a defined name, as seen from the formula's sheet
+func (r *resolver) Reference(ref string) (evaluatefuncs.Range, error) {
+	if named, _ := r.wb.FindName(ref); named != nil {
+		ref = named.Value
+	}
+	if ref == "" {
+		return nil, evaluatefuncs.ErrRef
+	}
+
+	if strings.Contains(ref, ":") {
+		rng, err := r.wb.resolveRange(r.home, ref)
+		if err != nil {
+			return nil, evaluatefuncs.ErrorValue{Code: evaluatefuncs.ErrRef.Code, Reason: err.Error()}
+		}
+		return r.reach(rng)
+	}
+
+	sheet, row, col, err := r.wb.resolveRef(r.home, ref)
+	if err != nil {
+		return nil, evaluatefuncs.ErrorValue{Code: evaluatefuncs.ErrRef.Code, Reason: err.Error()}
+	}
+	return r.reach(cellRange{Sheet: sheet, Top: row, Left: col, Bottom: row, Right: col})
+}
+
+// Offset returns the block of height x width cells moved rows down and cols right from base
+func (r *resolver) Offset(base evaluatefuncs.Range, rows, cols, height, width int) (evaluatefuncs.Range, error) {
+	from, ok := base.(rangeValue)
+	if !ok {
+		return nil, evaluatefuncs.ErrorValue{Code: evaluatefuncs.ErrValue.Code, Reason: "OFFSET: the first argument must be a reference"}
+	}
+
+	top, left := int64(from.rng.Top)+int64(rows), int64(from.rng.Left)+int64(cols)
+	bottom, right := top+int64(height)-1, left+int64(width)-1
+	if top < 1 || left < 1 || bottom > int64(utils.MAX_ROWS) || right > int64(utils.MAX_COLS) {
+		return nil, evaluatefuncs.ErrorValue{Code: evaluatefuncs.ErrRef.Code, Reason: "OFFSET: the result is outside of the sheet"}
+	}
+
+	return r.reach(cellRange{Sheet: from.rng.Sheet, Top: int32(top), Left: int32(left), Bottom: int32(bottom), Right: int32(right)})
+}
+
+// Random returns the next random number of the formula. With a seed, every cell draws the same numbers on
+// every recalculation, whatever order the cells are calculated in.
+func (r *resolver) Random() float64 {
+	if r.wb.Calc.Seed == 0 {
+		return rand.Float64()
+	}
+	if r.random == nil {
+		h := fnv.New64a()
+		h.Write([]byte(r.cell.String()))
+		r.random = rand.New(rand.NewPCG(uint64(r.wb.Calc.Seed), h.Sum64()))
+	}
+	return r.random.Float64()
+}
+
+// Caller describes the cell the formula is in, false when it is not in a cell
+func (r *resolver) Caller() (evaluatefuncs.CellInfo, bool) {
+	if r.cell.Sheet == nil {
+		return evaluatefuncs.CellInfo{}, false
+	}
+	return r.describe(r.cell), true
+}
+
+// Describe describes the top-left cell of a reference, false when ref holds computed values instead of cells
+func (r *resolver) Describe(ref evaluatefuncs.Range) (evaluatefuncs.CellInfo, bool) {
+	from, ok := ref.(rangeValue)
+	if !ok || from.rng.Sheet == nil {
+		return evaluatefuncs.CellInfo{}, false
+	}
+	return r.describe(CellKey{Sheet: from.rng.Sheet, Row: from.rng.Top, Col: from.rng.Left}), true
+}
+
+// Evaluate calculates the top-left cell of a reference the formula passed by its address alone, like the A1
+// of CELL("contents", A1), and links it as a dependency of the formula
+func (r *resolver) Evaluate(ref evaluatefuncs.Range) error {
+	from, ok := ref.(rangeValue)
+	if !ok || from.rng.Sheet == nil {
+		return nil
+	}
+	_, err := r.reach(cellRange{Sheet: from.rng.Sheet, Top: from.rng.Top, Left: from.rng.Left, Bottom: from.rng.Top, Right: from.rng.Left})
+	return err
+}
+
+// describe gathers what the information functions tell about a cell, empty or not
+func (r *resolver) describe(key CellKey) evaluatefuncs.CellInfo {
+	address := "$" + utils.ColumnName(key.Col) + "$" + strconv.Itoa(int(key.Row))
+	if key.Sheet != r.home {
+		address = QualifyRef(key.Sheet.Name, address)
+	}
+	info := evaluatefuncs.CellInfo{
+		Address: address,
+		Sheet:   key.Sheet.Name,
+		File:    r.wb.CurrentFile,
+		Row:     int(key.Row),
+		Col:     int(key.Col),
+	}
+
+	if c := cellAt(key); c != nil {
+		if c.IsFormula() && c.RawValue != nil {
+			info.Formula = *c.RawValue
+		}
+		info.Value = cellValue(c)
+		if c.Type != nil {
+			info.Type = *c.Type
+		}
+		info.Decimals = int(c.DecimalPoints)
+	}
+	return info
+}
+
+// reach evaluates the formulas inside the block and records it as a dependency of the formula
+func (r *resolver) reach(rng cellRange) (evaluatefuncs.Range, error) {
+	if rng.size() > maxLinkedRangeCells {
+		r.areas = append(r.areas, rng)
+	} else {
+		for row := rng.Top; row <= rng.Bottom; row++ {
+			for col := rng.Left; col <= rng.Right; col++ {
+				r.cells = append(r.cells, CellKey{Sheet: rng.Sheet, Row: row, Col: col})
+			}
+		}
+	}
+
+	if err := r.wb.evaluateRange(rng); err != nil {
+		var cycle *CycleError
+		if errors.As(err, &cycle) && r.cycle == nil {
+			r.cycle = cycle
+		}
+		return nil, evaluatefuncs.ErrorValue{Code: evaluatefuncs.ErrCirc.Code, Reason: err.Error()}
+	}
+	return rangeValue{rng: rng}, nil
+}
@@ -0,0 +1,317 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// evalsteps.go evaluates a formula one sub-expression at a time, for the Evaluate Formula dialog
+
+package calc
+
+import (
+	"fmt"
+	"gosheet/internal/services/cell"
+	"gosheet/internal/utils"
+	"gosheet/internal/utils/evaluatefuncs"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/parser"
+)
+
+// EvalStep is a sub-expression of a formula and what it evaluates to. The steps of a formula form its
+// expression tree: the whole formula first, each step holding the sub-expressions it is computed from.
+type EvalStep struct {
+	Text    string      // the sub-expression, written with the references of the formula
+	Value   string      // what it evaluates to, or its error code
+	Err     error       // why it failed, nil when it did not
+	Culprit bool        // the innermost sub-expression the error of the formula comes from
+	Steps   []*EvalStep // the sub-expressions it is computed from
+}
+
+// Failed reports whether the sub-expression evaluates to an error
+func (s *EvalStep) Failed() bool {
+	return s.Err != nil
+}
+
+// stepper evaluates the sub-expressions of one evaluable formula with the parameters it was built with
+type stepper struct {
+	wb         *Workbook
+	home       *Sheet
+	parameters map[string]any
+}
+
+// EvaluateSteps returns the expression tree of the formula in c, every sub-expression evaluated on its
+// own with the same cell values the formula reads. Neither c nor the dependency graph is changed. When
+// the formula fails, the steps leading to the innermost failing sub-expression are marked as well.
+func (wb *Workbook) EvaluateSteps(c *cell.Cell) (*EvalStep, error) {
+	if !c.IsFormula() || c.RawValue == nil {
+		return nil, fmt.Errorf("cell %s holds no formula", utils.FormatCellRef(c.Row, c.Column))
+	}
+	home := wb.SheetOfCell(c)
+	if home == nil {
+		return nil, fmt.Errorf("no active sheet")
+	}
+
+	wb.dropIndexes()
+	formula := wb.expandNames(c.GetFormulaExpression())
+	root := &EvalStep{Text: formula}
+
+	key := CellKey{Sheet: home, Row: c.Row, Col: c.Column}
+	s := &stepper{wb: wb, home: home, parameters: map[string]any{contextParam: &resolver{wb: wb, home: home, cell: key}}}
+	evaluable, err := wb.buildEvaluableFormula(home, formula, s.parameters)
+	if err != nil {
+		root.Value, root.Err, root.Culprit = previewErrorCode(err), err, true
+		return root, nil
+	}
+
+	tree, err := parser.Parse(evaluable)
+	if err != nil {
+		root.Value, root.Err, root.Culprit = evaluatefuncs.ErrError.Code, err, true
+		return root, nil
+	}
+
+	root = s.step(tree.Node, "")
+	if root.Failed() {
+		markCulprit(root)
+	}
+	return root, nil
+}
+
+// step evaluates node and, before it, the sub-expressions it is computed from. scope holds the lets the
+// node is inside of, so the names of a LET can be evaluated along with the sub-expressions using them.
+func (s *stepper) step(node ast.Node, scope string) *EvalStep {
+	step := &EvalStep{Text: s.label(node.String())}
+
+	// A reference shows the value the formula reads, without being evaluated
+	if ident, ok := node.(*ast.IdentifierNode); ok {
+		if value, isParam := s.parameters[ident.Value]; isParam {
+			step.Text = s.paramLabel(ident.Value, value)
+			step.Value = formatStepValue(value)
+			if ev, isError := value.(evaluatefuncs.ErrorValue); isError {
+				if ev.Reason == "" {
+					ev.Reason = fmt.Sprintf("%s shows %s", step.Text, ev.Code)
+				}
+				step.Err = ev
+			}
+			return step
+		}
+	}
+
+	for _, child := range stepChildren(node) {
+		switch n := child.(type) {
+		case *ast.VariableDeclaratorNode:
+			// A LET name shows the value given to it, the calculation after it sees the name
+			value := s.step(n.Value, scope)
+			value.Text = s.label(n.Name) + " = " + value.Text
+			step.Steps = append(step.Steps, value)
+			step.Steps = append(step.Steps, s.step(n.Expr, scope+"let "+n.Name+" = "+n.Value.String()+"; "))
+		default:
+			step.Steps = append(step.Steps, s.step(child, scope))
+		}
+	}
+
+	// Like a cell, a number out of range is #NUM!
+	result, err := evaluateExpression(scope+node.String(), s.parameters)
+	if err == nil {
+		switch v := result.(type) {
+		case evaluatefuncs.ErrorValue:
+			err = v
+		case float64:
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				err = evaluatefuncs.ErrNum
+			}
+		}
+	}
+	if err != nil {
+		step.Value, step.Err = evaluatefuncs.ErrorOf(err).Code, err
+		return step
+	}
+	step.Value = formatStepValue(result)
+	return step
+}
+
+// stepChildren returns the sub-expressions worth a step of their own: literals and the names of called
+// functions are left out, as is the hidden first argument of the functions which compute references
+func stepChildren(node ast.Node) []ast.Node {
+	var children []ast.Node
+	add := func(nodes ...ast.Node) {
+		for _, n := range nodes {
+			switch child := n.(type) {
+			case nil, *ast.NilNode, *ast.IntegerNode, *ast.FloatNode, *ast.BoolNode, *ast.StringNode, *ast.ConstantNode:
+				continue
+			case *ast.IdentifierNode:
+				if child.Value == contextParam {
+					continue
+				}
+			}
+			children = append(children, n)
+		}
+	}
+
+	switch n := node.(type) {
+	case *ast.UnaryNode:
+		add(n.Node)
+	case *ast.BinaryNode:
+		add(n.Left, n.Right)
+	case *ast.ChainNode:
+		add(n.Node)
+	case *ast.CallNode:
+		add(n.Arguments...)
+	case *ast.BuiltinNode:
+		add(n.Arguments...)
+	case *ast.ConditionalNode:
+		add(n.Cond, n.Exp1, n.Exp2)
+	case *ast.ArrayNode:
+		add(n.Nodes...)
+	case *ast.SequenceNode:
+		add(n.Nodes...)
+	case *ast.VariableDeclaratorNode:
+		children = append(children, n)
+	}
+	return children
+}
+
+// markCulprit follows the failing sub-expressions inward and marks the last one, whose own parts did not fail
+func markCulprit(step *EvalStep) {
+	for {
+		var next *EvalStep
+		for _, child := range step.Steps {
+			if child.Failed() {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			step.Culprit = true
+			return
+		}
+		step = next
+	}
+}
+
+// label writes an evaluable sub-expression back the way the formula has it: references instead of the
+// parameters holding their values, LET names and dotted function names as typed
+func (s *stepper) label(text string) string {
+	var result strings.Builder
+	for i := 0; i < len(text); {
+		if text[i] == '"' {
+			end := i + 1
+			for end < len(text) && text[end] != '"' {
+				if text[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(text))
+			result.WriteString(text[i:end])
+			i = end
+			continue
+		}
+		if !isIdentStart(text[i]) {
+			result.WriteByte(text[i])
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(text) && (isIdentStart(text[i]) || isDigit(text[i])) {
+			i++
+		}
+		word := text[start:i]
+
+		value, isParam := s.parameters[word]
+		switch {
+		case word == contextParam:
+			// The resolver is not an argument the formula wrote, nor is the comma after it
+			i += len(text[i:]) - len(strings.TrimPrefix(text[i:], ", "))
+		case isParam:
+			result.WriteString(s.paramLabel(word, value))
+		case strings.HasPrefix(word, "LET_"):
+			result.WriteString(strings.TrimPrefix(word, "LET_"))
+		case word == "true" || word == "false":
+			result.WriteString(strings.ToUpper(word))
+		case strings.Contains(word, "_"):
+			if _, known := evaluatefuncs.LookupFunction(strings.ReplaceAll(word, "_", ".")); known {
+				word = strings.ReplaceAll(word, "_", ".")
+			}
+			result.WriteString(word)
+		default:
+			result.WriteString(word)
+		}
+	}
+	return result.String()
+}
+
+// paramLabel returns the reference or string literal a parameter of the evaluable formula stands for
+func (s *stepper) paramLabel(name string, value any) string {
+	switch {
+	case strings.HasPrefix(name, "STR_LITERAL_"):
+		return `"` + fmt.Sprint(value) + `"`
+	case strings.HasPrefix(name, "CELL_S"):
+		index, ref, _ := strings.Cut(strings.TrimPrefix(name, "CELL_S"), "_")
+		if i, err := strconv.Atoi(index); err == nil && i >= 0 && i < len(s.wb.Sheets) {
+			return QualifyRef(s.wb.Sheets[i].Name, ref)
+		}
+		return ref
+	case strings.HasPrefix(name, "CELL_"):
+		return strings.TrimPrefix(name, "CELL_")
+	}
+
+	if rv, ok := value.(rangeValue); ok {
+		rng := rv.rng
+		ref := utils.FormatCellRef(rng.Top, rng.Left)
+		if rng.Bottom != rng.Top || rng.Right != rng.Left {
+			ref += ":" + utils.FormatCellRef(rng.Bottom, rng.Right)
+		}
+		if rng.Sheet != nil && rng.Sheet != s.home {
+			ref = QualifyRef(rng.Sheet.Name, ref)
+		}
+		return ref
+	}
+	return name
+}
+
+// formatStepValue writes a value the way the dialog shows it: strings quoted, arrays by their first values
+func formatStepValue(value any) string {
+	const maxShown = 6
+
+	switch v := value.(type) {
+	case nil:
+		return `""`
+	case evaluatefuncs.ErrorValue:
+		return v.Code
+	case float64:
+		return strconv.FormatFloat(v, 'g', 15, 64)
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(v))
+	case string:
+		return strconv.Quote(v)
+	case evaluatefuncs.Range:
+		rows, cols := v.Dims()
+		var b strings.Builder
+		b.WriteString("{")
+		shown := 0
+		for r := 0; r < rows && shown < maxShown; r++ {
+			if r > 0 {
+				b.WriteString("; ")
+			}
+			for c := 0; c < cols && shown < maxShown; c++ {
+				if c > 0 {
+					b.WriteString(", ")
+				}
+				b.WriteString(formatStepValue(v.At(r, c)))
+				shown++
+			}
+		}
+		if shown < rows*cols {
+			b.WriteString(", …")
+		}
+		fmt.Fprintf(&b, "} (%d x %d)", rows, cols)
+		return b.String()
+	}
+	return fmt.Sprintf("%v", value)
+}
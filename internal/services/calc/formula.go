@@ -0,0 +1,528 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// formula.go implements spreadsheet formula evaluation
+
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"gosheet/internal/services/cell"
+	"gosheet/internal/utils"
+	"gosheet/internal/utils/evaluatefuncs"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+)
+
+// Token types for formula parsing
+type TokenType int
+
+const (
+	TokenCellRef TokenType = iota
+	TokenStringLiteral
+	TokenOther
+	TokenRange
+	TokenRefError
+)
+
+// Token is a piece of a formula. Value holds the canonical upper-case text (references without $ anchors), Sheet the unquoted sheet qualifier of a reference.
+type Token struct {
+	Type     TokenType
+	Value    string
+	Original string
+	Sheet    string
+}
+
+// EvaluateCell evaluates a formula cell and sets the result as the cell display string
+func (wb *Workbook) EvaluateCell(c *cell.Cell) error {
+	if !c.IsFormula() {
+		return nil
+	}
+
+	if c.RawValue == nil {
+		return fmt.Errorf("cell has nil RawValue")
+	}
+
+	if c.HasFlag(cell.FlagEvaluated) {
+		return nil
+	}
+
+	home := wb.SheetOfCell(c)
+	if home == nil {
+		return fmt.Errorf("no active sheet")
+	}
+
+	key := CellKey{Sheet: home, Row: c.Row, Col: c.Column}
+
+	// Evaluated from outside the engine, the cell may read cells the table changed since the last calculation
+	if len(wb.evalStack) == 0 && !wb.recalculating {
+		wb.dropIndexes()
+	}
+
+	// Reaching a cell which is still being evaluated closes a cycle; report it once with its full path
+	// While iterating, the cell is read with the value the pass before left instead
+	if start, evaluating := wb.onStack[key]; evaluating {
+		if wb.iterating {
+			return nil
+		}
+		path := append(append([]CellKey{}, wb.evalStack[start:]...), key)
+		return &CycleError{Path: path}
+	}
+
+	if wb.onStack == nil {
+		wb.onStack = make(map[CellKey]int)
+	}
+	wb.onStack[key] = len(wb.evalStack)
+	wb.evalStack = append(wb.evalStack, key)
+	// A formula which no longer results in an array gives back the cells it spilled into
+	spilled := false
+	delete(wb.blocked, key)
+	defer func() {
+		wb.evalStack = wb.evalStack[:len(wb.evalStack)-1]
+		delete(wb.onStack, key)
+		if !spilled {
+			wb.clearSpill(key)
+		}
+	}()
+
+	delete(wb.cycles, key)
+	delete(wb.pending, key)
+
+	formula := wb.expandNames(c.GetFormulaExpression())
+
+	if err := wb.linkCell(home, c); err != nil {
+		*c.Display = "#REF!"
+		c.SetFlag(cell.FlagEvaluated)
+		return err
+	}
+
+	// Outside a recalculation pass the cells this one reads may already be evaluated, so look for a cycle in the graph.
+	// Iterative calculation evaluates it once with the values the cells show; the recalculation after settles it.
+	if !wb.recalculating {
+		if path := wb.graph.pathTo(key, key, make(map[CellKey]bool)); path != nil && wb.Calc.Iterative && !wb.iterating {
+			wb.iterating = true
+			defer func() { wb.iterating = false }()
+		} else if path != nil {
+			cycle := &CycleError{Path: path}
+			*c.Display = "#CIRC!"
+			wb.markCycle(key, cycle)
+			c.SetFlag(cell.FlagEvaluated)
+			return cycle
+		}
+	}
+
+	parameters := map[string]any{contextParam: &resolver{wb: wb, home: home, cell: key}}
+	evaluableFormula, err := wb.buildEvaluableFormula(home, formula, parameters)
+	if err != nil {
+		var cycle *CycleError
+		var ev evaluatefuncs.ErrorValue
+		if errors.As(err, &cycle) {
+			*c.Display = "#CIRC!"
+			wb.markCycle(key, cycle)
+		} else if errors.As(err, &ev) {
+			*c.Display = ev.Code
+		} else if errors.Is(err, errInvalidReference) {
+			*c.Display = "#REF!"
+		} else {
+			*c.Display = "#VALUE!"
+		}
+		c.SetFlag(cell.FlagEvaluated)
+		return err
+	}
+
+	result, err := evaluateExpression(evaluableFormula, parameters)
+
+	// Cells reached through INDIRECT or OFFSET are only known now; link them so edits to them recalculate this cell
+	if res, ok := parameters[contextParam].(*resolver); ok {
+		wb.graph.addPrecedents(key, res.cells, res.areas)
+		if res.cycle != nil {
+			*c.Display = "#CIRC!"
+			wb.markCycle(key, res.cycle)
+			c.SetFlag(cell.FlagEvaluated)
+			return res.cycle
+		}
+	}
+
+	if err != nil {
+		ev := evaluatefuncs.ErrorOf(err)
+		// An operation which cannot take an error, like negating it, fails with the error it was given
+		if !ev.Is(evaluatefuncs.ErrName) {
+			if operand, ok := errorParameter(evaluableFormula, parameters); ok {
+				ev = operand
+			}
+		}
+		*c.Display = ev.Code
+		c.SetFlag(cell.FlagEvaluated)
+		return err
+	}
+
+	// An array spills into the cells right and below, the formula cell showing its first value
+	if r, ok := result.(evaluatefuncs.Range); ok {
+		result, spilled = wb.spill(key, r)
+	}
+
+	err = showValue(c, result)
+	c.SetFlag(cell.FlagEvaluated)
+	c.SetFlag(cell.FlagFormula)
+
+	return err
+}
+
+// showValue sets a value computed by a formula as the display string of c, formatted by the type of c.
+// An error value, which includes a number out of range, is returned as the error.
+func showValue(c *cell.Cell, result any) error {
+	if v, ok := result.(float64); ok && (math.IsNaN(v) || math.IsInf(v, 0)) {
+		result = evaluatefuncs.ErrNum
+	}
+
+	switch v := result.(type) {
+	case evaluatefuncs.ErrorValue:
+		*c.Type = "string"
+		*c.Display = v.Code
+		return v
+	case float64:
+		if c.Type == nil || *c.Type == "string" {
+			*c.Type = "number"
+		}
+		if *c.Type == "financial" {
+			formatted := utils.FormatWithCommas(v, c.ThousandsSeparator, c.DecimalSeparator, c.DecimalPoints, c.FinancialSign)
+			*c.Display = fmt.Sprintf("%c%s", c.FinancialSign, formatted)
+		} else {
+			formatted := utils.FormatWithCommas(v, c.ThousandsSeparator, c.DecimalSeparator, c.DecimalPoints, c.FinancialSign)
+			*c.Display = formatted
+		}
+	case int:
+		if c.Type == nil || *c.Type == "string" {
+			*c.Type = "number"
+		}
+		floatVal := float64(v)
+		if *c.Type == "financial" {
+			formatted := utils.FormatWithCommas(floatVal, c.ThousandsSeparator, c.DecimalSeparator, c.DecimalPoints, c.FinancialSign)
+			*c.Display = fmt.Sprintf("%c%s", c.FinancialSign, formatted)
+		} else {
+			formatted := utils.FormatWithCommas(floatVal, c.ThousandsSeparator, c.DecimalSeparator, c.DecimalPoints, c.FinancialSign)
+			*c.Display = formatted
+		}
+	case string:
+		if res, err := utils.ParseDateTime(v); err == nil {
+			*c.Type = "datetime"
+			*c.Display = utils.FormatDateTime(res, *c.DateTimeFormat)
+		} else {
+			*c.Type = "string"
+			*c.Display = v
+		}
+	case bool:
+		*c.Type = "string"
+		if v {
+			*c.Display = "TRUE"
+		} else {
+			*c.Display = "FALSE"
+		}
+	default:
+		*c.Type = "string"
+		*c.Display = fmt.Sprintf("%v", result)
+	}
+
+	return nil
+}
+
+// EvaluateAll rebuilds the dependency graph and evaluates every formula of every sheet once, in dependency order.
+// It is the full recalculation, whatever the calculation mode.
+func (wb *Workbook) EvaluateAll() {
+	wb.graph.reset()
+	wb.cycles = nil
+	wb.pending = nil
+	wb.resetSpills()
+
+	all := make(map[CellKey]bool)
+	for _, sheet := range wb.Sheets {
+		if sheet == nil {
+			continue
+		}
+
+		for _, cellData := range sheet.Data {
+			if cellData.IsFormula() {
+				cellData.ClearFlag(cell.FlagEvaluated)
+				wb.linkCell(sheet, cellData)
+				all[CellKey{Sheet: sheet, Row: cellData.Row, Col: cellData.Column}] = true
+			}
+		}
+	}
+
+	wb.evaluateOrdered(all)
+
+	// Formulas evaluated before a spill reached the cells they read must read them again
+	wb.settleSpills()
+}
+
+// evaluateOrdered evaluates the formulas at keys once each, in dependency order. With iterative calculation
+// the cells of circular references come last, calculated until they settle.
+func (wb *Workbook) evaluateOrdered(keys map[CellKey]bool) {
+	wb.dropIndexes()
+	wb.recalculating = true
+	defer func() { wb.recalculating = false }()
+
+	sorted, cyclic := wb.graph.order(keys)
+	if !wb.Calc.Iterative {
+		sorted, cyclic = append(sorted, cyclic...), nil
+	}
+	for _, key := range sorted {
+		if c := cellAt(key); c != nil {
+			wb.EvaluateCell(c)
+		}
+	}
+	wb.iterate(cyclic)
+}
+
+// functionOptions registers the built-in functions and operators with expr; built once as it is the costly part of compiling
+var functionOptions = sync.OnceValue(func() []expr.Option {
+	functions := evaluatefuncs.GovalFuncs()
+	operators := evaluatefuncs.BinaryOperators()
+	options := make([]expr.Option, 0, len(functions)+2*len(operators))
+	for name, fn := range functions {
+		options = append(options, expr.Function(exprName(name), fn))
+	}
+
+	// Operators must come after the functions they are replaced by
+	for _, op := range operators {
+		options = append(options, expr.Function(op.Name, op.Fn, new(func(any, any) any)))
+	}
+	for _, op := range operators {
+		options = append(options, expr.Operator(op.Symbol, op.Name))
+	}
+	return options
+})
+
+// exprName returns how a function name is written for expr, which does not allow dots in names like ERROR.TYPE
+func exprName(name string) string {
+	return strings.ReplaceAll(name, ".", "_")
+}
+
+// errorParameter returns the first error value the evaluable formula reads from a cell
+func errorParameter(formula string, parameters map[string]any) (evaluatefuncs.ErrorValue, bool) {
+	_, positional := positionalParams(formula, parameters)
+	for i := 0; i < len(positional); i++ {
+		if ev, ok := positional[positionalName(i)].(evaluatefuncs.ErrorValue); ok {
+			return ev, true
+		}
+	}
+	return evaluatefuncs.ErrorValue{}, false
+}
+
+// maxCachedPrograms bounds the compiled program cache; it is simply emptied when full
+const maxCachedPrograms = 8192
+
+var (
+	programCache   = make(map[string]*vm.Program)
+	programCacheMu sync.Mutex
+)
+
+// Uses govaluate to return a result of the formula
+func evaluateExpression(formula string, env map[string]any) (any, error) {
+	formula, env = positionalParams(formula, env)
+
+	// A program is only valid for the parameter types it was checked against
+	var key strings.Builder
+	key.WriteString(formula)
+	for i := 0; i < len(env); i++ {
+		fmt.Fprintf(&key, "|%T", env[positionalName(i)])
+	}
+
+	programCacheMu.Lock()
+	program, cached := programCache[key.String()]
+	programCacheMu.Unlock()
+
+	if !cached {
+		options := append([]expr.Option{expr.Env(env)}, functionOptions()...)
+
+		var err error
+		program, err = expr.Compile(formula, options...)
+		if err != nil {
+			code := evaluatefuncs.ErrError
+			if strings.Contains(err.Error(), "unknown name") {
+				code = evaluatefuncs.ErrName
+			}
+			return nil, evaluatefuncs.ErrorValue{Code: code.Code, Reason: fmt.Sprintf("compile error: %v", err)}
+		}
+
+		programCacheMu.Lock()
+		if len(programCache) >= maxCachedPrograms {
+			programCache = make(map[string]*vm.Program)
+		}
+		programCache[key.String()] = program
+		programCacheMu.Unlock()
+	}
+
+	result, err := expr.Run(program, env)
+	if err != nil {
+		return nil, evaluatefuncs.ErrorValue{Code: evaluatefuncs.ErrValue.Code, Reason: fmt.Sprintf("runtime error: %v", err)}
+	}
+
+	return result, nil
+}
+
+// positionalParams renames the parameters of an evaluable formula by order of appearance,
+// so that A2+1 and A3+1 filled down a column share one compiled program
+func positionalParams(formula string, env map[string]any) (string, map[string]any) {
+	renamed := make(map[string]string, len(env))
+	positional := make(map[string]any, len(env))
+
+	var result strings.Builder
+	for i := 0; i < len(formula); {
+		if !isIdentStart(formula[i]) {
+			result.WriteByte(formula[i])
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(formula) && (isIdentStart(formula[i]) || isDigit(formula[i])) {
+			i++
+		}
+		word := formula[start:i]
+
+		value, isParam := env[word]
+		if !isParam {
+			result.WriteString(word)
+			continue
+		}
+		name, seen := renamed[word]
+		if !seen {
+			name = positionalName(len(renamed))
+			renamed[word] = name
+			positional[name] = value
+		}
+		result.WriteString(name)
+	}
+
+	return result.String(), positional
+}
+
+// positionalName returns the name of the i-th parameter after positionalParams
+func positionalName(i int) string {
+	return "P_" + strconv.Itoa(i)
+}
+
+// RecalculateCell re-evaluates a cell and everything that depends on it
+func (wb *Workbook) RecalculateCell(c *cell.Cell) error {
+	return wb.Recalculate(c)
+}
+
+// Recalculate re-evaluates the changed cells and only the formulas depending on them, each once and in dependency order,
+// along with the volatile formulas. In manual mode only the changed cells are, the others wait for a recalculation.
+// The error of the first changed cell which failed to evaluate is returned.
+func (wb *Workbook) Recalculate(changed ...*cell.Cell) error {
+	roots := make([]CellKey, 0, len(changed))
+	for _, c := range changed {
+		home := wb.SheetOfCell(c)
+		if home == nil {
+			continue
+		}
+		key := CellKey{Sheet: home, Row: c.Row, Col: c.Column}
+		if c.IsFormula() {
+			wb.linkCell(home, c)
+		} else {
+			wb.graph.clear(key)
+			wb.clearSpill(key)
+			delete(wb.blocked, key)
+		}
+		roots = append(roots, key)
+
+		// A cell pasted over a spilled one blocks the array it was part of
+		if c.SpilledFrom == nil {
+			for anchor, area := range wb.spills {
+				if anchor != key && area.contains(key) {
+					roots = append(roots, anchor)
+				}
+			}
+		}
+	}
+
+	errs := wb.recalculateKeys(roots, true)
+	wb.settleSpills()
+
+	for _, root := range roots {
+		if err := errs[root]; err != nil {
+			return err
+		}
+		if cycle := wb.cycles[root]; cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// recalculateKeys re-evaluates the formulas depending on the roots, and the volatile ones if asked to, and
+// returns the errors by cell. Formulas showing #SPILL! because of a root are tried again, the root may have
+// made room for them.
+func (wb *Workbook) recalculateKeys(roots []CellKey, withVolatile bool) map[CellKey]error {
+	wb.dropIndexes()
+	for anchor, area := range wb.blocked {
+		for _, root := range roots {
+			if area.contains(root) {
+				roots = append(roots, anchor)
+				break
+			}
+		}
+	}
+
+	starts := roots
+	if withVolatile {
+		starts = append(roots[:len(roots):len(roots)], wb.volatileKeys()...)
+	}
+	dirty := wb.graph.dirtyFrom(starts)
+	if wb.Calc.Manual {
+		wb.holdDependents(roots, dirty)
+	}
+	for key := range dirty {
+		delete(wb.cycles, key)
+		if c := cellAt(key); c != nil && c.IsFormula() {
+			c.ClearFlag(cell.FlagEvaluated)
+		}
+	}
+
+	// In manual mode the changed cells are evaluated on their own, so they look for cycles themselves
+	wb.recalculating = !wb.Calc.Manual
+	defer func() { wb.recalculating = false }()
+
+	errs := make(map[CellKey]error)
+	sorted, cyclic := wb.graph.order(dirty)
+	if !wb.Calc.Iterative {
+		sorted, cyclic = append(sorted, cyclic...), nil
+	}
+	for _, key := range sorted {
+		c := cellAt(key)
+		if c == nil || !c.IsFormula() {
+			continue
+		}
+		if err := wb.EvaluateCell(c); err != nil {
+			errs[key] = err
+		}
+		wb.notifyCellUpdated(key.Sheet, c)
+	}
+
+	for key, err := range wb.iterate(cyclic) {
+		errs[key] = err
+	}
+	for _, key := range cyclic {
+		if c := cellAt(key); c != nil && c.IsFormula() {
+			wb.notifyCellUpdated(key.Sheet, c)
+		}
+	}
+	return errs
+}
+
+// notifyCellUpdated tells the consumer, if it asked to be told, that a cell got a new value
+func (wb *Workbook) notifyCellUpdated(sheet *Sheet, c *cell.Cell) {
+	if wb.OnCellUpdated != nil && sheet != nil {
+		wb.OnCellUpdated(sheet, c)
+	}
+}
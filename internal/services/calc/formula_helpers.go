@@ -0,0 +1,752 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// formula_helpers.go provide auxiliary functions for the formula engine
+
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"gosheet/internal/services/cell"
+	"gosheet/internal/utils"
+	"gosheet/internal/utils/evaluatefuncs"
+	"strconv"
+	"strings"
+)
+
+// Parses formula into a format usable by govaluate, resolving references from the active sheet
+func (wb *Workbook) BuildEvaluableFormula(formula string, parameters map[string]any) (string, error) {
+	return wb.buildEvaluableFormula(wb.GetActiveSheet(), formula, parameters)
+}
+
+// callFrame is a function call the builder is inside of, and which of its arguments it is at.
+// A LET also keeps the names it bound so far and whether its final calculation has started.
+type callFrame struct {
+	name  string
+	arg   int
+	scope map[string]bool
+	final bool
+}
+
+// Parses formula into a format usable by govaluate, resolving references from the home sheet
+func (wb *Workbook) buildEvaluableFormula(home *Sheet, formula string, parameters map[string]any) (string, error) {
+	tokens := ParseFormulaTokens(wb.expandNames(formula))
+	addressOnly := addressOnlyRefs(tokens)
+	var result strings.Builder
+
+	var calls []callFrame
+	lastIdent := ""
+
+	// LET(x, 1, y, x+1, x*y) becomes (let LET_X = 1; let LET_Y = LET_X+1; LET_X*LET_Y); skip holds the
+	// names and commas already written as the let of each pair
+	skip := make(map[int]bool)
+	startLetArg := func(i int) error {
+		frame := &calls[len(calls)-1]
+		name := nonSpaceIndex(tokens, i+1)
+		comma := nonSpaceIndex(tokens, name+1)
+		if name < 0 || comma < 0 || tokens[comma].Value != "," || (tokens[name].Type != TokenCellRef && (tokens[name].Type != TokenOther || !isIdentStart(tokens[name].Value[0]))) {
+			frame.final = true
+			return nil
+		}
+		if err := validateLocalName(tokens[name].Value); err != nil {
+			return evaluatefuncs.ErrorValue{Code: evaluatefuncs.ErrName.Code, Reason: "LET: " + err.Error()}
+		}
+		frame.scope[tokens[name].Value] = true
+		result.WriteString("let " + letVar(tokens[name].Value) + " = ")
+		skip[name], skip[comma] = true, true
+		return nil
+	}
+	inLet := func() bool {
+		return len(calls) > 0 && calls[len(calls)-1].scope != nil
+	}
+	bound := func(name string) bool {
+		for j := len(calls) - 1; j >= 0; j-- {
+			if calls[j].scope[name] {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i, token := range tokens {
+		if skip[i] {
+			continue
+		}
+		ident := ""
+		if token.Type == TokenOther && isIdentStart(token.Value[0]) {
+			ident = token.Value
+		}
+
+		switch token.Type {
+		case TokenStringLiteral:
+			paramName := fmt.Sprintf("STR_LITERAL_%d", len(parameters))
+			parameters[paramName] = token.Value
+			result.WriteString(paramName)
+
+		case TokenRefError:
+			return "", errInvalidReference
+
+		case TokenCellRef:
+			sheet, row, col, err := wb.resolveRef(home, token.Value)
+			if err != nil {
+				return "", err
+			}
+
+			// A1# reads the whole array spilled by the formula in A1
+			if i+1 < len(tokens) && tokens[i+1].Value == "#" {
+				rng, err := wb.spillRange(CellKey{Sheet: sheet, Row: row, Col: col})
+				if err != nil {
+					return "", err
+				}
+				if err := wb.evaluateRange(rng); err != nil {
+					return "", err
+				}
+				paramName := fmt.Sprintf("RANGE_%d", len(parameters))
+				parameters[paramName] = rangeValue{rng: rng}
+				result.WriteString(paramName)
+				break
+			}
+
+			// Functions like OFFSET work on the reference itself, not on the value in it
+			if n := len(calls); n > 0 && evaluatefuncs.TakesReference(calls[n-1].name, calls[n-1].arg) {
+				rng := cellRange{Sheet: sheet, Top: row, Left: col, Bottom: row, Right: col}
+				if !addressOnly[i] {
+					if err := wb.evaluateRange(rng); err != nil {
+						return "", err
+					}
+				}
+				paramName := fmt.Sprintf("REF_%d", len(parameters))
+				parameters[paramName] = rangeValue{rng: rng}
+				result.WriteString(paramName)
+				break
+			}
+
+			paramName := "CELL_" + utils.FormatCellRef(row, col)
+			if sheet != home {
+				paramName = fmt.Sprintf("CELL_S%d_%s", wb.sheetIndex(sheet), utils.FormatCellRef(row, col))
+			}
+
+			c := lookupCell(sheet, row, col)
+
+			// A precedent showing an error is read like any other value, only a cycle stops the formula
+			if c.IsFormula() && !c.HasFlag(cell.FlagEvaluated) {
+				var cycle *CycleError
+				if err := wb.EvaluateCell(c); errors.As(err, &cycle) {
+					return "", err
+				}
+			}
+			if cycle := wb.cycles[CellKey{Sheet: sheet, Row: row, Col: col}]; cycle != nil {
+				return "", cycle
+			}
+
+			if val := cellValue(c); val != nil {
+				parameters[paramName] = val
+			} else {
+				parameters[paramName] = ""
+			}
+
+			result.WriteString(paramName)
+
+		case TokenRange:
+			rng, err := wb.resolveRange(home, token.Value)
+			if err != nil {
+				return "", err
+			}
+			if !addressOnly[i] {
+				if err := wb.evaluateRange(rng); err != nil {
+					return "", err
+				}
+			}
+
+			paramName := fmt.Sprintf("RANGE_%d", len(parameters))
+			parameters[paramName] = rangeValue{rng: rng}
+			result.WriteString(paramName)
+
+		default:
+			switch {
+			case token.Value == "(" && lastIdent == "LET":
+				calls = append(calls, callFrame{name: lastIdent, scope: make(map[string]bool)})
+				result.WriteString("(")
+				if err := startLetArg(i); err != nil {
+					return "", err
+				}
+			case token.Value == "(":
+				calls = append(calls, callFrame{name: lastIdent})
+				result.WriteString("(")
+
+				// Functions computing references get the resolver of this formula as a hidden first argument
+				if lastIdent != "" && evaluatefuncs.NeedsResolver(lastIdent) {
+					if parameters[contextParam] == nil {
+						parameters[contextParam] = &resolver{wb: wb, home: home}
+					}
+					result.WriteString(contextParam)
+					if next := nextNonSpace(tokens, i+1); next == nil || next.Value != ")" {
+						result.WriteString(", ")
+					}
+				}
+			case token.Value == ")":
+				if inLet() && !calls[len(calls)-1].final {
+					return "", evaluatefuncs.ErrorValue{Code: evaluatefuncs.ErrValue.Code, Reason: "LET: the last argument must be a calculation using the names"}
+				}
+				if len(calls) > 0 {
+					calls = calls[:len(calls)-1]
+				}
+				result.WriteString(")")
+			case token.Value == "," && inLet():
+				if calls[len(calls)-1].final {
+					return "", evaluatefuncs.ErrorValue{Code: evaluatefuncs.ErrValue.Code, Reason: "LET: expected a name and a value before the calculation"}
+				}
+				calls[len(calls)-1].arg++
+				result.WriteString("; ")
+				if err := startLetArg(i); err != nil {
+					return "", err
+				}
+			case token.Value == ",":
+				if len(calls) > 0 {
+					calls[len(calls)-1].arg++
+				}
+				result.WriteString(",")
+			case token.Value == "#" && i > 0 && tokens[i-1].Type == TokenCellRef:
+				// The spill operator was read along with the reference before it
+			case token.Value == "TRUE" || token.Value == "FALSE":
+				result.WriteString(strings.ToLower(token.Value))
+			case token.Value == "LAMBDA":
+				return "", evaluatefuncs.ErrorValue{Code: evaluatefuncs.ErrCalc.Code, Reason: "a LAMBDA must be saved under a name or called in place, like LAMBDA(x, x*2)(A1)"}
+			case isIdentStart(token.Value[0]):
+				next := nextNonSpace(tokens, i+1)
+				called := next != nil && next.Value == "("
+				switch {
+				case called && token.Value == "LET":
+					// Written along with its parenthesis
+				case called:
+					if err := wb.checkLambdaCall(token.Value); err != nil {
+						return "", err
+					}
+					if err := checkArgCount(tokens, i); err != nil {
+						return "", err
+					}
+					result.WriteString(exprName(token.Value))
+				case bound(token.Value):
+					result.WriteString(letVar(token.Value))
+				default:
+					result.WriteString(exprName(token.Value))
+				}
+			default:
+				result.WriteString(token.Value)
+			}
+		}
+
+		if ident != "" || strings.TrimSpace(token.Value) != "" {
+			lastIdent = ident
+		}
+	}
+
+	return result.String(), nil
+}
+
+// checkLambdaCall explains why a call of a saved LAMBDA was left in the formula by expandNames
+func (wb *Workbook) checkLambdaCall(name string) error {
+	named, _ := wb.FindName(name)
+	if named == nil {
+		return nil
+	}
+	params, _, ok := parseLambda(named.Value)
+	if !ok {
+		return evaluatefuncs.ErrorValue{Code: evaluatefuncs.ErrName.Code, Reason: fmt.Sprintf("%s is not a function", name)}
+	}
+	return evaluatefuncs.ErrorValue{
+		Code:   evaluatefuncs.ErrValue.Code,
+		Reason: fmt.Sprintf("%s takes %d argument(s), or calls itself more than %d levels deep", name, len(params), maxNameDepth),
+	}
+}
+
+// checkArgCount gives the #ARGS! error of a call with too few or too many arguments before the formula runs
+func checkArgCount(tokens []Token, i int) error {
+	info, ok := evaluatefuncs.LookupFunction(tokens[i].Value)
+	if !ok {
+		return nil
+	}
+	args, _, ok := callArgs(tokens, nonSpaceIndex(tokens, i+1))
+	if !ok {
+		return nil
+	}
+	return info.CheckArgs(len(args))
+}
+
+// nextNonSpace returns the first token from i on which is not blank
+func nextNonSpace(tokens []Token, i int) *Token {
+	for ; i < len(tokens); i++ {
+		if strings.TrimSpace(tokens[i].Value) != "" {
+			return &tokens[i]
+		}
+	}
+	return nil
+}
+
+// Turns a formula into tokens
+func ParseFormulaTokens(formula string) []Token {
+	var tokens []Token
+	i := 0
+
+	for i < len(formula) {
+		ch := formula[i]
+
+		if ch == '"' {
+			j := i + 1
+			escaped := false
+			for j < len(formula) {
+				if formula[j] == '\\' && !escaped {
+					escaped = true
+					j++
+					continue
+				}
+				if formula[j] == '"' && !escaped {
+					break
+				}
+				escaped = false
+				j++
+			}
+
+			if j < len(formula) {
+				content := formula[i+1 : j]
+				content = strings.ReplaceAll(content, `\"`, `"`)
+				content = strings.ReplaceAll(content, `\\`, `\`)
+
+				tokens = append(tokens, Token{
+					Type:     TokenStringLiteral,
+					Value:    content,
+					Original: formula[i : j+1],
+				})
+				i = j + 1
+				continue
+			}
+		}
+
+		if strings.HasPrefix(strings.ToUpper(formula[i:]), "#REF!") {
+			tokens = append(tokens, Token{
+				Type:     TokenRefError,
+				Value:    "#REF!",
+				Original: formula[i : i+5],
+			})
+			i += 5
+			continue
+		}
+
+		if isDigit(ch) {
+			if tok, next, ok := scanReference(formula, i); ok {
+				tokens = append(tokens, tok)
+				i = next
+				continue
+			}
+		}
+
+		if isDigit(ch) || (ch == '.' && i+1 < len(formula) && isDigit(formula[i+1])) {
+			j := scanNumber(formula, i)
+			tokens = append(tokens, Token{
+				Type:     TokenOther,
+				Value:    formula[i:j],
+				Original: formula[i:j],
+			})
+			i = j
+			continue
+		}
+
+		if ch == '\'' || ch == '$' || isIdentStart(ch) {
+			if tok, next, ok := scanReference(formula, i); ok {
+				tokens = append(tokens, tok)
+				i = next
+				continue
+			}
+		}
+
+		if isIdentStart(ch) {
+			j := i
+			for j < len(formula) && isIdentChar(formula[j]) {
+				j++
+			}
+			tokens = append(tokens, Token{
+				Type:     TokenOther,
+				Value:    strings.ToUpper(formula[i:j]),
+				Original: formula[i:j],
+			})
+			i = j
+			continue
+		}
+
+		tokens = append(tokens, Token{
+			Type:     TokenOther,
+			Value:    strings.ToUpper(string(ch)),
+			Original: string(ch),
+		})
+		i++
+	}
+
+	return tokens
+}
+
+// scanReference reads an optionally sheet-qualified cell reference or range starting at i
+func scanReference(formula string, i int) (Token, int, bool) {
+	sheetName, j, hasSheet := scanSheetPrefix(formula, i)
+	if !hasSheet {
+		j = i
+	}
+
+	first, next, ok := scanCellRef(formula, j)
+	if !ok {
+		span, k, ok := scanSpanRef(formula, j)
+		if !ok {
+			return Token{}, 0, false
+		}
+		return Token{
+			Type:     TokenRange,
+			Value:    QualifyRef(sheetName, stripAnchors(span)),
+			Original: formula[i:k],
+			Sheet:    sheetName,
+		}, k, true
+	}
+
+	tok := Token{Type: TokenCellRef, Sheet: sheetName}
+	ref := stripAnchors(first)
+	j = next
+
+	if j < len(formula) && formula[j] == ':' {
+		if second, k, ok := scanCellRef(formula, j+1); ok {
+			tok.Type = TokenRange
+			ref += ":" + stripAnchors(second)
+			j = k
+		}
+	}
+
+	tok.Value = QualifyRef(sheetName, ref)
+	tok.Original = formula[i:j]
+
+	return tok, j, true
+}
+
+// scanSheetPrefix reads "Sheet2!" or "'My Sheet'!" and returns the unquoted sheet name
+func scanSheetPrefix(formula string, i int) (string, int, bool) {
+	if formula[i] == '\'' {
+		var name strings.Builder
+		j := i + 1
+		for j < len(formula) {
+			if formula[j] == '\'' {
+				if j+1 < len(formula) && formula[j+1] == '\'' {
+					name.WriteByte('\'')
+					j += 2
+					continue
+				}
+				break
+			}
+			name.WriteByte(formula[j])
+			j++
+		}
+		if j+1 < len(formula) && formula[j+1] == '!' && name.Len() > 0 {
+			return name.String(), j + 2, true
+		}
+		return "", i, false
+	}
+
+	j := i
+	for j < len(formula) && isIdentChar(formula[j]) {
+		j++
+	}
+	if j > i && j+1 < len(formula) && formula[j] == '!' && formula[j+1] != '=' {
+		return formula[i:j], j + 1, true
+	}
+
+	return "", i, false
+}
+
+// scanCellRef reads a reference like A1, $A$1, A$1 or $A1 that is not part of a longer identifier or a function call
+func scanCellRef(formula string, i int) (string, int, bool) {
+	j := i
+	if j < len(formula) && formula[j] == '$' {
+		j++
+	}
+	letterStart := j
+	for j < len(formula) && isLetter(formula[j]) {
+		j++
+	}
+	letterEnd := j
+	if j < len(formula) && formula[j] == '$' {
+		j++
+	}
+	digitStart := j
+	for j < len(formula) && isDigit(formula[j]) {
+		j++
+	}
+
+	if letterEnd == letterStart || j == digitStart {
+		return "", 0, false
+	}
+	if j < len(formula) && (isIdentChar(formula[j]) || formula[j] == '(') {
+		return "", 0, false
+	}
+
+	return formula[i:j], j, true
+}
+
+// scanSpanRef reads a whole-column or whole-row range like A:C, $A:$A or 1:3
+func scanSpanRef(formula string, i int) (string, int, bool) {
+	j, ok := scanSpanEnd(formula, i)
+	if !ok || j >= len(formula) || formula[j] != ':' {
+		return "", 0, false
+	}
+	k, ok := scanSpanEnd(formula, j+1)
+	if !ok {
+		return "", 0, false
+	}
+
+	if _, _, ok := splitRefText(formula[i:k]); !ok {
+		return "", 0, false
+	}
+	return formula[i:k], k, true
+}
+
+// scanSpanEnd reads one end of a whole-column or whole-row range, a column like $A or a row like 1
+func scanSpanEnd(formula string, i int) (int, bool) {
+	j := i
+	if j < len(formula) && formula[j] == '$' {
+		j++
+	}
+	start := j
+	if j < len(formula) && isLetter(formula[j]) {
+		for j < len(formula) && isLetter(formula[j]) {
+			j++
+		}
+	} else {
+		for j < len(formula) && isDigit(formula[j]) {
+			j++
+		}
+	}
+
+	if j == start {
+		return 0, false
+	}
+	if j < len(formula) && (isIdentChar(formula[j]) || formula[j] == '(') {
+		return 0, false
+	}
+	return j, true
+}
+
+// stripAnchors returns the upper-case address without its $ anchors
+func stripAnchors(ref string) string {
+	return strings.ToUpper(strings.ReplaceAll(ref, "$", ""))
+}
+
+// scanNumber reads a numeric literal such as 12, 0.5 or 1E5
+func scanNumber(formula string, i int) int {
+	j := i
+	for j < len(formula) && (isDigit(formula[j]) || formula[j] == '.') {
+		j++
+	}
+	if j < len(formula) && (formula[j] == 'e' || formula[j] == 'E') {
+		k := j + 1
+		if k < len(formula) && (formula[k] == '+' || formula[k] == '-') {
+			k++
+		}
+		if k < len(formula) && isDigit(formula[k]) {
+			for k < len(formula) && isDigit(formula[k]) {
+				k++
+			}
+			j = k
+		}
+	}
+	return j
+}
+
+func isLetter(ch byte) bool {
+	return (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z')
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isIdentStart(ch byte) bool {
+	return isLetter(ch) || ch == '_'
+}
+
+func isIdentChar(ch byte) bool {
+	return isIdentStart(ch) || isDigit(ch) || ch == '.'
+}
+
+// addressOnlyRefs returns the positions of the references in tokens which a function reads only the address
+// of, like the A1 of ROW(A1). They are neither calculated first nor linked as precedents, so a formula may
+// ask for its own row without making a cycle.
+func addressOnlyRefs(tokens []Token) map[int]bool {
+	refs := make(map[int]bool)
+	var calls []callFrame
+	lastIdent := ""
+
+	for i, token := range tokens {
+		switch {
+		case token.Type == TokenCellRef || token.Type == TokenRange:
+			// A1# is the array spilled by A1, which must be calculated to know its size
+			spill := i+1 < len(tokens) && tokens[i+1].Value == "#"
+			if n := len(calls); n > 0 && !spill && evaluatefuncs.TakesAddress(calls[n-1].name, calls[n-1].arg) {
+				refs[i] = true
+			}
+		case token.Type != TokenOther:
+		case token.Value == "(":
+			calls = append(calls, callFrame{name: lastIdent})
+		case token.Value == ")" && len(calls) > 0:
+			calls = calls[:len(calls)-1]
+		case token.Value == "," && len(calls) > 0:
+			calls[len(calls)-1].arg++
+		}
+
+		if token.Type == TokenOther && isIdentStart(token.Value[0]) {
+			lastIdent = token.Value
+		} else if strings.TrimSpace(token.Value) != "" {
+			lastIdent = ""
+		}
+	}
+	return refs
+}
+
+// ClearDependencies unlinks the cell from everything it reads; cells reading it stay linked to its position
+func (wb *Workbook) ClearDependencies(c *cell.Cell) {
+	if home := wb.SheetOfCell(c); home != nil {
+		wb.graph.clear(CellKey{Sheet: home, Row: c.Row, Col: c.Column})
+	}
+}
+
+// linkCell records which cells the formula in c reads, in the dependency graph and in c.DependsOn, and
+// whether it calls a volatile function
+func (wb *Workbook) linkCell(home *Sheet, c *cell.Cell) error {
+	key := CellKey{Sheet: home, Row: c.Row, Col: c.Column}
+	wb.graph.clear(key)
+
+	c.DependsOn = nil
+	var precedents []CellKey
+	var areas []cellRange
+	seen := make(map[CellKey]bool)
+
+	addCell := func(depKey CellKey) {
+		if seen[depKey] {
+			return
+		}
+		seen[depKey] = true
+
+		canonical := relativeRef(home, depKey.Sheet, depKey.Row, depKey.Col)
+		c.DependsOn = append(c.DependsOn, &canonical)
+		precedents = append(precedents, depKey)
+	}
+
+	volatile := false
+	tokens := ParseFormulaTokens(wb.expandNames(c.GetFormulaExpression()))
+	addressOnly := addressOnlyRefs(tokens)
+	for i, token := range tokens {
+		if addressOnly[i] {
+			continue
+		}
+		switch token.Type {
+		case TokenOther:
+			if isIdentStart(token.Value[0]) && evaluatefuncs.IsVolatile(token.Value) {
+				if next := nextNonSpace(tokens, i+1); next != nil && next.Value == "(" {
+					volatile = true
+				}
+			}
+
+		case TokenCellRef:
+			depSheet, depRow, depCol, err := wb.resolveRef(home, token.Value)
+			if err != nil {
+				return err
+			}
+			addCell(CellKey{Sheet: depSheet, Row: depRow, Col: depCol})
+
+		case TokenRange:
+			rng, err := wb.resolveRange(home, token.Value)
+			if err != nil {
+				return err
+			}
+
+			if rng.size() > maxLinkedRangeCells {
+				_, cellRef := SplitSheetRef(token.Value)
+				canonical := cellRef
+				if rng.Sheet != home {
+					canonical = QualifyRef(rng.Sheet.Name, cellRef)
+				}
+				c.DependsOn = append(c.DependsOn, &canonical)
+				areas = append(areas, rng)
+				continue
+			}
+			for row := rng.Top; row <= rng.Bottom; row++ {
+				for col := rng.Left; col <= rng.Right; col++ {
+					addCell(CellKey{Sheet: rng.Sheet, Row: row, Col: col})
+				}
+			}
+		}
+	}
+
+	wb.graph.setPrecedents(key, precedents, areas)
+	if volatile {
+		wb.graph.setVolatile(key)
+	}
+	return nil
+}
+
+// markCycle remembers that the cell at key shows #CIRC! because of cycle
+func (wb *Workbook) markCycle(key CellKey, cycle *CycleError) {
+	if wb.cycles == nil {
+		wb.cycles = make(map[CellKey]*CycleError)
+	}
+	wb.cycles[key] = cycle
+}
+
+// CycleAt returns the circular reference which made the cell show #CIRC!, if any
+func (wb *Workbook) CycleAt(c *cell.Cell) *CycleError {
+	home := wb.SheetOfCell(c)
+	if home == nil {
+		return nil
+	}
+	return wb.cycles[CellKey{Sheet: home, Row: c.Row, Col: c.Column}]
+}
+
+// Returns the cell based on its address, which may be qualified with a sheet name
+func (wb *Workbook) GetCellByRef(ref string) (*cell.Cell, error) {
+	if wb.GetActiveSheet() == nil {
+		return nil, fmt.Errorf("no active sheet")
+	}
+
+	return wb.getCellFrom(wb.GetActiveSheet(), ref)
+}
+
+// Returns cell's value
+func (wb *Workbook) GetCellValue(ref string) (float64, error) {
+	c, err := wb.GetCellByRef(ref)
+	if err != nil {
+		return 0, err
+	}
+	
+	if c.Display == nil || c.RawValue == nil {
+		return 0, nil
+	}
+	
+	if c.IsFormula() && !c.HasFlag(cell.FlagEvaluated) {
+		if err := wb.EvaluateCell(c); err != nil {
+			return 0, err
+		}
+	}
+	
+	val := strings.TrimSpace(*c.Display)
+	val = strings.ReplaceAll(val, string(c.ThousandsSeparator), "")
+	val = strings.TrimPrefix(val, string(c.FinancialSign))
+	
+	if num, err := strconv.ParseFloat(val, 64); err == nil {
+		return num, nil
+	}
+	
+	if !c.IsFormula() {
+		rawVal := strings.TrimSpace(*c.RawValue)
+		if num, err := strconv.ParseFloat(rawVal, 64); err == nil {
+			return num, nil
+		}
+	}
+	
+	return 0, fmt.Errorf("cell %s does not contain a numeric value", ref)
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package calc
+
+import (
+	"fmt"
+	"gosheet/internal/services/cell"
+	"gosheet/internal/utils"
+	"testing"
+)
+
+// workbookWith builds a workbook with a second sheet called Data and types the cells into it in order,
+// the way a user would. Errors of formulas are left for the cases to check through the values shown.
+func workbookWith(cells [][2]string) *Workbook {
+	wb := NewWorkbook()
+	wb.Sheets = append(wb.Sheets, NewSheet("Data"))
+	for _, c := range cells {
+		wb.SetCell(c[0], c[1])
+	}
+	return wb
+}
+
+// checkValues compares the values shown by the cells of want with the expected ones
+func checkValues(t *testing.T, wb *Workbook, want map[string]string) {
+	t.Helper()
+	for ref, expected := range want {
+		got, err := wb.Value(ref)
+		if err != nil {
+			t.Errorf("%s: %v", ref, err)
+			continue
+		}
+		if got != expected {
+			t.Errorf("%s = %q, want %q", ref, got, expected)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	cases := []struct {
+		name  string
+		cells [][2]string
+		want  map[string]string
+	}{
+		{
+			name:  "arithmetic",
+			cells: [][2]string{{"A1", "5"}, {"A2", "2.5"}, {"B1", "$=A1*2+A2"}},
+			want:  map[string]string{"B1": "12.50"},
+		},
+		{
+			name:  "empty cell reads as zero",
+			cells: [][2]string{{"B1", "$=A1+1"}},
+			want:  map[string]string{"B1": "1.00"},
+		},
+		{
+			name:  "range",
+			cells: [][2]string{{"A1", "1"}, {"A2", "2"}, {"A3", "3"}, {"B1", "$=SUM(A1:A3)"}},
+			want:  map[string]string{"B1": "6.00"},
+		},
+		{
+			name:  "whole column",
+			cells: [][2]string{{"A1", "1"}, {"A500", "2"}, {"A9000", "3"}, {"B1", "$=SUM(A:A)"}, {"B2", "$=COUNT(A:A)"}},
+			want:  map[string]string{"B1": "6.00", "B2": "3.00"},
+		},
+		{
+			name:  "whole row",
+			cells: [][2]string{{"A1", "4"}, {"Z1", "6"}, {"A2", "$=AVG(1:1)"}},
+			want:  map[string]string{"A2": "5.00"},
+		},
+		{
+			name:  "other sheet",
+			cells: [][2]string{{"Data!A1", "7"}, {"Data!A2", "8"}, {"A1", "$=Data!A1*2"}, {"A2", "$=SUM(Data!A1:A2)"}},
+			want:  map[string]string{"A1": "14.00", "A2": "15.00"},
+		},
+		{
+			name:  "chain",
+			cells: [][2]string{{"A1", "$=A2+1"}, {"A2", "$=A3+1"}, {"A3", "1"}},
+			want:  map[string]string{"A1": "3.00", "A2": "2.00"},
+		},
+		{
+			name:  "edit recalculates dependents",
+			cells: [][2]string{{"A1", "1"}, {"B1", "$=A1*10"}, {"C1", "$=B1+1"}, {"A1", "2"}},
+			want:  map[string]string{"B1": "20.00", "C1": "21.00"},
+		},
+		{
+			name:  "errors",
+			cells: [][2]string{{"A1", "$=1/0"}, {"A2", "$=A1+1"}, {"A3", "$=IFERROR(A1, 0)"}, {"A4", "$=NOSUCHFUNCTION(1)"}},
+			want:  map[string]string{"A1": "#DIV/0!", "A2": "#DIV/0!", "A3": "0.00", "A4": "#NAME?"},
+		},
+		{
+			name:  "strings",
+			cells: [][2]string{{"A1", "Go"}, {"B1", `$=CONCAT(A1, "Sheet")`}, {"B2", `$=LEN("a,b")`}},
+			want:  map[string]string{"B1": "GoSheet", "B2": "3.00"},
+		},
+		{
+			name:  "let",
+			cells: [][2]string{{"A1", "3"}, {"B1", "$=LET(x, A1*2, y, x+1, x*y)"}},
+			want:  map[string]string{"B1": "42.00"},
+		},
+		{
+			name:  "indirect follows its target",
+			cells: [][2]string{{"A1", "2"}, {"B2", "5"}, {"C1", `$=INDIRECT(CONCAT("B", A1))`}, {"B2", "9"}},
+			want:  map[string]string{"C1": "9.00"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checkValues(t, workbookWith(tc.cells), tc.want)
+		})
+	}
+}
+
+// The table stores the cells it edits in the sheet itself, the engine must still find them in ranges
+func TestCellsStoredByTheTable(t *testing.T) {
+	wb := workbookWith([][2]string{{"A1", "1"}, {"A2", "2"}, {"B1", "$=SUM(A:A)"}, {"B2", "$=COUNT(A1:A9)"}})
+
+	c := cell.NewCell(7, 1, "4")
+	wb.GetActiveSheet().Data[[2]int{7, 1}] = c
+	if err := wb.RecalculateCell(c); err != nil {
+		t.Fatal(err)
+	}
+	checkValues(t, wb, map[string]string{"B1": "7.00", "B2": "3.00"})
+}
+
+func TestCellInformation(t *testing.T) {
+	cases := []struct {
+		name  string
+		cells [][2]string
+		want  map[string]string
+	}{
+		{
+			name:  "own row",
+			cells: [][2]string{{"C20", "$=ROW(C20)"}, {"C21", "$=ROW()"}, {"D3", "$=COLUMN(D3)"}},
+			want:  map[string]string{"C20": "20.00", "C21": "21.00", "D3": "4.00"},
+		},
+		{
+			name:  "own address",
+			cells: [][2]string{{"C21", `$=CELL("address", C21)`}, {"C22", `$=CELL("row", C22)`}},
+			want:  map[string]string{"C21": "$C$21", "C22": "22.00"},
+		},
+		{
+			name:  "size of a range holding the formula",
+			cells: [][2]string{{"A5", "$=ROWS($A$1:A5)"}, {"C12", "$=COLUMNS(A:C)"}, {"B2", "$=ROWS(B2)"}},
+			want:  map[string]string{"A5": "5.00", "C12": "3.00", "B2": "1.00"},
+		},
+		{
+			name:  "formula asking about itself",
+			cells: [][2]string{{"D1", "$=ISFORMULA(D1)"}, {"D2", "$=ISFORMULA(D3)"}, {"D3", "7"}},
+			want:  map[string]string{"D1": "TRUE", "D2": "FALSE"},
+		},
+		{
+			name:  "whole column and row",
+			cells: [][2]string{{"B1", "$=ROW(A:A)"}, {"B2", "$=COLUMN(1:1)"}, {"B3", "$=ROW(5:5)"}, {"B4", "$=COLUMN(C:C)"}},
+			want:  map[string]string{"B1": "1.00", "B2": "1.00", "B3": "5.00", "B4": "3.00"},
+		},
+		{
+			name:  "rows of a range spill",
+			cells: [][2]string{{"A1", "$=ROW(B3:B5)"}},
+			want:  map[string]string{"A1": "3.00", "A2": "4.00", "A3": "5.00"},
+		},
+		{
+			name:  "contents are calculated and followed",
+			cells: [][2]string{{"C1", `$=CELL("contents", B1)`}, {"B1", "$=A1*2"}, {"A1", "4"}},
+			want:  map[string]string{"C1": "8.00"},
+		},
+		{
+			name:  "own contents",
+			cells: [][2]string{{"C30", `$=CELL("contents", C30)`}},
+			want:  map[string]string{"C30": "#CIRC!"},
+		},
+		{
+			name:  "formula text",
+			cells: [][2]string{{"B1", "$=A1*2"}, {"B2", "$=FORMULATEXT(B1)"}, {"B3", "$=FORMULATEXT(A1)"}},
+			want:  map[string]string{"B2": "$=A1*2", "B3": "#N/A"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checkValues(t, workbookWith(tc.cells), tc.want)
+		})
+	}
+}
+
+func TestNames(t *testing.T) {
+	wb := workbookWith([][2]string{{"A1", "100"}, {"A2", "50"}})
+	if err := wb.DefineName("TaxRate", "0.5", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := wb.DefineName("Sales", "$A$1:$A$2", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := wb.DefineName("HALF", "LAMBDA(x, x*TaxRate)", ""); err != nil {
+		t.Fatal(err)
+	}
+	wb.SetCell("B1", "$=SUM(Sales)*TaxRate")
+	wb.SetCell("B2", "$=HALF(A2)")
+
+	checkValues(t, wb, map[string]string{"B1": "75.00", "B2": "25.00"})
+}
+
+func TestLocaleRoundTrip(t *testing.T) {
+	defer func(l utils.Locale) { utils.CurrentLocale = l }(utils.CurrentLocale)
+	german, _ := utils.FindLocale("de-DE")
+
+	cases := []struct {
+		formula   string
+		localized string
+		want      string
+	}{
+		{formula: "$=ROUNDTO(1.25, 1)", localized: "$=ROUNDTO(1,25; 1)", want: "1.30"},
+		{formula: "$=SUM(A1:A2, .5)", localized: "$=SUM(A1:A2; ,5)", want: "3.50"},
+		{formula: `$=LEN("1,5")`, localized: `$=LEN("1,5")`, want: "3.00"},
+		{formula: "$=Data!A1+0.5", localized: "$=Data!A1+0,5", want: "7.50"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.formula, func(t *testing.T) {
+			utils.CurrentLocale = german
+			localized := utils.CurrentLocale.LocalizeFormula(tc.formula)
+			if localized != tc.localized {
+				t.Errorf("localized %q, want %q", localized, tc.localized)
+			}
+			if back := utils.CurrentLocale.CanonicalFormula(localized); back != tc.formula {
+				t.Errorf("canonical %q, want %q", back, tc.formula)
+			}
+
+			wb := workbookWith([][2]string{{"A1", "1"}, {"A2", "2"}, {"Data!A1", "7"}, {"B1", utils.CurrentLocale.CanonicalFormula(localized)}})
+			checkValues(t, wb, map[string]string{"B1": tc.want})
+		})
+	}
+}
+
+func TestLocaleNumbers(t *testing.T) {
+	defer func(l utils.Locale) { utils.CurrentLocale = l }(utils.CurrentLocale)
+	utils.CurrentLocale, _ = utils.FindLocale("de-DE")
+
+	cases := []struct {
+		typed string
+		want  string
+	}{
+		{typed: "1,5", want: "1.5"},
+		{typed: "1.234,5", want: "1234.5"},
+		{typed: "-0,25", want: "-0.25"},
+		{typed: "1.500", want: "1500"},
+
+		// Text stays text
+		{typed: "ca. 2,5", want: "ca. 2,5"},
+		{typed: "Preis", want: "Preis"},
+	}
+
+	cells := [][2]string{{"B1", "$=SUM(A1:A6)"}, {"B2", "$=COUNT(A1:A6)"}}
+	for i, tc := range cases {
+		got := utils.CurrentLocale.CanonicalNumber(tc.typed)
+		if got != tc.want {
+			t.Errorf("CanonicalNumber(%q) = %q, want %q", tc.typed, got, tc.want)
+		}
+		cells = append(cells, [2]string{fmt.Sprintf("A%d", i+1), got})
+	}
+
+	// Numbers typed with a decimal comma count as numbers in formulas
+	wb := workbookWith(cells)
+	checkValues(t, wb, map[string]string{"B1": "2,735.75", "B2": "4.00"})
+}
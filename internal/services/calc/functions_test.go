@@ -0,0 +1,211 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package calc
+
+import "testing"
+
+func TestCriteria(t *testing.T) {
+	data := [][2]string{{"A1", "N/A text"}, {"A2", "abc"}, {"A3", "a*c"}, {"A4", "a?c"}, {"B1", "1"}, {"B2", "2"}, {"B3", "4"}, {"B4", "8"}}
+
+	cases := []struct {
+		name    string
+		formula string
+		want    string
+	}{
+		{name: "any text", formula: `$=COUNTIF(A1:A4, "*")`, want: "4.00"},
+		{name: "star across a slash", formula: `$=SUMIF(A1:A4, "N*", B1:B4)`, want: "1.00"},
+		{name: "question mark for a slash", formula: `$=COUNTIF(A1:A4, "N?A*")`, want: "1.00"},
+		{name: "one character", formula: `$=SUMIF(A1:A4, "a?c", B1:B4)`, want: "14.00"},
+		{name: "escaped star", formula: `$=SUMIF(A1:A4, "a~*c", B1:B4)`, want: "4.00"},
+		{name: "escaped question mark", formula: `$=SUMIF(A1:A4, "a~?c", B1:B4)`, want: "8.00"},
+		{name: "not matching", formula: `$=COUNTIF(A1:A4, "<>a*")`, want: "1.00"},
+		{name: "comparison", formula: `$=SUMIFS(B1:B4, B1:B4, ">=2", A1:A4, "a*")`, want: "14.00"},
+		{name: "values resized from a cell", formula: `$=SUMIF(A1:A4, "a*", B1)`, want: "14.00"},
+		{name: "values resized from a smaller range", formula: `$=AVERAGEIF(A1:A4, "a?c", B2:B3)`, want: "6.00"},
+		{name: "values resized from a larger range", formula: `$=SUMIF(A1:A2, "*", B1:B4)`, want: "3.00"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checkValues(t, workbookWith(append(data, [2]string{"C1", tc.formula})), map[string]string{"C1": tc.want})
+		})
+	}
+}
+
+// The cells SUMIF adds beyond those it was given are followed like the others
+func TestResizedValuesFollowed(t *testing.T) {
+	wb := workbookWith([][2]string{{"A1", "y"}, {"A2", "y"}, {"B1", "1"}, {"B2", "2"}, {"C1", `$=SUMIF(A1:A2, "y", B1)`}})
+	wb.SetCell("B2", "5")
+	checkValues(t, wb, map[string]string{"C1": "6.00"})
+}
+
+func TestLookup(t *testing.T) {
+	data := [][2]string{
+		{"A1", "N/A text"}, {"A2", "apple"}, {"A3", "a*c"}, {"A4", "a?c"},
+		{"B1", "1"}, {"B2", "2"}, {"B3", "3"}, {"B4", "4"},
+		{"F1", "5"}, {"G1", "10"}, {"H1", "15"}, {"I1", "20"},
+		{"F2", "a"}, {"G2", "b"}, {"H2", "c"}, {"I2", "d"},
+		{"F4", "10"}, {"F5", "20"}, {"F6", "30"}, {"F7", "40"},
+		{"G4", "none"}, {"G5", "low"}, {"G6", "mid"}, {"G7", "top"},
+		{"H4", "40"}, {"H5", "30"}, {"H6", "20"}, {"H7", "10"},
+	}
+
+	cases := []struct {
+		name    string
+		formula string
+		want    string
+	}{
+		{name: "wildcard across a slash", formula: `$=MATCH("N*", A1:A4, 0)`, want: "1.00"},
+		{name: "wildcard lookup", formula: `$=VLOOKUP("ap*", A1:B4, 2, FALSE)`, want: "2.00"},
+		{name: "escaped star", formula: `$=MATCH("a~*c", A1:A4, 0)`, want: "3.00"},
+		{name: "escaped question mark", formula: `$=XLOOKUP("a~?c", A1:A4, B1:B4, "none", 2)`, want: "4.00"},
+		{name: "slash taken literally", formula: `$=XLOOKUP("N/A*", A1:A4, B1:B4, "none", 2)`, want: "1.00"},
+
+		{name: "exact match", formula: `$=MATCH("apple", A1:A4, 0)`, want: "2.00"},
+		{name: "exact vlookup ignores case", formula: `$=VLOOKUP("APPLE", A1:B4, 2, FALSE)`, want: "2.00"},
+		{name: "exact hlookup", formula: `$=HLOOKUP(10, F1:I2, 2, FALSE)`, want: "b"},
+		{name: "approximate vlookup", formula: `$=VLOOKUP(25, F4:G7, 2)`, want: "low"},
+		{name: "approximate vlookup past the end", formula: `$=VLOOKUP(99, F4:G7, 2, TRUE)`, want: "top"},
+		{name: "approximate match", formula: `$=MATCH(35, F4:F7, 1)`, want: "3.00"},
+		{name: "descending match", formula: `$=MATCH(25, H4:H7, -1)`, want: "2.00"},
+		{name: "next larger", formula: `$=XLOOKUP(25, F4:F7, G4:G7, "none", 1)`, want: "mid"},
+		{name: "last match", formula: `$=XLOOKUP("a*", A1:A4, B1:B4, "none", 2, -1)`, want: "4.00"},
+		{name: "index", formula: `$=INDEX(F4:G7, 3, 2)`, want: "mid"},
+		{name: "index and match", formula: `$=INDEX(B1:B4, MATCH("a?c", A1:A4, 0))`, want: "3.00"},
+		{name: "not found", formula: `$=MATCH("pear", A1:A4, 0)`, want: "#N/A"},
+		{name: "vlookup not found", formula: `$=VLOOKUP("pear", A1:B4, 2, FALSE)`, want: "#N/A"},
+		{name: "below the first value", formula: `$=VLOOKUP(5, F4:G7, 2, TRUE)`, want: "#N/A"},
+		{name: "xlookup not found", formula: `$=XLOOKUP("pear", A1:A4, B1:B4)`, want: "#N/A"},
+		{name: "xlookup default", formula: `$=XLOOKUP("pear", A1:A4, B1:B4, "none")`, want: "none"},
+		{name: "column out of the table", formula: `$=VLOOKUP("apple", A1:B4, 3, FALSE)`, want: "#REF!"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checkValues(t, workbookWith(append(data, [2]string{"D1", tc.formula})), map[string]string{"D1": tc.want})
+		})
+	}
+}
+
+func TestStatistics(t *testing.T) {
+	data := [][2]string{
+		{"A1", "2"}, {"A2", "4"}, {"A3", "4"}, {"A4", "4"}, {"A5", "5"}, {"A6", "5"}, {"A7", "7"}, {"A8", "9"}, {"A9", "text"},
+		{"B1", "1"}, {"B2", "2"}, {"B3", "3"}, {"B4", "4"},
+		{"C1", "3"}, {"C2", "5"}, {"C3", "7"}, {"C4", "9"},
+		{"D1", "6"},
+	}
+
+	cases := []struct {
+		name    string
+		formula string
+		want    string
+	}{
+		{name: "median", formula: "$=MEDIAN(A1:A10)", want: "4.50"},
+		{name: "mode", formula: "$=MODE(A1:A10)", want: "4.00"},
+		{name: "sample deviation ignores text and blanks", formula: "$=STDEV.S(A1:A10)", want: "2.14"},
+		{name: "population deviation", formula: "$=STDEV.P(A1:A10)", want: "2.00"},
+		{name: "sample variance", formula: "$=VAR.S(A1:A10)", want: "4.57"},
+		{name: "population variance", formula: "$=VAR.P(A1:A10)", want: "4.00"},
+		{name: "percentile", formula: "$=PERCENTILE(B1:B4, 0.5)", want: "2.50"},
+		{name: "quartile", formula: "$=QUARTILE(B1:B4, 3)", want: "3.25"},
+		{name: "rank", formula: "$=RANK(7, A1:A8)", want: "2.00"},
+		{name: "large", formula: "$=LARGE(A1:A8, 2)", want: "7.00"},
+		{name: "small", formula: "$=SMALL(A1:A8, 2)", want: "4.00"},
+		{name: "correlation", formula: "$=CORREL(B1:B4, C1:C4)", want: "1.00"},
+		{name: "slope", formula: "$=SLOPE(C1:C4, B1:B4)", want: "2.00"},
+		{name: "intercept", formula: "$=INTERCEPT(C1:C4, B1:B4)", want: "1.00"},
+		{name: "forecast", formula: "$=FORECAST(5, C1:C4, B1:B4)", want: "11.00"},
+		{name: "normal distribution", formula: "$=NORM.DIST(0, 0, 1, TRUE)", want: "0.50"},
+		{name: "inverse normal distribution", formula: "$=ROUNDTO(NORM.INV(0.975, 0, 1), 2)", want: "1.96"},
+
+		{name: "sample deviation of one value", formula: "$=STDEV.S(D1)", want: "#DIV/0!"},
+		{name: "sample variance of one value", formula: "$=VAR.S(D1:D5)", want: "#DIV/0!"},
+		{name: "sample deviation of text", formula: "$=STDEV.S(A9:A10)", want: "#DIV/0!"},
+		{name: "rank of a missing value", formula: "$=RANK(6, A1:A8)", want: "#N/A"},
+		{name: "large past the end", formula: "$=LARGE(B1:B4, 5)", want: "#NUM!"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checkValues(t, workbookWith(append(data, [2]string{"F1", tc.formula})), map[string]string{"F1": tc.want})
+		})
+	}
+}
+
+func TestFinancial(t *testing.T) {
+	data := [][2]string{
+		{"A1", "-100"}, {"A2", "60"}, {"A3", "60"},
+		{"B1", "100"}, {"B2", "100"},
+		{"C1", "-1000"}, {"C2", "300"}, {"C3", "400"}, {"C4", "500"},
+		{"D1", "2024-01-01"}, {"D2", "2024-07-01"}, {"D3", "2025-01-01"},
+		{"E1", "-100"}, {"E2", "250"}, {"E3", "-200"},
+	}
+
+	cases := []struct {
+		name    string
+		formula string
+		want    string
+	}{
+		{name: "payment", formula: "$=PMT(0.05/12, 360, 200000)", want: "-1,073.64"},
+		{name: "present value", formula: "$=PV(0.05, 10, -100)", want: "772.17"},
+		{name: "future value", formula: "$=FV(0.05, 10, -100)", want: "1,257.79"},
+		{name: "periods", formula: "$=NPER(0.01, -100, 1000)", want: "10.59"},
+		{name: "net present value", formula: "$=NPV(0.1, C2:C4)", want: "978.96"},
+		{name: "internal rate", formula: "$=ROUNDTO(IRR(A1:A3)*100, 2)", want: "13.07"},
+		{name: "internal rate with a guess", formula: "$=ROUNDTO(IRR(C1:C4, 0.2)*100, 2)", want: "8.90"},
+		{name: "rate", formula: "$=ROUNDTO(RATE(12, -100, 1000)*100, 2)", want: "2.92"},
+		{name: "dated net present value", formula: "$=XNPV(0.1, A1:A3, D1:D3)", want: "11.75"},
+		{name: "dated internal rate", formula: "$=ROUNDTO(XIRR(A1:A3, D1:D3)*100, 2)", want: "27.82"},
+
+		{name: "internal rate without a sign change", formula: "$=IRR(B1:B2)", want: "#NUM!"},
+		{name: "internal rate not converging", formula: "$=IRR(E1:E3)", want: "#NUM!"},
+		{name: "internal rate not converging from a guess", formula: "$=IRR(E1:E3, 0.5)", want: "#NUM!"},
+		{name: "rate without a solution", formula: "$=RATE(10, 100, 1000)", want: "#NUM!"},
+		{name: "dated internal rate without a sign change", formula: "$=XIRR(B1:B2, D1:D2)", want: "#NUM!"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checkValues(t, workbookWith(append(data, [2]string{"F1", tc.formula})), map[string]string{"F1": tc.want})
+		})
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	data := [][2]string{
+		{"A1", "1"}, {"B1", "2"}, {"A2", "3"}, {"B2", "4"},
+		{"C1", "5"}, {"D1", "6"}, {"C2", "7"}, {"D2", "8"},
+		{"A4", "1"}, {"A5", "2"}, {"A6", "3"},
+		{"A8", "1"}, {"B8", "2"}, {"A9", "2"}, {"B9", "4"},
+		{"C8", "x"},
+	}
+
+	cases := []struct {
+		name    string
+		formula string
+		want    map[string]string
+	}{
+		{name: "product", formula: "$=MMULT(A1:B2, C1:D2)", want: map[string]string{"F1": "19.00", "G1": "22.00", "F2": "43.00", "G2": "50.00"}},
+		{name: "product of a row and a column", formula: "$=MMULT(TRANSPOSE(A4:A6), A4:A6)", want: map[string]string{"F1": "14.00"}},
+		{name: "inverse", formula: "$=MINVERSE(A1:B2)", want: map[string]string{"F1": "-2.00", "G1": "1.00", "F2": "1.50", "G2": "-0.50"}},
+		{name: "determinant", formula: "$=MDETERM(A1:B2)", want: map[string]string{"F1": "-2.00"}},
+		{name: "transpose", formula: "$=TRANSPOSE(A1:B2)", want: map[string]string{"F1": "1.00", "G1": "3.00", "F2": "2.00", "G2": "4.00"}},
+		{name: "sum of products", formula: "$=SUMPRODUCT(A1:B2, C1:D2)", want: map[string]string{"F1": "70.00"}},
+
+		{name: "product of mismatched sizes", formula: "$=MMULT(A1:B2, A4:A6)", want: map[string]string{"F1": "#VALUE!"}},
+		{name: "product with text", formula: "$=MMULT(B8:C8, A4:A5)", want: map[string]string{"F1": "#VALUE!"}},
+		{name: "inverse of a non-square matrix", formula: "$=MINVERSE(A4:A6)", want: map[string]string{"F1": "#VALUE!"}},
+		{name: "inverse of a singular matrix", formula: "$=MINVERSE(A8:B9)", want: map[string]string{"F1": "#NUM!"}},
+		{name: "determinant of a singular matrix", formula: "$=MDETERM(A8:B9)", want: map[string]string{"F1": "0.00"}},
+		{name: "sum of products of mismatched sizes", formula: "$=SUMPRODUCT(A1:B2, A4:A6)", want: map[string]string{"F1": "#VALUE!"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checkValues(t, workbookWith(append(data, [2]string{"F1", tc.formula})), tc.want)
+		})
+	}
+}
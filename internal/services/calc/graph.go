@@ -0,0 +1,332 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// graph.go keeps the dependency graph between formula cells and orders recalculation
+
+package calc
+
+import (
+	"gosheet/internal/services/cell"
+	"gosheet/internal/utils"
+	"strings"
+)
+
+// CellKey identifies a cell by its sheet and position, so links survive the cell being replaced or its sheet renamed
+type CellKey struct {
+	Sheet    *Sheet
+	Row, Col int32
+}
+
+// String returns the sheet-qualified address of the cell
+func (k CellKey) String() string {
+	ref := utils.FormatCellRef(k.Row, k.Col)
+	if k.Sheet == nil {
+		return ref
+	}
+	return QualifyRef(k.Sheet.Name, ref)
+}
+
+// CycleError reports a circular reference with the full path, e.g. A1 -> B1 -> C1 -> A1
+type CycleError struct {
+	Path []CellKey
+}
+
+func (e *CycleError) Error() string {
+	parts := make([]string, len(e.Path))
+	for i, key := range e.Path {
+		parts[i] = key.String()
+	}
+	return "circular reference: " + strings.Join(parts, " -> ")
+}
+
+// depGraph links every formula cell to the cells it reads (precedents) and back (dependents).
+// Large ranges are kept as blocks in areas rather than as one link per cell, and filed by the columns
+// or rows they span in areaSlots so that finding the blocks holding a cell does not visit them all.
+// volatile holds the formulas calling a function like NOW or RAND, which must be recalculated with every change.
+type depGraph struct {
+	precedents map[CellKey][]CellKey
+	dependents map[CellKey]map[CellKey]struct{}
+	areas      map[CellKey][]cellRange
+	areaSlots  map[areaSlot]map[CellKey]struct{}
+	volatile   map[CellKey]bool
+}
+
+// areaSlot files blocks by a column of a sheet, Row being 0, or by a row, Col being 0. Blocks too wide
+// and too tall for either are filed under their sheet alone.
+type areaSlot struct {
+	Sheet    *Sheet
+	Row, Col int32
+}
+
+// maxAreaSlots is the largest number of columns or rows a block is filed under
+const maxAreaSlots = 256
+
+// slotsOf returns the slots a block is filed under: its columns when it is taller than wide, its rows otherwise
+func slotsOf(area cellRange) []areaSlot {
+	width, height := area.Right-area.Left+1, area.Bottom-area.Top+1
+	var slots []areaSlot
+	switch {
+	case min(width, height) > maxAreaSlots:
+		slots = append(slots, areaSlot{Sheet: area.Sheet})
+	case width <= height:
+		for col := area.Left; col <= area.Right; col++ {
+			slots = append(slots, areaSlot{Sheet: area.Sheet, Col: col})
+		}
+	default:
+		for row := area.Top; row <= area.Bottom; row++ {
+			slots = append(slots, areaSlot{Sheet: area.Sheet, Row: row})
+		}
+	}
+	return slots
+}
+
+// reset drops every link
+func (g *depGraph) reset() {
+	g.precedents = make(map[CellKey][]CellKey)
+	g.dependents = make(map[CellKey]map[CellKey]struct{})
+	g.areas = make(map[CellKey][]cellRange)
+	g.areaSlots = make(map[areaSlot]map[CellKey]struct{})
+	g.volatile = make(map[CellKey]bool)
+}
+
+// fileAreas files the blocks key reads under their slots
+func (g *depGraph) fileAreas(key CellKey, areas []cellRange) {
+	for _, area := range areas {
+		for _, slot := range slotsOf(area) {
+			if g.areaSlots[slot] == nil {
+				g.areaSlots[slot] = make(map[CellKey]struct{})
+			}
+			g.areaSlots[slot][key] = struct{}{}
+		}
+	}
+}
+
+// setPrecedents replaces the cells and blocks key reads
+func (g *depGraph) setPrecedents(key CellKey, precedents []CellKey, areas []cellRange) {
+	if g.precedents == nil {
+		g.reset()
+	}
+	g.clear(key)
+
+	if len(areas) > 0 {
+		g.areas[key] = areas
+		g.fileAreas(key, areas)
+	}
+	if len(precedents) == 0 {
+		return
+	}
+	g.precedents[key] = precedents
+	for _, p := range precedents {
+		if g.dependents[p] == nil {
+			g.dependents[p] = make(map[CellKey]struct{})
+		}
+		g.dependents[p][key] = struct{}{}
+	}
+}
+
+// addPrecedents links key to more cells and blocks, on top of those it already reads
+func (g *depGraph) addPrecedents(key CellKey, precedents []CellKey, areas []cellRange) {
+	if g.precedents == nil {
+		g.reset()
+	}
+	if len(areas) > 0 {
+		g.areas[key] = append(g.areas[key], areas...)
+		g.fileAreas(key, areas)
+	}
+
+	for _, p := range precedents {
+		if _, linked := g.dependents[p][key]; linked {
+			continue
+		}
+		g.precedents[key] = append(g.precedents[key], p)
+		if g.dependents[p] == nil {
+			g.dependents[p] = make(map[CellKey]struct{})
+		}
+		g.dependents[p][key] = struct{}{}
+	}
+}
+
+// clear removes the links from key to the cells it reads; cells reading key keep their links
+func (g *depGraph) clear(key CellKey) {
+	for _, p := range g.precedents[key] {
+		if deps := g.dependents[p]; deps != nil {
+			delete(deps, key)
+			if len(deps) == 0 {
+				delete(g.dependents, p)
+			}
+		}
+	}
+	delete(g.precedents, key)
+	for _, area := range g.areas[key] {
+		for _, slot := range slotsOf(area) {
+			if deps := g.areaSlots[slot]; deps != nil {
+				delete(deps, key)
+				if len(deps) == 0 {
+					delete(g.areaSlots, slot)
+				}
+			}
+		}
+	}
+	delete(g.areas, key)
+	delete(g.volatile, key)
+}
+
+// setVolatile marks key as calling a volatile function
+func (g *depGraph) setVolatile(key CellKey) {
+	if g.volatile == nil {
+		g.volatile = make(map[CellKey]bool)
+	}
+	g.volatile[key] = true
+}
+
+// eachDependent calls fn once for every cell reading key, through a single link or a block
+func (g *depGraph) eachDependent(key CellKey, fn func(dep CellKey)) {
+	direct := g.dependents[key]
+	for dep := range direct {
+		fn(dep)
+	}
+
+	// A cell reading key through blocks filed under different slots is only reported once
+	var seen map[CellKey]bool
+	slots := [...]areaSlot{{Sheet: key.Sheet, Col: key.Col}, {Sheet: key.Sheet, Row: key.Row}, {Sheet: key.Sheet}}
+	for i, slot := range slots {
+		for dep := range g.areaSlots[slot] {
+			if _, linked := direct[dep]; linked || seen[dep] {
+				continue
+			}
+			for _, area := range g.areas[dep] {
+				if area.contains(key) {
+					if i < len(slots)-1 {
+						if seen == nil {
+							seen = make(map[CellKey]bool)
+						}
+						seen[dep] = true
+					}
+					fn(dep)
+					break
+				}
+			}
+		}
+	}
+}
+
+// eachPrecedent calls fn for every cell key reads; of its blocks only the stored cells are visited
+func (g *depGraph) eachPrecedent(key CellKey, fn func(p CellKey) bool) {
+	for _, p := range g.precedents[key] {
+		if !fn(p) {
+			return
+		}
+	}
+
+	for _, area := range g.areas[key] {
+		stop := false
+		area.each(func(c *cell.Cell) bool {
+			stop = !fn(CellKey{Sheet: area.Sheet, Row: c.Row, Col: c.Column})
+			return !stop
+		})
+		if stop {
+			return
+		}
+	}
+}
+
+// dirtyFrom returns the roots and every cell that depends on them, directly or not
+func (g *depGraph) dirtyFrom(roots []CellKey) map[CellKey]bool {
+	dirty := make(map[CellKey]bool, len(roots))
+	queue := make([]CellKey, 0, len(roots))
+	for _, root := range roots {
+		if !dirty[root] {
+			dirty[root] = true
+			queue = append(queue, root)
+		}
+	}
+
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		g.eachDependent(key, func(dep CellKey) {
+			if !dirty[dep] {
+				dirty[dep] = true
+				queue = append(queue, dep)
+			}
+		})
+	}
+
+	return dirty
+}
+
+// order sorts the given cells so every cell comes after the cells it reads.
+// Cells that could not be placed are part of, or depend on, a cycle and are returned separately.
+func (g *depGraph) order(cells map[CellKey]bool) (sorted, cyclic []CellKey) {
+	pending := make(map[CellKey]int, len(cells))
+	for key := range cells {
+		pending[key] = 0
+	}
+	for key := range cells {
+		g.eachDependent(key, func(dep CellKey) {
+			if cells[dep] {
+				pending[dep]++
+			}
+		})
+	}
+
+	sorted = make([]CellKey, 0, len(cells))
+	for key, count := range pending {
+		if count == 0 {
+			sorted = append(sorted, key)
+		}
+	}
+
+	for i := 0; i < len(sorted); i++ {
+		g.eachDependent(sorted[i], func(dep CellKey) {
+			if !cells[dep] {
+				return
+			}
+			pending[dep]--
+			if pending[dep] == 0 {
+				sorted = append(sorted, dep)
+			}
+		})
+	}
+
+	if len(sorted) < len(cells) {
+		for key, count := range pending {
+			if count > 0 {
+				cyclic = append(cyclic, key)
+			}
+		}
+	}
+
+	return sorted, cyclic
+}
+
+// pathTo searches the precedents of from for target and returns the chain of cells leading to it
+func (g *depGraph) pathTo(from, target CellKey, visited map[CellKey]bool) []CellKey {
+	var path []CellKey
+	g.eachPrecedent(from, func(p CellKey) bool {
+		if p == target {
+			path = []CellKey{from, p}
+			return false
+		}
+		if visited[p] {
+			return true
+		}
+		visited[p] = true
+		if rest := g.pathTo(p, target, visited); rest != nil {
+			path = append([]CellKey{from}, rest...)
+			return false
+		}
+		return true
+	})
+	return path
+}
+
+// cellAt returns the cell stored at key without creating it
+func cellAt(key CellKey) *cell.Cell {
+	if key.Sheet == nil {
+		return nil
+	}
+	return key.Sheet.Data[[2]int{int(key.Row), int(key.Col)}]
+}
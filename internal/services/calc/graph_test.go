@@ -0,0 +1,243 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package calc
+
+import (
+	"gosheet/internal/services/cell"
+	"strings"
+	"testing"
+)
+
+// keyOf returns the key of the cell at ref, a cell of the first sheet unless ref names another
+func keyOf(t *testing.T, wb *Workbook, ref string) CellKey {
+	t.Helper()
+	sheet, row, col, err := wb.resolveRef(wb.GetActiveSheet(), ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return CellKey{Sheet: sheet, Row: row, Col: col}
+}
+
+// joinKeys writes cells like Sheet1!A1 Sheet1!B2
+func joinKeys(keys []CellKey) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestCycles(t *testing.T) {
+	cases := []struct {
+		name  string
+		cells [][2]string
+		ref   string
+		want  string
+	}{
+		{
+			name:  "self",
+			cells: [][2]string{{"A1", "$=A1+1"}},
+			ref:   "A1",
+			want:  "circular reference: Sheet1!A1 -> Sheet1!A1",
+		},
+		{
+			name:  "three cells",
+			cells: [][2]string{{"C1", "$=C2+1"}, {"C2", "$=C3+1"}, {"C3", "$=C1+1"}},
+			ref:   "C3",
+			want:  "circular reference: Sheet1!C3 -> Sheet1!C1 -> Sheet1!C2 -> Sheet1!C3",
+		},
+		{
+			name:  "through a range",
+			cells: [][2]string{{"A1", "1"}, {"A3", "$=SUM(A1:A2)"}, {"A2", "$=A3*2"}},
+			ref:   "A2",
+			want:  "circular reference: Sheet1!A2 -> Sheet1!A3 -> Sheet1!A2",
+		},
+		{
+			name:  "through a whole column",
+			cells: [][2]string{{"B1", "$=SUM(A:A)"}, {"A7", "$=B1"}},
+			ref:   "A7",
+			want:  "circular reference: Sheet1!A7 -> Sheet1!B1 -> Sheet1!A7",
+		},
+		{
+			name:  "across sheets",
+			cells: [][2]string{{"A1", "$=Data!A1"}, {"Data!A1", "$=Sheet1!A1"}},
+			ref:   "Data!A1",
+			want:  "circular reference: Data!A1 -> Sheet1!A1 -> Data!A1",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			wb := workbookWith(tc.cells)
+			checkValues(t, wb, map[string]string{tc.ref: "#CIRC!"})
+
+			c, _ := wb.GetCellByRef(tc.ref)
+			cycle := wb.CycleAt(c)
+			if cycle == nil {
+				t.Fatalf("%s has no cycle", tc.ref)
+			}
+			// Whichever cell the cycle was found from, the path must go round it once
+			path := cycle.Path
+			if len(path) < 2 || path[0] != path[len(path)-1] {
+				t.Fatalf("the path %v is not a loop", cycle)
+			}
+			for i, key := range path {
+				if key == keyOf(t, wb, tc.ref) {
+					path = append(path[i:len(path)-1:len(path)-1], path[:i+1]...)
+					break
+				}
+			}
+			if got := (&CycleError{Path: path}).Error(); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCycleBroken(t *testing.T) {
+	wb := workbookWith([][2]string{{"A1", "$=B1+1"}, {"B1", "$=A1+1"}, {"B1", "5"}})
+	checkValues(t, wb, map[string]string{"A1": "6.00"})
+	if c, _ := wb.GetCellByRef("A1"); wb.CycleAt(c) != nil {
+		t.Errorf("A1 still reports %v", wb.CycleAt(c))
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	cases := []struct {
+		name         string
+		cells        [][2]string
+		ref          string
+		precedents   string
+		dependents   string
+		dependentRef string
+	}{
+		{
+			name:         "references",
+			cells:        [][2]string{{"A1", "1"}, {"B1", "$=A1+A2"}, {"C1", "$=A1*2"}},
+			ref:          "B1",
+			precedents:   "Sheet1!A1 Sheet1!A2",
+			dependentRef: "A1",
+			dependents:   "Sheet1!B1 Sheet1!C1",
+		},
+		{
+			name:         "whole column",
+			cells:        [][2]string{{"A2", "1"}, {"A9", "2"}, {"B1", "$=SUM(A:A)"}},
+			ref:          "B1",
+			precedents:   "Sheet1!A2 Sheet1!A9",
+			dependentRef: "A500",
+			dependents:   "Sheet1!B1",
+		},
+		{
+			name:         "indirect",
+			cells:        [][2]string{{"A1", "B2"}, {"C1", "$=INDIRECT(A1)"}},
+			ref:          "C1",
+			precedents:   "Sheet1!A1 Sheet1!B2",
+			dependentRef: "B2",
+			dependents:   "Sheet1!C1",
+		},
+		{
+			name:         "other sheet",
+			cells:        [][2]string{{"A1", "$=Data!B3"}},
+			ref:          "A1",
+			precedents:   "Data!B3",
+			dependentRef: "Data!B3",
+			dependents:   "Sheet1!A1",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			wb := workbookWith(tc.cells)
+			if got := joinKeys(wb.Precedents(keyOf(t, wb, tc.ref))); got != tc.precedents {
+				t.Errorf("precedents of %s = %q, want %q", tc.ref, got, tc.precedents)
+			}
+			if got := joinKeys(wb.Dependents(keyOf(t, wb, tc.dependentRef))); got != tc.dependents {
+				t.Errorf("dependents of %s = %q, want %q", tc.dependentRef, got, tc.dependents)
+			}
+		})
+	}
+}
+
+func TestIterative(t *testing.T) {
+	cases := []struct {
+		name          string
+		cells         [][2]string
+		maxIterations int
+		want          map[string]string
+	}{
+		{
+			name:  "converges",
+			cells: [][2]string{{"A1", "$=B1/2+1"}, {"B1", "$=A1"}},
+			want:  map[string]string{"A1": "2.00", "B1": "2.00"},
+		},
+		{
+			name:  "self reference",
+			cells: [][2]string{{"A1", "$=A1/2+3"}},
+			want:  map[string]string{"A1": "6.00"},
+		},
+		{
+			name:          "stops after the passes allowed",
+			cells:         [][2]string{{"A1", "$=A1+1"}},
+			maxIterations: 5,
+			want:          map[string]string{"A1": "5.00"},
+		},
+		{
+			name:  "dependent of a cycle",
+			cells: [][2]string{{"A1", "$=B1/2+1"}, {"B1", "$=A1"}, {"C1", "$=A1*10"}},
+			want:  map[string]string{"C1": "20.00"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			wb := NewWorkbook()
+			wb.Calc.Iterative = true
+			wb.Calc.MaxIterations = tc.maxIterations
+			for _, c := range tc.cells {
+				wb.SetCell(c[0], c[1])
+			}
+			checkValues(t, wb, tc.want)
+		})
+	}
+}
+
+func TestManualMode(t *testing.T) {
+	wb := workbookWith([][2]string{{"A1", "1"}, {"B1", "$=A1+1"}, {"C1", "$=B1*2"}})
+	wb.Calc.Manual = true
+
+	wb.SetCell("A1", "5")
+	checkValues(t, wb, map[string]string{"B1": "2.00", "C1": "4.00"})
+	if !wb.Stale() {
+		t.Error("the workbook should wait for a recalculation")
+	}
+
+	// The edited formula itself is calculated at once, what reads it waits
+	wb.SetCell("B1", "$=A1+2")
+	checkValues(t, wb, map[string]string{"B1": "7.00", "C1": "4.00"})
+
+	wb.RecalculateSheet(wb.GetActiveSheet())
+	checkValues(t, wb, map[string]string{"B1": "7.00", "C1": "14.00"})
+	if wb.Stale() {
+		t.Error("the workbook is still stale after the recalculation")
+	}
+}
+
+func TestVolatile(t *testing.T) {
+	wb := workbookWith([][2]string{{"A1", "$=RAND()"}, {"B1", "1"}})
+	wb.Calc.Seed = 7
+	wb.EvaluateAll()
+	first, _ := wb.Value("A1")
+
+	wb.SetCell("B1", "2")
+	if got, _ := wb.Value("A1"); got != first {
+		t.Errorf("with a seed RAND gave %q, then %q", first, got)
+	}
+
+	c, _ := wb.GetCellByRef("A1")
+	if !c.HasFlag(cell.FlagEvaluated) {
+		t.Error("RAND was not recalculated with the edit")
+	}
+}
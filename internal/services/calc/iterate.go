@@ -0,0 +1,96 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// iterate.go calculates circular references over and over when the workbook allows iterative calculation
+
+package calc
+
+import (
+	"gosheet/internal/services/cell"
+	"gosheet/internal/utils"
+	"gosheet/internal/utils/evaluatefuncs"
+	"math"
+)
+
+// iterationLimits returns how many passes iterative calculation makes at most and the change of value
+// small enough to stop at, the defaults standing in for settings left unset
+func (wb *Workbook) iterationLimits() (int, float64) {
+	maxIterations, maxChange := wb.Calc.MaxIterations, wb.Calc.MaxChange
+	if maxIterations <= 0 {
+		maxIterations = utils.DEFAULT_MAX_ITERATIONS
+	}
+	if maxChange <= 0 {
+		maxChange = utils.DEFAULT_MAX_CHANGE
+	}
+	return maxIterations, maxChange
+}
+
+// iterate evaluates the formulas of circular references, and those depending on them, again and again,
+// each pass reading the values the pass before left. It stops once no value changes by more than the
+// allowed change or the passes run out, the cells keeping the values of the last pass, and returns the
+// errors of that pass by cell.
+func (wb *Workbook) iterate(keys []CellKey) map[CellKey]error {
+	if len(keys) == 0 {
+		return nil
+	}
+	wb.sortKeys(keys)
+
+	wb.iterating = true
+	defer func() { wb.iterating = false }()
+
+	// A cell which never had a value of its own, or only #CIRC!, starts the cycle from zero
+	for _, key := range keys {
+		c := cellAt(key)
+		if c == nil || !c.IsFormula() || c.Display == nil {
+			continue
+		}
+		if _, isError := cellValue(c).(evaluatefuncs.ErrorValue); isError || (c.RawValue != nil && *c.Display == *c.RawValue) {
+			*c.Display = "0"
+		}
+	}
+
+	maxIterations, maxChange := wb.iterationLimits()
+	var errs map[CellKey]error
+	for pass := 0; pass < maxIterations; pass++ {
+		before := make(map[CellKey]any, len(keys))
+		for _, key := range keys {
+			if c := cellAt(key); c != nil && c.IsFormula() {
+				before[key] = cellValue(c)
+				c.ClearFlag(cell.FlagEvaluated)
+			}
+		}
+
+		errs = make(map[CellKey]error)
+		change := 0.0
+		for _, key := range keys {
+			c := cellAt(key)
+			if c == nil || !c.IsFormula() {
+				continue
+			}
+			if err := wb.EvaluateCell(c); err != nil {
+				errs[key] = err
+			}
+			change = max(change, valueChange(before[key], cellValue(c)))
+		}
+		if change <= maxChange {
+			break
+		}
+	}
+	return errs
+}
+
+// valueChange returns by how much a cell value changed in a pass; any change which is not between two
+// numbers counts as too large to stop at
+func valueChange(before, after any) float64 {
+	a, aIsNumber := before.(float64)
+	b, bIsNumber := after.(float64)
+	switch {
+	case aIsNumber && bIsNumber:
+		return math.Abs(b - a)
+	case before == after:
+		return 0
+	}
+	return math.Inf(1)
+}
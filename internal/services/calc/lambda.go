@@ -0,0 +1,241 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// lambda.go implements LET and the LAMBDA functions saved under a workbook name, such as MARGIN(price, cost)
+
+package calc
+
+import (
+	"fmt"
+	"gosheet/internal/utils/evaluatefuncs"
+	"strings"
+)
+
+// UserFunction is a LAMBDA saved under a workbook name, called in formulas like a built-in function
+type UserFunction struct {
+	Name    string
+	Params  []string
+	Body    string
+	Comment string
+}
+
+// UserFunctions returns the names of the workbook holding a LAMBDA, in the order they were defined
+func (wb *Workbook) UserFunctions() []UserFunction {
+	var functions []UserFunction
+	for _, named := range wb.Names {
+		if params, body, ok := parseLambda(named.Value); ok {
+			functions = append(functions, UserFunction{Name: named.Name, Params: params, Body: body, Comment: named.Comment})
+		}
+	}
+	return functions
+}
+
+// Info describes the function for the function browser and the formula editor
+func (f UserFunction) Info() evaluatefuncs.FunctionInfo {
+	info := evaluatefuncs.FunctionInfo{
+		Name:        f.Name,
+		Category:    evaluatefuncs.CategoryWorkbook,
+		Description: f.Comment,
+		Example:     fmt.Sprintf("%s(%s)", f.Name, strings.Join(f.Params, ", ")),
+	}
+	if info.Description == "" {
+		info.Description = "Saved in the Name Manager as LAMBDA(" + strings.Join(append(append([]string(nil), f.Params...), f.Body), ", ") + ")"
+	}
+	for _, p := range f.Params {
+		info.Args = append(info.Args, evaluatefuncs.ArgInfo{Name: p})
+	}
+	return info
+}
+
+// IsLambda reports whether a name's value defines a function rather than a reference or a constant
+func IsLambda(value string) bool {
+	tokens := ParseFormulaTokens(strings.TrimSpace(value))
+	return len(tokens) > 0 && tokens[0].Value == "LAMBDA"
+}
+
+// parseLambda splits LAMBDA(a, b, a*b) into its parameters and its body
+func parseLambda(value string) ([]string, string, bool) {
+	tokens := ParseFormulaTokens(strings.TrimSpace(value))
+	if len(tokens) == 0 || tokens[0].Value != "LAMBDA" {
+		return nil, "", false
+	}
+	open := nonSpaceIndex(tokens, 1)
+	if open < 0 || tokens[open].Value != "(" {
+		return nil, "", false
+	}
+	args, end, ok := callArgs(tokens, open)
+	if !ok || len(args) == 0 || nonSpaceIndex(tokens, end+1) >= 0 {
+		return nil, "", false
+	}
+
+	params := args[:len(args)-1]
+	if err := validateParams(params); err != nil {
+		return nil, "", false
+	}
+	return params, args[len(args)-1], true
+}
+
+// validateParams checks the parameters of a LAMBDA: distinct names which cannot be read as anything else
+func validateParams(params []string) error {
+	seen := make(map[string]bool, len(params))
+	for _, p := range params {
+		if err := validateLocalName(p); err != nil {
+			return err
+		}
+		if seen[strings.ToUpper(p)] {
+			return fmt.Errorf("parameter '%s' is given twice", p)
+		}
+		seen[strings.ToUpper(p)] = true
+	}
+	return nil
+}
+
+// validateLocalName checks a name bound by LET or LAMBDA, which may shadow functions but not cell references
+func validateLocalName(name string) error {
+	if name == "" || !isIdentStart(name[0]) {
+		return fmt.Errorf("'%s' is not a valid name", name)
+	}
+	for i := 0; i < len(name); i++ {
+		if !isIdentChar(name[i]) {
+			return fmt.Errorf("'%s' is not a valid name", name)
+		}
+	}
+	upper := strings.ToUpper(name)
+	if looksLikeCellRef(upper) || upper == "TRUE" || upper == "FALSE" {
+		return fmt.Errorf("'%s' cannot be used as a name", name)
+	}
+	return nil
+}
+
+// letVar returns the expression variable standing for a name bound by LET
+func letVar(name string) string {
+	return "LET_" + exprName(strings.ToUpper(name))
+}
+
+// nonSpaceIndex returns the index of the first token from i on which is not blank, or -1
+func nonSpaceIndex(tokens []Token, i int) int {
+	for ; i < len(tokens); i++ {
+		if strings.TrimSpace(tokens[i].Value) != "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// callArgs returns the text of each argument of the call whose opening parenthesis is tokens[open],
+// and the index of its closing parenthesis
+func callArgs(tokens []Token, open int) ([]string, int, bool) {
+	var args []string
+	var current strings.Builder
+	depth := 0
+
+	for i := open; i < len(tokens); i++ {
+		token := tokens[i]
+		if token.Type == TokenOther {
+			switch token.Value {
+			case "(":
+				depth++
+				if depth == 1 {
+					continue
+				}
+			case ")":
+				depth--
+				if depth == 0 {
+					if arg := strings.TrimSpace(current.String()); arg != "" || len(args) > 0 {
+						args = append(args, arg)
+					}
+					return args, i, true
+				}
+			case ",":
+				if depth == 1 {
+					args = append(args, strings.TrimSpace(current.String()))
+					current.Reset()
+					continue
+				}
+			}
+		}
+		current.WriteString(token.Original)
+	}
+	return nil, 0, false
+}
+
+// lambdaCallAt recognizes a call of a saved LAMBDA, MARGIN(B2, C2), or of one written in place,
+// LAMBDA(x, x*2)(B2), at tokens[i]. It returns the call written as a LET binding the parameters to the
+// arguments, and the index of the last token of the call.
+func (wb *Workbook) lambdaCallAt(tokens []Token, i int) (string, int, bool) {
+	token := tokens[i]
+	if token.Type != TokenOther || token.Value == "" || !isIdentStart(token.Value[0]) {
+		return "", 0, false
+	}
+	open := nonSpaceIndex(tokens, i+1)
+	if open < 0 || tokens[open].Value != "(" {
+		return "", 0, false
+	}
+
+	var params []string
+	var body string
+	if token.Value == "LAMBDA" {
+		definition, end, ok := callArgs(tokens, open)
+		if !ok || len(definition) == 0 {
+			return "", 0, false
+		}
+		open = nonSpaceIndex(tokens, end+1)
+		if open < 0 || tokens[open].Value != "(" {
+			return "", 0, false
+		}
+		params, body = definition[:len(definition)-1], definition[len(definition)-1]
+		if validateParams(params) != nil {
+			return "", 0, false
+		}
+	} else {
+		named, _ := wb.FindName(token.Value)
+		if named == nil {
+			return "", 0, false
+		}
+		var ok bool
+		if params, body, ok = parseLambda(named.Value); !ok {
+			return "", 0, false
+		}
+	}
+
+	args, end, ok := callArgs(tokens, open)
+	if !ok || len(args) != len(params) {
+		return "", 0, false
+	}
+	if len(params) == 0 {
+		return "(" + body + ")", end, true
+	}
+
+	var call strings.Builder
+	call.WriteString("LET(")
+	for j, p := range params {
+		fmt.Fprintf(&call, "%s, %s, ", p, args[j])
+	}
+	call.WriteString(body)
+	call.WriteString(")")
+	return call.String(), end, true
+}
+
+// letNames returns the names declared by the LETs of a formula, which defined names must not replace
+func letNames(tokens []Token) map[string]bool {
+	names := make(map[string]bool)
+	for i, token := range tokens {
+		if token.Value != "LET" {
+			continue
+		}
+		open := nonSpaceIndex(tokens, i+1)
+		if open < 0 || tokens[open].Value != "(" {
+			continue
+		}
+		args, _, ok := callArgs(tokens, open)
+		if !ok {
+			continue
+		}
+		for j := 0; j+1 < len(args); j += 2 {
+			names[strings.ToUpper(args[j])] = true
+		}
+	}
+	return names
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// names.go implements workbook-level named ranges and constants such as TaxRate or Sales
+
+package calc
+
+import (
+	"fmt"
+	"gosheet/internal/services/fileop"
+	"gosheet/internal/utils/evaluatefuncs"
+	"strconv"
+	"strings"
+)
+
+// maxNameDepth limits how deeply names referring to other names are expanded
+const maxNameDepth = 8
+
+// FindName looks up a defined name, ignoring case like Excel does
+func (wb *Workbook) FindName(name string) (*fileop.NamedRange, int) {
+	for i := range wb.Names {
+		if strings.EqualFold(wb.Names[i].Name, name) {
+			return &wb.Names[i], i
+		}
+	}
+	return nil, -1
+}
+
+// ValidateName checks that a name can be used in formulas without being mistaken for something else
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+	if !isIdentStart(name[0]) {
+		return fmt.Errorf("name must start with a letter or underscore")
+	}
+	for i := 0; i < len(name); i++ {
+		if !isIdentChar(name[i]) {
+			return fmt.Errorf("name can only contain letters, digits, '_' and '.'")
+		}
+	}
+
+	upper := strings.ToUpper(name)
+	if looksLikeCellRef(upper) {
+		return fmt.Errorf("'%s' looks like a cell reference", name)
+	}
+	if upper == "TRUE" || upper == "FALSE" || upper == "THIS" || upper == "LET" || upper == "LAMBDA" {
+		return fmt.Errorf("'%s' is a reserved word", name)
+	}
+	if _, exists := evaluatefuncs.GovalFuncs()[upper]; exists {
+		return fmt.Errorf("'%s' is a function name", name)
+	}
+
+	return nil
+}
+
+// DefineName adds a name or changes the value of an existing one, then recalculates the workbook
+func (wb *Workbook) DefineName(name, value, comment string) error {
+	name = strings.TrimSpace(name)
+	value = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), "="))
+
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if value == "" {
+		return fmt.Errorf("name must refer to a reference or a constant")
+	}
+	if IsLambda(value) {
+		if _, _, ok := parseLambda(value); !ok {
+			return fmt.Errorf("a function must be written LAMBDA(parameter, ..., calculation) with distinct parameter names")
+		}
+	}
+
+	if existing, _ := wb.FindName(name); existing != nil {
+		existing.Name = name
+		existing.Value = value
+		existing.Comment = comment
+	} else {
+		wb.Names = append(wb.Names, fileop.NamedRange{Name: name, Value: value, Comment: comment})
+	}
+
+	wb.RebuildDependencies()
+	return nil
+}
+
+// DeleteName removes a name; formulas using it will no longer evaluate
+func (wb *Workbook) DeleteName(name string) error {
+	_, index := wb.FindName(name)
+	if index < 0 {
+		return fmt.Errorf("name '%s' does not exist", name)
+	}
+
+	wb.Names = append(wb.Names[:index], wb.Names[index+1:]...)
+	wb.RebuildDependencies()
+	return nil
+}
+
+// rewriteNameReferences applies fn to every reference inside the names' values
+func (wb *Workbook) rewriteNameReferences(fn func(tok Token) string) {
+	for i := range wb.Names {
+		wb.Names[i].Value = rewriteFormulaRefs(wb.Names[i].Value, fn)
+	}
+}
+
+// expandNames replaces defined names in a formula by the reference or constant they stand for,
+// and calls of LAMBDA functions by their body with the arguments bound to the parameters
+func (wb *Workbook) expandNames(formula string) string {
+	if len(wb.Names) == 0 && !strings.Contains(strings.ToUpper(formula), "LAMBDA") {
+		return formula
+	}
+
+	for depth := 0; depth < maxNameDepth; depth++ {
+		tokens := ParseFormulaTokens(formula)
+		local := letNames(tokens)
+		changed := false
+
+		var result strings.Builder
+		for i := 0; i < len(tokens); i++ {
+			if call, end, ok := wb.lambdaCallAt(tokens, i); ok {
+				result.WriteString(call)
+				i = end
+				changed = true
+				continue
+			}
+			if named := wb.nameAt(tokens, i); named != nil && !local[strings.ToUpper(named.Name)] {
+				result.WriteString(nameText(named.Value))
+				changed = true
+				continue
+			}
+			result.WriteString(tokens[i].Original)
+		}
+
+		formula = result.String()
+		if !changed {
+			break
+		}
+	}
+
+	return formula
+}
+
+// nameAt returns the defined name used by tokens[i], if it is an identifier that is not called as a function
+func (wb *Workbook) nameAt(tokens []Token, i int) *fileop.NamedRange {
+	token := tokens[i]
+	if token.Type != TokenOther || token.Original == "" || !isIdentStart(token.Original[0]) {
+		return nil
+	}
+
+	for j := i + 1; j < len(tokens); j++ {
+		if strings.TrimSpace(tokens[j].Original) == "" {
+			continue
+		}
+		if tokens[j].Original == "(" || tokens[j].Original == "!" {
+			return nil
+		}
+		break
+	}
+
+	named, _ := wb.FindName(token.Original)
+	return named
+}
+
+// nameText returns how a name's value is written inside a formula
+func nameText(value string) string {
+	if _, _, ok := splitRefText(value); ok {
+		return value
+	}
+	return "(" + value + ")"
+}
+
+// ResolveName returns the current value of a name pointing at a single cell or holding a constant
+func (wb *Workbook) ResolveName(name string) (any, bool) {
+	named, _ := wb.FindName(name)
+	if named == nil {
+		return nil, false
+	}
+
+	if _, addrs, ok := splitRefText(named.Value); ok {
+		if len(addrs) != 1 {
+			return nil, false
+		}
+		c, err := wb.getCellFrom(wb.GetActiveSheet(), named.Value)
+		if err != nil {
+			return nil, false
+		}
+		if c.IsFormula() {
+			wb.EvaluateCell(c)
+		}
+		val := strings.TrimSpace(*c.Display)
+		if num, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimPrefix(val, string(c.FinancialSign)), string(c.ThousandsSeparator), ""), 64); err == nil {
+			return num, true
+		}
+		return val, true
+	}
+
+	parameters := make(map[string]any)
+	evaluable, err := wb.BuildEvaluableFormula(named.Value, parameters)
+	if err != nil {
+		return nil, false
+	}
+	result, err := evaluateExpression(evaluable, parameters)
+	if err != nil {
+		return nil, false
+	}
+	return result, true
+}
+
+// AbsoluteRef anchors both row and column of every address in ref, as names usually are
+func AbsoluteRef(ref string) string {
+	prefix, addrs, ok := splitRefText(ref)
+	if !ok {
+		return ref
+	}
+	for i := range addrs {
+		addrs[i].RowAbs, addrs[i].ColAbs = true, true
+	}
+	return joinRefText(prefix, addrs)
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// preview.go evaluates a formula being typed, without storing it, for the preview of the formula editor
+
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"gosheet/internal/services/cell"
+	"gosheet/internal/utils/evaluatefuncs"
+)
+
+// PreviewFormula evaluates formula as if it were written in c and returns what the cell would show.
+// Neither c nor the dependency graph is changed. When the formula fails, the error code is returned
+// along with the error.
+func (wb *Workbook) PreviewFormula(c *cell.Cell, formula string) (string, error) {
+	home := wb.SheetOfCell(c)
+	if home == nil {
+		home = wb.GetActiveSheet()
+	}
+	if home == nil {
+		return "", fmt.Errorf("no active sheet")
+	}
+	key := CellKey{Sheet: home, Row: c.Row, Col: c.Column}
+	wb.dropIndexes()
+
+	formula = wb.expandNames(formula)
+	// With iterative calculation a circular reference is calculated, reading the values its cells show
+	if cycle, err := wb.previewCycle(home, key, formula); err != nil {
+		return "#REF!", err
+	} else if cycle != nil && !wb.Calc.Iterative {
+		return "#CIRC!", cycle
+	}
+
+	parameters := map[string]any{contextParam: &resolver{wb: wb, home: home, cell: key}}
+	evaluableFormula, err := wb.buildEvaluableFormula(home, formula, parameters)
+	if err != nil {
+		return previewErrorCode(err), err
+	}
+
+	result, err := evaluateExpression(evaluableFormula, parameters)
+	if res, ok := parameters[contextParam].(*resolver); ok && res.cycle != nil {
+		return "#CIRC!", res.cycle
+	}
+	if err != nil {
+		ev := evaluatefuncs.ErrorOf(err)
+		if !ev.Is(evaluatefuncs.ErrName) {
+			if operand, ok := errorParameter(evaluableFormula, parameters); ok {
+				ev = operand
+			}
+		}
+		return ev.Code, err
+	}
+
+	// An array shows its first value, as the formula cell would once it spilled
+	suffix := ""
+	if r, ok := result.(evaluatefuncs.Range); ok {
+		rows, cols := r.Dims()
+		if rows == 0 || cols == 0 {
+			return evaluatefuncs.ErrCalc.Code, evaluatefuncs.ErrorValue{Code: evaluatefuncs.ErrCalc.Code, Reason: "the formula results in an empty array"}
+		}
+		result = arrayValue(r, 0, 0)
+		suffix = fmt.Sprintf(" (spills %d x %d)", rows, cols)
+	}
+
+	preview := c.Clone()
+	if preview.Display == nil {
+		preview.Display = new(string)
+	}
+	if preview.Type == nil {
+		typeStr := "string"
+		preview.Type = &typeStr
+	}
+	if err := showValue(preview, result); err != nil {
+		return *preview.Display, err
+	}
+	return *preview.Display + suffix, nil
+}
+
+// previewCycle returns the circular reference the formula would close if it were written at key
+func (wb *Workbook) previewCycle(home *Sheet, key CellKey, formula string) (*CycleError, error) {
+	visited := make(map[CellKey]bool)
+	var cycle *CycleError
+	reaches := func(p CellKey) bool {
+		if p == key {
+			cycle = &CycleError{Path: []CellKey{key, key}}
+		} else if path := wb.graph.pathTo(p, key, visited); path != nil {
+			cycle = &CycleError{Path: append([]CellKey{key}, path...)}
+		}
+		return cycle != nil
+	}
+
+	for _, token := range ParseFormulaTokens(formula) {
+		switch token.Type {
+		case TokenCellRef:
+			sheet, row, col, err := wb.resolveRef(home, token.Value)
+			if err != nil {
+				return nil, err
+			}
+			if reaches(CellKey{Sheet: sheet, Row: row, Col: col}) {
+				return cycle, nil
+			}
+		case TokenRange:
+			rng, err := wb.resolveRange(home, token.Value)
+			if err != nil {
+				return nil, err
+			}
+			if rng.contains(key) {
+				return &CycleError{Path: []CellKey{key, key}}, nil
+			}
+			rng.each(func(c *cell.Cell) bool {
+				return !reaches(CellKey{Sheet: rng.Sheet, Row: c.Row, Col: c.Column})
+			})
+			if cycle != nil {
+				return cycle, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// previewErrorCode returns the code a cell shows when its formula cannot be built
+func previewErrorCode(err error) string {
+	var cycle *CycleError
+	var ev evaluatefuncs.ErrorValue
+	switch {
+	case errors.As(err, &cycle):
+		return "#CIRC!"
+	case errors.As(err, &ev):
+		return ev.Code
+	case errors.Is(err, errInvalidReference):
+		return "#REF!"
+	}
+	return "#VALUE!"
+}
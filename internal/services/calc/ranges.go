@@ -0,0 +1,220 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// ranges.go represents ranges as blocks of a sheet which are read lazily instead of being expanded cell by cell
+
+package calc
+
+import (
+	"errors"
+	"gosheet/internal/services/cell"
+	"gosheet/internal/utils"
+	"gosheet/internal/utils/evaluatefuncs"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxLinkedRangeCells is the largest range whose cells are linked one by one in the dependency graph.
+// Larger ranges, like whole columns, are linked as a block.
+const maxLinkedRangeCells = 1024
+
+// cellRange is a rectangular block of a sheet; whole-column and whole-row ranges span the full grid
+type cellRange struct {
+	Sheet                    *Sheet
+	Top, Left, Bottom, Right int32
+}
+
+// size returns the number of cells in the block, populated or not
+func (r cellRange) size() int64 {
+	return int64(r.Bottom-r.Top+1) * int64(r.Right-r.Left+1)
+}
+
+// contains reports whether the cell at key lies inside the block
+func (r cellRange) contains(key CellKey) bool {
+	return key.Sheet == r.Sheet &&
+		key.Row >= r.Top && key.Row <= r.Bottom &&
+		key.Col >= r.Left && key.Col <= r.Right
+}
+
+// each calls fn for every cell stored inside the block, row by row, until fn returns false.
+// Small blocks are looked up cell by cell, larger ones through the index of the sheet, so a whole column
+// only visits the cells stored in it.
+func (r cellRange) each(fn func(c *cell.Cell) bool) {
+	if r.Sheet == nil {
+		return
+	}
+
+	if r.size() <= int64(len(r.Sheet.Data)) {
+		for row := r.Top; row <= r.Bottom; row++ {
+			for col := r.Left; col <= r.Right; col++ {
+				if c, ok := r.Sheet.Data[[2]int{int(row), int(col)}]; ok && !fn(c) {
+					return
+				}
+			}
+		}
+		return
+	}
+
+	rows := r.Sheet.indexed()
+	first, _ := slices.BinarySearchFunc(rows, r.Top, func(ir indexRow, row int32) int { return int(ir.row - row) })
+	for _, ir := range rows[first:] {
+		if ir.row > r.Bottom {
+			return
+		}
+		left := 0
+		if ir.cells[0].Column < r.Left {
+			left, _ = slices.BinarySearchFunc(ir.cells, r.Left, func(c *cell.Cell, col int32) int { return int(c.Column - col) })
+		}
+		for _, c := range ir.cells[left:] {
+			if c.Column > r.Right {
+				break
+			}
+			if !fn(c) {
+				return
+			}
+		}
+	}
+}
+
+// indexRow holds the cells stored in a row of a sheet, in column order
+type indexRow struct {
+	row   int32
+	cells []*cell.Cell
+}
+
+// cellIndex lists the cells stored in a sheet row by row, so that a large block only visits its own cells.
+// The table edits Data directly, so the index is built when first needed and dropped whenever a calculation
+// starts, see dropIndexes; in between only the engine adds cells, through getCellInSheet.
+type cellIndex []indexRow
+
+// indexed returns the index of the cells stored in the sheet, building it if needed
+func (s *Sheet) indexed() cellIndex {
+	if s.index != nil {
+		return *s.index
+	}
+
+	byRow := make(map[int32][]*cell.Cell)
+	for key, c := range s.Data {
+		byRow[int32(key[0])] = append(byRow[int32(key[0])], c)
+	}
+	index := make(cellIndex, 0, len(byRow))
+	for row, cells := range byRow {
+		slices.SortFunc(cells, func(a, b *cell.Cell) int { return int(a.Column - b.Column) })
+		index = append(index, indexRow{row: row, cells: cells})
+	}
+	slices.SortFunc(index, func(a, b indexRow) int { return int(a.row - b.row) })
+
+	s.index = &index
+	return index
+}
+
+// dropIndexes forgets the indexes of every sheet, for the cells may have been changed since they were built
+func (wb *Workbook) dropIndexes() {
+	for _, sheet := range wb.Sheets {
+		if sheet != nil {
+			sheet.index = nil
+		}
+	}
+}
+
+// rangeValue hands a block to the functions of a formula, see evaluatefuncs.Range
+type rangeValue struct {
+	rng cellRange
+}
+
+func (v rangeValue) Dims() (int, int) {
+	return int(v.rng.Bottom - v.rng.Top + 1), int(v.rng.Right - v.rng.Left + 1)
+}
+
+func (v rangeValue) At(row, col int) any {
+	if v.rng.Sheet == nil {
+		return nil
+	}
+	c := v.rng.Sheet.Data[[2]int{int(v.rng.Top) + row, int(v.rng.Left) + col}]
+	if c == nil {
+		return nil
+	}
+	return cellValue(c)
+}
+
+func (v rangeValue) Each(fn func(row, col int, value any) bool) {
+	v.rng.each(func(c *cell.Cell) bool {
+		value := cellValue(c)
+		if value == nil {
+			return true
+		}
+		return fn(int(c.Row-v.rng.Top), int(c.Column-v.rng.Left), value)
+	})
+}
+
+// Trim clips a whole-column or whole-row block to the part of its sheet holding data,
+// so that A:A used as an array is only as long as the data
+func (v rangeValue) Trim() evaluatefuncs.Range {
+	wholeCols, wholeRows := v.rng.Bottom == utils.MAX_ROWS, v.rng.Right == utils.MAX_COLS
+	if !wholeCols && !wholeRows {
+		return v
+	}
+
+	bottom, right := v.rng.Top, v.rng.Left
+	v.rng.each(func(c *cell.Cell) bool {
+		if cellValue(c) != nil {
+			bottom, right = max(bottom, c.Row), max(right, c.Column)
+		}
+		return true
+	})
+	if wholeCols {
+		v.rng.Bottom = bottom
+	}
+	if wholeRows {
+		v.rng.Right = right
+	}
+	return v
+}
+
+// cellValue returns what a formula reads from the cell: a number, a string, an error value, or nil when it is blank
+func cellValue(c *cell.Cell) any {
+	if c.Display == nil {
+		return nil
+	}
+	display := strings.TrimSpace(*c.Display)
+	if display == "" {
+		return nil
+	}
+	if ev, ok := evaluatefuncs.ParseError(display); ok {
+		return ev
+	}
+
+	val := strings.ReplaceAll(display, string(c.ThousandsSeparator), "")
+	val = strings.TrimPrefix(val, string(c.FinancialSign))
+	if c.DecimalSeparator != '.' && c.DecimalSeparator != c.ThousandsSeparator && c.DecimalSeparator != 0 {
+		// A cell shown with a decimal comma, like 1.234,50
+		val = strings.Replace(val, string(c.DecimalSeparator), ".", 1)
+	}
+	if num, err := strconv.ParseFloat(val, 64); err == nil {
+		return num
+	}
+	return display
+}
+
+// evaluateRange makes sure every formula inside the block holds its current value before the block is read
+func (wb *Workbook) evaluateRange(rng cellRange) error {
+	var err error
+	rng.each(func(c *cell.Cell) bool {
+		if c.IsFormula() && !c.HasFlag(cell.FlagEvaluated) {
+			var cycle *CycleError
+			if evalErr := wb.EvaluateCell(c); errors.As(evalErr, &cycle) {
+				err = evalErr
+				return false
+			}
+		}
+		if cycle := wb.cycles[CellKey{Sheet: rng.Sheet, Row: c.Row, Col: c.Column}]; cycle != nil {
+			err = cycle
+			return false
+		}
+		return true
+	})
+	return err
+}
@@ -0,0 +1,503 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// references.go resolves sheet-qualified cell references (Sheet2!A1, 'My Sheet'!A1:B5) against the workbook
+
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"gosheet/internal/services/cell"
+	"gosheet/internal/utils"
+	"strconv"
+	"strings"
+)
+
+// errInvalidReference is returned when a formula points at a sheet or cell that no longer exists
+var errInvalidReference = errors.New("invalid cell reference")
+
+// QuoteSheetName returns the sheet name as it must be written in front of a reference
+func QuoteSheetName(name string) string {
+	plain := name != ""
+	for i := 0; i < len(name); i++ {
+		ch := name[i]
+		if !isIdentChar(ch) || (i == 0 && !isIdentStart(ch)) {
+			plain = false
+			break
+		}
+	}
+
+	if plain && !looksLikeCellRef(strings.ToUpper(name)) {
+		return name
+	}
+	return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+}
+
+// QualifyRef prefixes ref with the given sheet name, leaving it untouched if the sheet is empty
+func QualifyRef(sheetName, ref string) string {
+	if sheetName == "" {
+		return ref
+	}
+	return QuoteSheetName(sheetName) + "!" + ref
+}
+
+// SplitSheetRef splits "Sheet2!A1" or "'My Sheet'!A1" into the unquoted sheet name and the cell part
+func SplitSheetRef(ref string) (sheetName string, cellRef string) {
+	ref = strings.TrimSpace(ref)
+
+	if strings.HasPrefix(ref, "'") {
+		for i := 1; i < len(ref); i++ {
+			if ref[i] != '\'' {
+				continue
+			}
+			if i+1 < len(ref) && ref[i+1] == '\'' {
+				i++
+				continue
+			}
+			if i+1 < len(ref) && ref[i+1] == '!' {
+				return strings.ReplaceAll(ref[1:i], "''", "'"), ref[i+2:]
+			}
+			break
+		}
+		return "", ref
+	}
+
+	if idx := strings.LastIndex(ref, "!"); idx > 0 {
+		return ref[:idx], ref[idx+1:]
+	}
+
+	return "", ref
+}
+
+// isPlainCellRef reports whether s is made of letters followed by digits, like A1 or XFD1048576
+func isPlainCellRef(s string) bool {
+	i := 0
+	for i < len(s) && isLetter(s[i]) {
+		i++
+	}
+	if i == 0 || i == len(s) {
+		return false
+	}
+	for j := i; j < len(s); j++ {
+		if !isDigit(s[j]) {
+			return false
+		}
+	}
+	return true
+}
+
+// looksLikeCellRef reports whether a sheet name could be mistaken for a cell inside the grid, like S2
+func looksLikeCellRef(s string) bool {
+	if !isPlainCellRef(s) || len(s) > 12 {
+		return false
+	}
+	letters := strings.TrimRight(s, "0123456789")
+	return utils.ColumnNumber(letters) <= int(utils.MAX_COLS)
+}
+
+// cellAddress is one end of a reference; the Abs flags record the $ anchors.
+// Ends of whole-column ranges (A:C) have no row and ends of whole-row ranges (1:3) no column, both left at 0.
+type cellAddress struct {
+	Row, Col       int32
+	RowAbs, ColAbs bool
+}
+
+// parseCellAddress parses A1, $A1, A$1 or $A$1
+func parseCellAddress(s string) (cellAddress, bool) {
+	var a cellAddress
+	i := 0
+
+	if i < len(s) && s[i] == '$' {
+		a.ColAbs = true
+		i++
+	}
+	start := i
+	for i < len(s) && isLetter(s[i]) {
+		i++
+	}
+	letters := s[start:i]
+
+	if i < len(s) && s[i] == '$' {
+		a.RowAbs = true
+		i++
+	}
+	digits := s[i:]
+
+	if letters == "" || digits == "" {
+		return a, false
+	}
+	for j := 0; j < len(digits); j++ {
+		if !isDigit(digits[j]) {
+			return a, false
+		}
+	}
+
+	row, err := strconv.Atoi(digits)
+	if err != nil {
+		return a, false
+	}
+	a.Row = int32(row)
+	a.Col = int32(utils.ColumnNumber(strings.ToUpper(letters)))
+
+	return a, a.Row > 0 && a.Col > 0
+}
+
+// parseSpanAddress parses one end of a whole-column or whole-row range: A, $A, 1 or $1
+func parseSpanAddress(s string) (cellAddress, bool) {
+	var a cellAddress
+	abs := strings.HasPrefix(s, "$")
+	s = strings.TrimPrefix(s, "$")
+	if s == "" {
+		return a, false
+	}
+
+	if isLetter(s[0]) {
+		for i := 0; i < len(s); i++ {
+			if !isLetter(s[i]) {
+				return a, false
+			}
+		}
+		a.Col, a.ColAbs = int32(utils.ColumnNumber(strings.ToUpper(s))), abs
+		return a, a.Col > 0 && a.Col <= utils.MAX_COLS
+	}
+
+	row, err := strconv.Atoi(s)
+	if err != nil || row <= 0 || row > int(utils.MAX_ROWS) {
+		return a, false
+	}
+	a.Row, a.RowAbs = int32(row), abs
+	return a, true
+}
+
+// String formats the address back to text, keeping its anchors
+func (a cellAddress) String() string {
+	var b strings.Builder
+	if a.Col > 0 {
+		if a.ColAbs {
+			b.WriteByte('$')
+		}
+		b.WriteString(utils.ColumnName(a.Col))
+	}
+	if a.Row > 0 {
+		if a.RowAbs {
+			b.WriteByte('$')
+		}
+		b.WriteString(strconv.Itoa(int(a.Row)))
+	}
+	return b.String()
+}
+
+// splitRefText breaks "Sheet2!$A$1:B5", "A:C" or "1:3" into the sheet prefix as written and its one or two addresses
+func splitRefText(ref string) (string, []cellAddress, bool) {
+	_, cellPart := SplitSheetRef(ref)
+	prefix := ref[:len(ref)-len(cellPart)]
+
+	parts := strings.Split(cellPart, ":")
+	if len(parts) > 2 {
+		return "", nil, false
+	}
+
+	addrs := make([]cellAddress, 0, len(parts))
+	for _, part := range parts {
+		a, ok := parseCellAddress(part)
+		if !ok {
+			break
+		}
+		addrs = append(addrs, a)
+	}
+	if len(addrs) == len(parts) {
+		return prefix, addrs, true
+	}
+
+	if len(parts) != 2 {
+		return "", nil, false
+	}
+	first, ok1 := parseSpanAddress(parts[0])
+	second, ok2 := parseSpanAddress(parts[1])
+	if !ok1 || !ok2 || (first.Row == 0) != (second.Row == 0) {
+		return "", nil, false
+	}
+
+	return prefix, []cellAddress{first, second}, true
+}
+
+// joinRefText is the inverse of splitRefText
+func joinRefText(prefix string, addrs []cellAddress) string {
+	parts := make([]string, len(addrs))
+	for i, a := range addrs {
+		parts[i] = a.String()
+	}
+	return prefix + strings.Join(parts, ":")
+}
+
+// offsetRefText moves the relative parts of a reference by dRow rows and dCol columns, as a paste or fill does
+func offsetRefText(ref string, dRow, dCol int32) string {
+	prefix, addrs, ok := splitRefText(ref)
+	if !ok {
+		return ref
+	}
+
+	for i := range addrs {
+		if addrs[i].Row > 0 {
+			if !addrs[i].RowAbs {
+				addrs[i].Row += dRow
+			}
+			if addrs[i].Row < 1 || addrs[i].Row > utils.MAX_ROWS {
+				return "#REF!"
+			}
+		}
+		if addrs[i].Col > 0 {
+			if !addrs[i].ColAbs {
+				addrs[i].Col += dCol
+			}
+			if addrs[i].Col < 1 || addrs[i].Col > utils.MAX_COLS {
+				return "#REF!"
+			}
+		}
+	}
+
+	return joinRefText(prefix, addrs)
+}
+
+// OffsetFormulaRefs shifts every relative reference of a formula expression, leaving $ anchored parts in place
+func OffsetFormulaRefs(formula string, dRow, dCol int32) string {
+	if dRow == 0 && dCol == 0 {
+		return formula
+	}
+	return rewriteFormulaRefs(formula, func(tok Token) string {
+		return offsetRefText(tok.Original, dRow, dCol)
+	})
+}
+
+// FindSheet looks up a sheet by name, ignoring case like Excel does
+func (wb *Workbook) FindSheet(name string) (*Sheet, int) {
+	for i, sheet := range wb.Sheets {
+		if sheet != nil && strings.EqualFold(sheet.Name, name) {
+			return sheet, i
+		}
+	}
+	return nil, -1
+}
+
+// sheetIndex returns the position of the sheet in the workbook
+func (wb *Workbook) sheetIndex(sheet *Sheet) int {
+	for i, s := range wb.Sheets {
+		if s == sheet {
+			return i
+		}
+	}
+	return -1
+}
+
+// SheetOfCell returns the sheet which owns the given cell, falling back to the active sheet
+func (wb *Workbook) SheetOfCell(c *cell.Cell) *Sheet {
+	key := [2]int{int(c.Row), int(c.Column)}
+	for _, sheet := range wb.Sheets {
+		if sheet != nil && sheet.Data[key] == c {
+			return sheet
+		}
+	}
+
+	return wb.GetActiveSheet()
+}
+
+// refSheet returns the sheet a reference qualified with sheetName points at, as seen from the home sheet
+func (wb *Workbook) refSheet(home *Sheet, sheetName string) *Sheet {
+	if sheetName != "" {
+		target, _ := wb.FindSheet(sheetName)
+		return target
+	}
+	if home == nil {
+		return wb.GetActiveSheet()
+	}
+	return home
+}
+
+// resolveRef returns the sheet and coordinates addressed by ref, as seen from the home sheet
+func (wb *Workbook) resolveRef(home *Sheet, ref string) (*Sheet, int32, int32, error) {
+	sheetName, cellRef := SplitSheetRef(ref)
+
+	target := wb.refSheet(home, sheetName)
+	if target == nil {
+		return nil, 0, 0, fmt.Errorf("%w: %s", errInvalidReference, ref)
+	}
+
+	cellRef = strings.ReplaceAll(cellRef, "$", "")
+	if !isPlainCellRef(strings.ToUpper(cellRef)) {
+		return nil, 0, 0, fmt.Errorf("%w: %s", errInvalidReference, ref)
+	}
+
+	row, col := utils.ParseCellRef(cellRef)
+	if row <= 0 || col <= 0 {
+		return nil, 0, 0, fmt.Errorf("%w: %s", errInvalidReference, ref)
+	}
+
+	return target, row, col, nil
+}
+
+// resolveRange returns the block addressed by a range like A1:B5, A:C or Sheet2!1:3, as seen from the home sheet
+func (wb *Workbook) resolveRange(home *Sheet, ref string) (cellRange, error) {
+	sheetName, _ := SplitSheetRef(ref)
+
+	target := wb.refSheet(home, sheetName)
+	_, addrs, ok := splitRefText(ref)
+	if target == nil || !ok || len(addrs) != 2 {
+		return cellRange{}, fmt.Errorf("%w: %s", errInvalidReference, ref)
+	}
+
+	rng := cellRange{
+		Sheet:  target,
+		Top:    min(addrs[0].Row, addrs[1].Row),
+		Bottom: max(addrs[0].Row, addrs[1].Row),
+		Left:   min(addrs[0].Col, addrs[1].Col),
+		Right:  max(addrs[0].Col, addrs[1].Col),
+	}
+	if rng.Top == 0 {
+		rng.Top, rng.Bottom = 1, utils.MAX_ROWS
+	}
+	if rng.Left == 0 {
+		rng.Left, rng.Right = 1, utils.MAX_COLS
+	}
+
+	return rng, nil
+}
+
+// getCellInSheet returns the cell at the given position, creating an empty one if needed.
+// Only edits should create cells; reading goes through lookupCell.
+func getCellInSheet(sheet *Sheet, row, col int32) *cell.Cell {
+	key := [2]int{int(row), int(col)}
+
+	if cellData, exists := sheet.Data[key]; exists {
+		return cellData
+	}
+
+	newCell := cell.NewCell(row, col, "")
+	sheet.Data[key] = newCell
+	sheet.index = nil
+
+	return newCell
+}
+
+// lookupCell returns the cell at the given position, or a blank one which is not stored in the sheet
+func lookupCell(sheet *Sheet, row, col int32) *cell.Cell {
+	if c, exists := sheet.Data[[2]int{int(row), int(col)}]; exists {
+		return c
+	}
+	return cell.NewCell(row, col, "")
+}
+
+// getCellFrom resolves ref from the home sheet and returns the addressed cell without creating it
+func (wb *Workbook) getCellFrom(home *Sheet, ref string) (*cell.Cell, error) {
+	sheet, row, col, err := wb.resolveRef(home, ref)
+	if err != nil {
+		return nil, err
+	}
+	return lookupCell(sheet, row, col), nil
+}
+
+// relativeRef formats the address of (row, col) on target as written from the base sheet
+func relativeRef(base, target *Sheet, row, col int32) string {
+	ref := utils.FormatCellRef(row, col)
+	if target == nil || target == base {
+		return ref
+	}
+	return QualifyRef(target.Name, ref)
+}
+
+// rewriteFormulaRefs rebuilds a formula, letting fn replace the text of every reference and range
+func rewriteFormulaRefs(formula string, fn func(tok Token) string) string {
+	var result strings.Builder
+	for _, tok := range ParseFormulaTokens(formula) {
+		switch tok.Type {
+		case TokenCellRef, TokenRange:
+			result.WriteString(fn(tok))
+		default:
+			result.WriteString(tok.Original)
+		}
+	}
+	return result.String()
+}
+
+// RenameSheetReferences points every formula, name and dependency on oldName at newName
+func (wb *Workbook) RenameSheetReferences(oldName, newName string) {
+	renameRef := func(ref string) string {
+		sheetName, cellRef := SplitSheetRef(ref)
+		if sheetName != "" && strings.EqualFold(sheetName, oldName) {
+			return QualifyRef(newName, cellRef)
+		}
+		return ref
+	}
+
+	renameToken := func(tok Token) string {
+		if tok.Sheet != "" && strings.EqualFold(tok.Sheet, oldName) {
+			_, cellRef := SplitSheetRef(tok.Original)
+			return QualifyRef(newName, cellRef)
+		}
+		return tok.Original
+	}
+
+	wb.rewriteNameReferences(renameToken)
+
+	for _, sheet := range wb.Sheets {
+		for _, c := range sheet.Data {
+			if c.IsFormula() {
+				expr := rewriteFormulaRefs(c.GetFormulaExpression(), renameToken)
+				raw := "$=" + expr
+				c.RawValue = &raw
+			}
+
+			for i, dep := range c.DependsOn {
+				if dep != nil {
+					renamed := renameRef(*dep)
+					c.DependsOn[i] = &renamed
+				}
+			}
+		}
+	}
+}
+
+// InvalidateSheetReferences turns every reference to a deleted sheet into #REF! and recalculates the workbook
+func (wb *Workbook) InvalidateSheetReferences(deletedName string) {
+	wb.rewriteNameReferences(func(tok Token) string {
+		if tok.Sheet != "" && strings.EqualFold(tok.Sheet, deletedName) {
+			return "#REF!"
+		}
+		return tok.Original
+	})
+
+	for _, sheet := range wb.Sheets {
+		for _, c := range sheet.Data {
+			if c.IsFormula() {
+				changed := false
+				expr := rewriteFormulaRefs(c.GetFormulaExpression(), func(tok Token) string {
+					if tok.Sheet != "" && strings.EqualFold(tok.Sheet, deletedName) {
+						changed = true
+						return "#REF!"
+					}
+					return tok.Original
+				})
+				if changed {
+					raw := "$=" + expr
+					c.RawValue = &raw
+				}
+			}
+		}
+	}
+
+	wb.RebuildDependencies()
+}
+
+// RebuildDependencies drops every dependency link and re-evaluates all formulas in the workbook
+func (wb *Workbook) RebuildDependencies() {
+	for _, sheet := range wb.Sheets {
+		for _, c := range sheet.Data {
+			c.DependsOn = nil
+			c.Dependents = nil
+		}
+	}
+
+	wb.EvaluateAll()
+}
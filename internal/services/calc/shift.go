@@ -0,0 +1,122 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// shift.go rewrites references after rows or columns are inserted or deleted
+
+package calc
+
+import "strings"
+
+// ShiftReferences rewrites formulas and validation rules after count rows (or columns) were inserted at index on target.
+// A negative count means they were deleted; references to deleted cells become #REF!.
+func (wb *Workbook) ShiftReferences(target *Sheet, rows bool, index, count int32) {
+	if wb == nil || target == nil {
+		return
+	}
+
+	// Names are only moved when they name their sheet, unqualified ones follow the sheet they are used on
+	wb.rewriteNameReferences(func(tok Token) string {
+		if tok.Sheet == "" {
+			return tok.Original
+		}
+		if refSheet, _ := wb.FindSheet(tok.Sheet); refSheet != target {
+			return tok.Original
+		}
+		return shiftRefText(tok.Original, rows, index, count)
+	})
+
+	for _, sheet := range wb.Sheets {
+		home := sheet
+		shift := func(tok Token) string {
+			refSheet := home
+			if tok.Sheet != "" {
+				refSheet, _ = wb.FindSheet(tok.Sheet)
+			}
+			if refSheet != target {
+				return tok.Original
+			}
+			return shiftRefText(tok.Original, rows, index, count)
+		}
+
+		for _, c := range sheet.Data {
+			if c.IsFormula() {
+				raw := "$=" + rewriteFormulaRefs(c.GetFormulaExpression(), shift)
+				c.RawValue = &raw
+			}
+			if c.Valrule != nil && strings.TrimSpace(*c.Valrule) != "" {
+				rule := rewriteFormulaRefs(*c.Valrule, shift)
+				c.Valrule = &rule
+			}
+		}
+	}
+
+	wb.RebuildDependencies()
+}
+
+// shiftRefText moves a single reference or range like Sheet2!A1:B5, keeping its sheet prefix and $ anchors as written
+func shiftRefText(ref string, rows bool, index, count int32) string {
+	prefix, addrs, ok := splitRefText(ref)
+	if !ok {
+		return ref
+	}
+
+	axis := func(a *cellAddress) *int32 {
+		if rows {
+			return &a.Row
+		}
+		return &a.Col
+	}
+
+	first, last := 0, len(addrs)-1
+	if *axis(&addrs[last]) < *axis(&addrs[first]) {
+		first, last = last, first
+	}
+
+	lo, hi, ok := shiftSpan(*axis(&addrs[first]), *axis(&addrs[last]), index, count)
+	if !ok {
+		return "#REF!"
+	}
+	*axis(&addrs[first]) = lo
+	*axis(&addrs[last]) = hi
+
+	return joinRefText(prefix, addrs)
+}
+
+// shiftSpan moves the span lo..hi along one axis, growing or shrinking it when the change happens inside it.
+// It returns false when every cell of the span was deleted.
+func shiftSpan(lo, hi, index, count int32) (int32, int32, bool) {
+	if count > 0 {
+		if lo >= index {
+			lo += count
+		}
+		if hi >= index {
+			hi += count
+		}
+		return lo, hi, true
+	}
+
+	removed := -count
+	last := index + removed - 1
+
+	switch {
+	case hi < index:
+		return lo, hi, true
+	case lo > last:
+		return lo - removed, hi - removed, true
+	case lo >= index && hi <= last:
+		return 0, 0, false
+	}
+
+	if lo >= index {
+		lo = index
+	}
+	if hi <= last {
+		hi = index - 1
+	} else {
+		hi -= removed
+	}
+
+	return lo, hi, true
+}
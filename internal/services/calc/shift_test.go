@@ -0,0 +1,86 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package calc
+
+import "testing"
+
+func TestShiftRefText(t *testing.T) {
+	cases := []struct {
+		ref   string
+		rows  bool
+		index int32
+		count int32
+		want  string
+	}{
+		{ref: "A1", rows: true, index: 2, count: 1, want: "A1"},
+		{ref: "A2", rows: true, index: 2, count: 1, want: "A3"},
+		{ref: "$B$5", rows: true, index: 2, count: 3, want: "$B$8"},
+		{ref: "C3", rows: false, index: 2, count: 2, want: "E3"},
+		{ref: "A2:A9", rows: true, index: 5, count: 1, want: "A2:A10"},
+		{ref: "Sheet2!B2:C4", rows: true, index: 1, count: 1, want: "Sheet2!B3:C5"},
+		{ref: "'My Sheet'!A3", rows: true, index: 1, count: 2, want: "'My Sheet'!A5"},
+		{ref: "A:A", rows: true, index: 2, count: 1, want: "A:A"},
+		{ref: "A:C", rows: false, index: 2, count: 1, want: "A:D"},
+		{ref: "2:3", rows: true, index: 1, count: 1, want: "3:4"},
+
+		// Deleting
+		{ref: "A5", rows: true, index: 2, count: -2, want: "A3"},
+		{ref: "A2", rows: true, index: 2, count: -1, want: "#REF!"},
+		{ref: "A1:A9", rows: true, index: 3, count: -2, want: "A1:A7"},
+		{ref: "A3:A9", rows: true, index: 2, count: -2, want: "A2:A7"},
+		{ref: "A2:A3", rows: true, index: 2, count: -2, want: "#REF!"},
+		{ref: "B1:D1", rows: false, index: 4, count: -1, want: "B1:C1"},
+		{ref: "2:3", rows: true, index: 2, count: -1, want: "2:2"},
+	}
+
+	for _, tc := range cases {
+		if got := shiftRefText(tc.ref, tc.rows, tc.index, tc.count); got != tc.want {
+			t.Errorf("shiftRefText(%q, %v, %d, %d) = %q, want %q", tc.ref, tc.rows, tc.index, tc.count, got, tc.want)
+		}
+	}
+}
+
+func TestShiftReferences(t *testing.T) {
+	cases := []struct {
+		name    string
+		formula string
+		sheet   string
+		rows    bool
+		index   int32
+		count   int32
+		want    string
+	}{
+		{name: "rows inserted", formula: "$=SUM(B2:B5)+$C$3", sheet: "Sheet1", rows: true, index: 3, count: 2, want: "$=SUM(B2:B7)+$C$5"},
+		{name: "columns inserted", formula: "$=B2*C2", sheet: "Sheet1", rows: false, index: 3, count: 1, want: "$=B2*D2"},
+		{name: "rows deleted", formula: "$=B2+B3+B4", sheet: "Sheet1", rows: true, index: 3, count: -1, want: "$=B2+#REF!+B3"},
+		{name: "other sheet moved", formula: "$=Data!A4+A4", sheet: "Data", rows: true, index: 1, count: 1, want: "$=Data!A5+A4"},
+		{name: "other sheet untouched", formula: "$=Data!A4+A4", sheet: "Sheet1", rows: true, index: 1, count: 1, want: "$=Data!A4+A5"},
+		{name: "strings untouched", formula: `$=CONCAT("B2", B2)`, sheet: "Sheet1", rows: true, index: 1, count: 1, want: `$=CONCAT("B2", B3)`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			wb := workbookWith([][2]string{{"A1", tc.formula}})
+			target, _ := wb.FindSheet(tc.sheet)
+			wb.ShiftReferences(target, tc.rows, tc.index, tc.count)
+
+			c, _ := wb.GetCellByRef("A1")
+			if got := *c.RawValue; got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestShiftedNames(t *testing.T) {
+	wb := workbookWith([][2]string{{"A1", "$=SUM(Sales)"}})
+	wb.DefineName("Sales", "Sheet1!$B$2:$B$5", "")
+	wb.ShiftReferences(wb.GetActiveSheet(), true, 1, 1)
+
+	if named, _ := wb.FindName("Sales"); named == nil || named.Value != "Sheet1!$B$3:$B$6" {
+		t.Errorf("Sales = %+v, want Sheet1!$B$3:$B$6", named)
+	}
+}
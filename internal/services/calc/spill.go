@@ -0,0 +1,220 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// spill.go lets a formula resulting in an array fill the cells right and below of its own
+
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"gosheet/internal/services/cell"
+	"gosheet/internal/utils"
+	"gosheet/internal/utils/evaluatefuncs"
+	"strings"
+)
+
+const (
+	// maxSpillCells bounds the cells a single formula may spill into, each becoming a cell of the sheet
+	maxSpillCells = 1 << 16
+
+	// maxSpillPasses bounds how often spills growing into each other are settled before giving up
+	maxSpillPasses = 10
+)
+
+// spill shows the array values at key and in the cells right and below of it, and returns the value the
+// formula cell itself shows. When the array does not fit, nothing is written and #SPILL! is returned.
+func (wb *Workbook) spill(key CellKey, values evaluatefuncs.Range) (any, bool) {
+	if wb.spills == nil {
+		wb.spills = make(map[CellKey]cellRange)
+		wb.blocked = make(map[CellKey]cellRange)
+	}
+
+	rows, cols := values.Dims()
+	if rows == 0 || cols == 0 {
+		return evaluatefuncs.ErrorValue{Code: evaluatefuncs.ErrCalc.Code, Reason: "the formula results in an empty array"}, false
+	}
+	area := cellRange{
+		Sheet:  key.Sheet,
+		Top:    key.Row,
+		Left:   key.Col,
+		Bottom: key.Row + int32(rows) - 1,
+		Right:  key.Col + int32(cols) - 1,
+	}
+	if int64(key.Row)+int64(rows)-1 > int64(utils.MAX_ROWS) || int64(key.Col)+int64(cols)-1 > int64(utils.MAX_COLS) {
+		return spillError("the array runs past the edge of the sheet"), false
+	}
+	if area.size() > maxSpillCells {
+		return spillError(fmt.Sprintf("the array of %d x %d values is too large to spill", rows, cols)), false
+	}
+
+	// Cells holding a value of their own, or spilled by another formula, block the array
+	anchor := utils.FormatCellRef(key.Row, key.Col)
+	blocker := ""
+	area.each(func(c *cell.Cell) bool {
+		if c.Row == key.Row && c.Column == key.Col {
+			return true
+		}
+		own := c.RawValue != nil && strings.TrimSpace(*c.RawValue) != ""
+		foreign := c.SpilledFrom != nil && *c.SpilledFrom != anchor
+		if own || foreign {
+			blocker = utils.FormatCellRef(c.Row, c.Column)
+			return false
+		}
+		return true
+	})
+	if blocker != "" {
+		wb.blocked[key] = area
+		return spillError("the array would overwrite " + blocker), false
+	}
+
+	if old, ok := wb.spills[key]; ok {
+		old.each(func(c *cell.Cell) bool {
+			if !area.contains(CellKey{Sheet: key.Sheet, Row: c.Row, Col: c.Column}) && c.SpilledFrom != nil && *c.SpilledFrom == anchor {
+				wb.releaseSpilled(key.Sheet, c)
+			}
+			return true
+		})
+	}
+	wb.spills[key] = area
+
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			if row == 0 && col == 0 {
+				continue
+			}
+			c := getCellInSheet(key.Sheet, key.Row+int32(row), key.Col+int32(col))
+			if c.SpilledFrom == nil {
+				// A new cell shares one string between its raw value and display, which must stay apart
+				ref, display := anchor, ""
+				c.SpilledFrom = &ref
+				c.Display = &display
+				c.ClearFlag(cell.FlagEditable)
+
+				// The cell now changes with the formula, and whatever read it while empty must read it again
+				spilledKey := CellKey{Sheet: key.Sheet, Row: c.Row, Col: c.Column}
+				wb.graph.setPrecedents(spilledKey, []CellKey{key}, nil)
+				wb.spillChanged = append(wb.spillChanged, spilledKey)
+			}
+			showValue(c, arrayValue(values, row, col))
+			wb.notifyCellUpdated(key.Sheet, c)
+		}
+	}
+
+	return arrayValue(values, 0, 0), true
+}
+
+// arrayValue returns the value at row, col of an array result, a blank reading as empty text
+func arrayValue(values evaluatefuncs.Range, row, col int) any {
+	if v := values.At(row, col); v != nil {
+		return v
+	}
+	return ""
+}
+
+// spillError is the #SPILL! shown by a formula whose array cannot be written
+func spillError(reason string) evaluatefuncs.ErrorValue {
+	return evaluatefuncs.ErrorValue{Code: evaluatefuncs.ErrSpill.Code, Reason: reason}
+}
+
+// clearSpill gives back the cells the formula at key spilled into
+func (wb *Workbook) clearSpill(key CellKey) {
+	area, ok := wb.spills[key]
+	if !ok {
+		return
+	}
+	delete(wb.spills, key)
+
+	anchor := utils.FormatCellRef(key.Row, key.Col)
+	area.each(func(c *cell.Cell) bool {
+		if c.SpilledFrom != nil && *c.SpilledFrom == anchor {
+			wb.releaseSpilled(key.Sheet, c)
+		}
+		return true
+	})
+}
+
+// releaseSpilled turns a spilled cell back into an empty, editable cell
+func (wb *Workbook) releaseSpilled(sheet *Sheet, c *cell.Cell) {
+	c.SpilledFrom = nil
+	c.SetFlag(cell.FlagEditable)
+	empty := ""
+	c.Display = &empty
+
+	key := CellKey{Sheet: sheet, Row: c.Row, Col: c.Column}
+	wb.graph.clear(key)
+	wb.spillChanged = append(wb.spillChanged, key)
+	wb.notifyCellUpdated(sheet, c)
+}
+
+// resetSpills gives back every spilled cell of the workbook, before all formulas spill again
+func (wb *Workbook) resetSpills() {
+	for _, sheet := range wb.Sheets {
+		if sheet == nil {
+			continue
+		}
+		for _, c := range sheet.Data {
+			if c.SpilledFrom != nil {
+				c.SpilledFrom = nil
+				c.SetFlag(cell.FlagEditable)
+				empty := ""
+				c.Display = &empty
+			}
+		}
+	}
+	wb.spills = make(map[CellKey]cellRange)
+	wb.blocked = make(map[CellKey]cellRange)
+	wb.spillChanged = nil
+}
+
+// settleSpills recalculates the formulas reading cells which a spill just filled or gave back,
+// until no spill moves anymore
+func (wb *Workbook) settleSpills() {
+	for pass := 0; pass < maxSpillPasses && len(wb.spillChanged) > 0; pass++ {
+		changed := wb.spillChanged
+		wb.spillChanged = nil
+		wb.recalculateKeys(changed, false)
+	}
+	wb.spillChanged = nil
+}
+
+// spillRange returns the block spilled by the formula at key, for A1# references
+func (wb *Workbook) spillRange(key CellKey) (cellRange, error) {
+	c := cellAt(key)
+	if c == nil || !c.IsFormula() {
+		return cellRange{}, errInvalidReference
+	}
+	if !c.HasFlag(cell.FlagEvaluated) {
+		var cycle *CycleError
+		if err := wb.EvaluateCell(c); errors.As(err, &cycle) {
+			return cellRange{}, err
+		}
+	}
+	if cycle := wb.cycles[key]; cycle != nil {
+		return cellRange{}, cycle
+	}
+
+	area, ok := wb.spills[key]
+	if !ok {
+		return cellRange{}, errInvalidReference
+	}
+	return area, nil
+}
+
+// SpillAnchor returns the formula cell whose array fills c, or nil when c is not spilled
+func (wb *Workbook) SpillAnchor(c *cell.Cell) *cell.Cell {
+	if c == nil || c.SpilledFrom == nil {
+		return nil
+	}
+	sheet := wb.SheetOfCell(c)
+	if sheet == nil {
+		return nil
+	}
+	anchor, err := wb.getCellFrom(sheet, *c.SpilledFrom)
+	if err != nil {
+		return nil
+	}
+	return anchor
+}
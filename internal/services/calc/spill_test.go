@@ -0,0 +1,76 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package calc
+
+import "testing"
+
+func TestSpill(t *testing.T) {
+	cases := []struct {
+		name  string
+		cells [][2]string
+		want  map[string]string
+	}{
+		{
+			name:  "column",
+			cells: [][2]string{{"A1", "$=SEQUENCE(3)"}},
+			want:  map[string]string{"A1": "1.00", "A2": "2.00", "A3": "3.00"},
+		},
+		{
+			name:  "block",
+			cells: [][2]string{{"A1", "$=SEQUENCE(2, 2)"}},
+			want:  map[string]string{"A1": "1.00", "B1": "2.00", "A2": "3.00", "B2": "4.00"},
+		},
+		{
+			name:  "blocked by a value",
+			cells: [][2]string{{"A2", "x"}, {"A1", "$=SEQUENCE(3)"}},
+			want:  map[string]string{"A1": "#SPILL!", "A2": "x"},
+		},
+		{
+			name:  "unblocked when the value is cleared",
+			cells: [][2]string{{"A2", "x"}, {"A1", "$=SEQUENCE(3)"}, {"A2", ""}},
+			want:  map[string]string{"A1": "1.00", "A2": "2.00", "A3": "3.00"},
+		},
+		{
+			name:  "blocked by another spill",
+			cells: [][2]string{{"C1", "$=SEQUENCE(2)"}, {"A2", "$=SEQUENCE(1, 3)"}},
+			want:  map[string]string{"A2": "#SPILL!", "B2": "", "C2": "2.00"},
+		},
+		{
+			name:  "spill reference",
+			cells: [][2]string{{"A1", "$=SEQUENCE(4)"}, {"B1", "$=SUM(A1#)"}, {"B2", "$=ROWS(A1#)"}},
+			want:  map[string]string{"B1": "10.00", "B2": "4.00"},
+		},
+		{
+			name:  "spill reference follows the array",
+			cells: [][2]string{{"C1", "3"}, {"A1", "$=SEQUENCE(C1)"}, {"B1", "$=SUM(A1#)"}, {"C1", "5"}},
+			want:  map[string]string{"A5": "5.00", "B1": "15.00"},
+		},
+		{
+			name:  "cells read from the spill",
+			cells: [][2]string{{"B1", "$=A3*10"}, {"A1", "$=SEQUENCE(3)"}},
+			want:  map[string]string{"B1": "30.00"},
+		},
+		{
+			name:  "shrinking gives cells back",
+			cells: [][2]string{{"C1", "3"}, {"A1", "$=SEQUENCE(C1)"}, {"C1", "1"}, {"B1", "$=ISBLANK(A3)"}},
+			want:  map[string]string{"A1": "1.00", "B1": "TRUE"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checkValues(t, workbookWith(tc.cells), tc.want)
+		})
+	}
+}
+
+func TestSpilledCellsAreReadOnly(t *testing.T) {
+	wb := workbookWith([][2]string{{"A1", "$=SEQUENCE(3)"}})
+	if _, err := wb.SetCell("A2", "x"); err == nil {
+		t.Error("a spilled cell was overwritten")
+	}
+	checkValues(t, wb, map[string]string{"A2": "2.00"})
+}
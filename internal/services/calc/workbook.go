@@ -0,0 +1,148 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// Package calc is GoSheet's formula engine. It evaluates formulas on a Workbook/Sheet model and
+// knows nothing about the terminal UI, so it can be used by batch jobs and tests as well as by the table package.
+
+// workbook.go implements the workbook and sheet model the engine works on
+
+package calc
+
+import (
+	"fmt"
+	"gosheet/internal/services/cell"
+	"gosheet/internal/services/fileop"
+	"gosheet/internal/utils"
+	"strings"
+)
+
+type Sheet struct {
+	Name string
+	Data map[[2]int]*cell.Cell
+
+	index *cellIndex
+}
+
+type Workbook struct {
+	Sheets      []*Sheet
+	ActiveSheet int
+	Names       []fileop.NamedRange
+	Calc        fileop.CalcSettings
+	CurrentFile string // the file the workbook was opened from or last saved to, "" before it is saved
+
+	// OnCellUpdated, if set, is called every time a recalculation gives a cell a new value
+	OnCellUpdated func(sheet *Sheet, c *cell.Cell)
+
+	graph         depGraph
+	cycles        map[CellKey]*CycleError
+	pending       map[CellKey]bool
+	evalStack     []CellKey
+	onStack       map[CellKey]int
+	recalculating bool
+	iterating     bool
+
+	// spills maps each formula showing an array to the block it fills, blocked those showing #SPILL! to the
+	// block they need. spillChanged collects the cells a spill filled or gave back since the last settling.
+	spills       map[CellKey]cellRange
+	blocked      map[CellKey]cellRange
+	spillChanged []CellKey
+}
+
+// NewWorkbook creates an empty workbook with a single sheet
+func NewWorkbook() *Workbook {
+	return &Workbook{
+		Sheets:      []*Sheet{NewSheet(utils.DEFAULT_SHEET_NAME)},
+		ActiveSheet: 0,
+	}
+}
+
+// NewSheet creates an empty sheet
+func NewSheet(name string) *Sheet {
+	return &Sheet{
+		Name: name,
+		Data: make(map[[2]int]*cell.Cell),
+	}
+}
+
+// OpenWorkbook loads a workbook from any supported file and evaluates all of its formulas
+func OpenWorkbook(filename string) (*Workbook, error) {
+	result, err := fileop.OpenWorkbook(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	wb := &Workbook{Names: result.Meta.Names, Calc: result.Meta.Calc, CurrentFile: filename}
+	for _, sheetResult := range result.Sheets {
+		sheet := NewSheet(sheetResult.Name)
+		for _, c := range sheetResult.Cells {
+			sheet.Data[[2]int{int(c.Row), int(c.Column)}] = c
+		}
+		wb.Sheets = append(wb.Sheets, sheet)
+	}
+
+	if len(wb.Sheets) == 0 {
+		wb.Sheets = append(wb.Sheets, NewSheet(utils.DEFAULT_SHEET_NAME))
+	}
+	if result.ActiveSheet >= 0 && result.ActiveSheet < len(wb.Sheets) {
+		wb.ActiveSheet = result.ActiveSheet
+	}
+
+	wb.EvaluateAll()
+	return wb, nil
+}
+
+// GetActiveSheet returns the sheet unqualified references are resolved against when no other sheet is known
+func (wb *Workbook) GetActiveSheet() *Sheet {
+	if wb.ActiveSheet >= 0 && wb.ActiveSheet < len(wb.Sheets) {
+		return wb.Sheets[wb.ActiveSheet]
+	}
+	return nil
+}
+
+// SetCell stores a value or a formula ($=...) at ref, which may name a sheet, and recalculates what depends on it.
+// The returned error tells why the new formula failed to evaluate.
+func (wb *Workbook) SetCell(ref, value string) (*cell.Cell, error) {
+	sheet, row, col, err := wb.resolveRef(wb.GetActiveSheet(), ref)
+	if err != nil {
+		return nil, err
+	}
+
+	c := getCellInSheet(sheet, row, col)
+	if c.SpilledFrom != nil {
+		return nil, fmt.Errorf("cell %s is part of the array spilled by %s", ref, *c.SpilledFrom)
+	}
+	if c.IsFormula() {
+		wb.ClearDependencies(c)
+		c.DependsOn = nil
+	}
+
+	value = strings.TrimSpace(value)
+	raw, display := value, value
+	c.RawValue = &raw
+	c.Display = &display
+	c.ClearFlag(cell.FlagEvaluated)
+	if strings.HasPrefix(value, "$=") {
+		c.SetFlag(cell.FlagFormula)
+	} else {
+		c.ClearFlag(cell.FlagFormula)
+	}
+
+	return c, wb.RecalculateCell(c)
+}
+
+// Value returns the displayed value of the cell at ref
+func (wb *Workbook) Value(ref string) (string, error) {
+	c, err := wb.GetCellByRef(ref)
+	if err != nil {
+		return "", err
+	}
+	if c.IsFormula() && !c.HasFlag(cell.FlagEvaluated) {
+		wb.EvaluateCell(c)
+	}
+	if c.Display == nil {
+		return "", fmt.Errorf("cell %s has no value", ref)
+	}
+	return *c.Display, nil
+}
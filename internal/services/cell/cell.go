@@ -193,6 +193,10 @@ func (c *Cell) Clone() *Cell {
 		dateTimeFormatCopy := *c.DateTimeFormat
 		clone.DateTimeFormat = &dateTimeFormatCopy
 	}
+	if c.SpilledFrom != nil {
+		spilledFromCopy := *c.SpilledFrom
+		clone.SpilledFrom = &spilledFromCopy
+	}
     
     clone.DependsOn = make([]*string, len(c.DependsOn))
     for i, dep := range c.DependsOn {
@@ -55,6 +55,9 @@ type Cell struct {
     DependsOn     []*string          
     Dependents    []*string
 
+	// SpilledFrom is the address of the formula whose array result fills this read-only cell, nil otherwise
+	SpilledFrom   *string
+
 	tvCell        *tview.TableCell
 }
 
@@ -17,3 +17,14 @@ func GetWorkbookForSave() ([]SheetInfo, int, bool) {
 	}
 	return GetWorkbookForSaveFunc()
 }
+
+// GetWorkbookMetaFunc is a hook set by the table package to provide workbook-level data such as names.
+var GetWorkbookMetaFunc func() WorkbookMeta
+
+// GetWorkbookMeta returns the current workbook-level data for saving.
+func GetWorkbookMeta() WorkbookMeta {
+	if GetWorkbookMetaFunc == nil {
+		return WorkbookMeta{}
+	}
+	return GetWorkbookMetaFunc()
+}
@@ -7,6 +7,7 @@ import (
 	"gosheet/internal/utils"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -108,8 +109,12 @@ func (h *CSVFormatHandler) Read(filename string) (*WorkbookResult, error) {
 			autotype := "auto"
 			cellType := "string"
 
-			if utils.IsNumber(value, utils.DEFAULT_CELL_FINANCIAL_SIGN) {
+			if num, err := utils.ParseNumber(value, utils.DEFAULT_CELL_FINANCIAL_SIGN); err == nil {
 				cellType = "number"
+				// A number written in the locale, like 2,5 in de-DE, is kept the way cells store numbers
+				if utils.CurrentLocale.DecimalSeparator != '.' {
+					value = strconv.FormatFloat(num, 'f', -1, 64)
+				}
 			}
 
 			c := &cell.Cell{
@@ -9,6 +9,7 @@ package fileop
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -21,6 +22,9 @@ import (
 // ExcelFormatHandler handles .xlsx files
 type ExcelFormatHandler struct{}
 
+// bareReferenceRegex matches a cell or range without a sheet qualifier, like $B$2 or A2:A500
+var bareReferenceRegex = regexp.MustCompile(`^\$?[A-Za-z]+\$?\d+(:\$?[A-Za-z]+\$?\d+)?$`)
+
 // SupportsFormat returns whether this handler supports the format
 func (h *ExcelFormatHandler) SupportsFormat(format FileFormat) bool {
 	return format == FormatXLSX
@@ -96,6 +100,9 @@ func (h *ExcelFormatHandler) Read(filename string) (*WorkbookResult, error) {
 		})
 	}
 
+	result.Meta.Names = h.readDefinedNames(f)
+	result.Meta.Calc = h.readCalcSettings(f)
+
 	return result, nil
 }
 
@@ -128,6 +135,16 @@ func (h *ExcelFormatHandler) Write(filename string, sheets []SheetInfo, activeSh
 		}
 	}
 
+	activeName := ""
+	if activeSheet >= 0 && activeSheet < len(sheets) {
+		activeName = sheets[activeSheet].Name
+	}
+	meta := GetWorkbookMeta()
+	h.writeDefinedNames(f, meta.Names, activeName)
+	if err := h.writeCalcSettings(f, meta.Calc); err != nil {
+		return fmt.Errorf("failed to write calculation properties: %v", err)
+	}
+
 	if err := f.SaveAs(filename); err != nil {
 		return fmt.Errorf("failed to save Excel file: %v", err)
 	}
@@ -135,6 +152,106 @@ func (h *ExcelFormatHandler) Write(filename string, sheets []SheetInfo, activeSh
 	return nil
 }
 
+// readDefinedNames imports the workbook's defined names, skipping Excel's built-in ones
+func (h *ExcelFormatHandler) readDefinedNames(f *excelize.File) []NamedRange {
+	var names []NamedRange
+	seen := make(map[string]bool)
+
+	for _, dn := range f.GetDefinedName() {
+		if strings.HasPrefix(strings.ToLower(dn.Name), "_xlnm.") || seen[strings.ToUpper(dn.Name)] {
+			continue
+		}
+		seen[strings.ToUpper(dn.Name)] = true
+
+		value := strings.TrimSpace(strings.TrimPrefix(dn.RefersTo, "="))
+		if isLambdaName(value) {
+			value = h.convertExcelFormulaToGoSheet(value)
+		}
+		names = append(names, NamedRange{
+			Name:    dn.Name,
+			Value:   value,
+			Comment: dn.Comment,
+		})
+	}
+
+	return names
+}
+
+// writeDefinedNames exports names as workbook-scoped defined names; Excel needs bare references qualified with a sheet
+func (h *ExcelFormatHandler) writeDefinedNames(f *excelize.File, names []NamedRange, activeName string) {
+	for _, name := range names {
+		refersTo := strings.TrimSpace(name.Value)
+		if bareReferenceRegex.MatchString(refersTo) && activeName != "" {
+			refersTo = "'" + strings.ReplaceAll(activeName, "'", "''") + "'!" + refersTo
+		}
+		if isLambdaName(refersTo) {
+			refersTo = strings.TrimPrefix(h.convertFormulaToExcel(refersTo), "=")
+		}
+
+		f.SetDefinedName(&excelize.DefinedName{
+			Name:     name.Name,
+			Comment:  name.Comment,
+			RefersTo: refersTo,
+			Scope:    "Workbook",
+		})
+	}
+}
+
+// readCalcSettings imports the calculation mode and iterative calculation properties of the workbook
+func (h *ExcelFormatHandler) readCalcSettings(f *excelize.File) CalcSettings {
+	var settings CalcSettings
+	props, err := f.GetCalcProps()
+	if err != nil {
+		return settings
+	}
+	if props.CalcMode != nil {
+		settings.Manual = *props.CalcMode == "manual"
+	}
+	if props.Iterate != nil {
+		settings.Iterative = *props.Iterate
+	}
+	if props.IterateCount != nil {
+		settings.MaxIterations = int(*props.IterateCount)
+	}
+	if props.IterateDelta != nil {
+		settings.MaxChange = *props.IterateDelta
+	}
+	return settings
+}
+
+// writeCalcSettings exports the calculation mode and iterative calculation settings as calculation
+// properties; Excel's own defaults are left alone for a workbook which keeps them. The seed of the
+// random numbers has no place in XLSX files.
+func (h *ExcelFormatHandler) writeCalcSettings(f *excelize.File, settings CalcSettings) error {
+	if !settings.Manual && !settings.Iterative && settings.MaxIterations == 0 && settings.MaxChange == 0 {
+		return nil
+	}
+	props := &excelize.CalcPropsOptions{Iterate: &settings.Iterative}
+	if settings.Manual {
+		mode := "manual"
+		props.CalcMode = &mode
+	}
+	if settings.MaxIterations > 0 {
+		count := uint(settings.MaxIterations)
+		props.IterateCount = &count
+	}
+	if err := f.SetCalcProps(props); err != nil {
+		return err
+	}
+
+	// SetCalcProps cannot set the delta, a float, so it goes straight into the calculation properties it made
+	if settings.MaxChange > 0 && f.WorkBook != nil && f.WorkBook.CalcPr != nil {
+		f.WorkBook.CalcPr.IterateDelta = settings.MaxChange
+	}
+	return nil
+}
+
+// isLambdaName reports whether a defined name holds a LAMBDA function rather than a reference or a constant
+func isLambdaName(value string) bool {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	return strings.HasPrefix(value, "LAMBDA(") || strings.HasPrefix(value, "_XLFN.LAMBDA(")
+}
+
 // readSheet reads a single sheet from Excel file
 func (h *ExcelFormatHandler) readSheet(f *excelize.File, sheetName string) ([]*cell.Cell, int32, int32, error) {
 	rows, err := f.GetRows(sheetName)
@@ -188,7 +305,7 @@ func (h *ExcelFormatHandler) readSheet(f *excelize.File, sheetName string) ([]*c
 				rawValue = "$=" + h.convertExcelFormulaToGoSheet(formula)
 				typeValue = "formula"
 			} else {
-				if utils.IsNumber(cellValue, utils.DEFAULT_CELL_FINANCIAL_SIGN) {
+				if utils.IsCanonicalNumber(cellValue, utils.DEFAULT_CELL_FINANCIAL_SIGN) {
 					typeValue = "number"
 				} else if isValid, format := utils.IsValidDateTime(cellValue); isValid {
 					typeValue = "datetime"
@@ -243,8 +360,29 @@ func (h *ExcelFormatHandler) readSheet(f *excelize.File, sheetName string) ([]*c
 	return cells, maxRow, maxCol, nil
 }
 
+// spillAreas returns the block each formula of a sheet spills its array into, like A1:B3, by the address
+// of the formula
+func spillAreas(data map[[2]int]*cell.Cell) map[string]string {
+	ends := make(map[string][2]int32)
+	for _, c := range data {
+		if c.SpilledFrom == nil {
+			continue
+		}
+		end := ends[*c.SpilledFrom]
+		ends[*c.SpilledFrom] = [2]int32{max(end[0], c.Row), max(end[1], c.Column)}
+	}
+
+	areas := make(map[string]string, len(ends))
+	for anchor, end := range ends {
+		areas[anchor] = anchor + ":" + utils.FormatCellRef(end[0], end[1])
+	}
+	return areas
+}
+
 // writeSheet writes a single sheet to Excel file
 func (h *ExcelFormatHandler) writeSheet(f *excelize.File, sheetName string, sheet SheetInfo) error {
+	spills := spillAreas(sheet.GlobalData)
+
 	for _, cellData := range sheet.GlobalData {
 		row, col := int(cellData.Row), int(cellData.Column)
 
@@ -255,14 +393,27 @@ func (h *ExcelFormatHandler) writeSheet(f *excelize.File, sheetName string, shee
 
 		h.writeCellFormatting(f, sheetName, cellCoord, cellData)
 
+		// Spilled values are left to the formula of their anchor, which Excel spills again
+		if cellData.SpilledFrom != nil {
+			continue
+		}
+
 		if cellData.IsFormula() && cellData.RawValue != nil {
 			formulaStr := strings.TrimPrefix(*cellData.RawValue, "$=")
 			formulaStr = strings.TrimSpace(formulaStr)
 			
 			if formulaStr != "" {
 				excelFormula := h.convertFormulaToExcel(formulaStr)
+
+				// A formula spilling an array is written as an array formula over the block it fills, so
+				// Excel shows every value rather than the first one
+				var opts []excelize.FormulaOpts
+				if area, ok := spills[cellCoord]; ok {
+					formulaType := excelize.STCellFormulaTypeArray
+					opts = append(opts, excelize.FormulaOpts{Type: &formulaType, Ref: &area})
+				}
 				
-				if err := f.SetCellFormula(sheetName, cellCoord, excelFormula); err != nil {
+				if err := f.SetCellFormula(sheetName, cellCoord, excelFormula, opts...); err != nil {
 					if cellData.Display != nil && *cellData.Display != "" {
 						f.SetCellValue(sheetName, cellCoord, *cellData.Display)
 					}
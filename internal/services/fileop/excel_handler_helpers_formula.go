@@ -7,7 +7,10 @@
 
 package fileop
 
-import "strings"
+import (
+	"regexp"
+	"strings"
+)
 
 // convertFormulaToExcel converts GoSheet formula syntax to Excel syntax
 func (h *ExcelFormatHandler) convertFormulaToExcel(formula string) string {
@@ -50,6 +53,16 @@ func (h *ExcelFormatHandler) convertFormulaToExcel(formula string) string {
 	formula = replaceBesselJ1(formula)
 	formula = replaceBesselYN(formula)
 
+	formula = spillRefPattern.ReplaceAllString(formula, "ANCHORARRAY($1)")
+	formula = prefixParameters(formula)
+
+	for _, name := range worksheetFunctions {
+		formula = prefixFunction(formula, name, "_xlfn._xlws.")
+	}
+	for _, name := range futureFunctions {
+		formula = prefixFunction(formula, name, "_xlfn.")
+	}
+
 	return formula
 }
 
@@ -57,11 +70,12 @@ func (h *ExcelFormatHandler) convertFormulaToExcel(formula string) string {
 func (h *ExcelFormatHandler) convertExcelFormulaToGoSheet(formula string) string {
 	formula = strings.TrimPrefix(formula, "=")
 	formula = strings.ToUpper(formula)
-	formula = strings.TrimPrefix(formula, "_xludf.")
-	formula = strings.TrimPrefix(formula, "_XLUDF.")
-	formula = strings.TrimPrefix(formula, "_xlfn.")
-	formula = strings.TrimPrefix(formula, "_XLFN.")
+	formula = strings.ReplaceAll(formula, "_XLUDF.", "")
+	formula = strings.ReplaceAll(formula, "_XLFN.", "")
+	formula = strings.ReplaceAll(formula, "_XLWS.", "")
+	formula = strings.ReplaceAll(formula, "_XLPM.", "")
 	formula = strings.TrimSpace(formula)
+	formula = replaceAnchorArray(formula)
 
 	simpleReplacements := map[string]string{
 		"AVERAGE":   "AVG",
@@ -71,6 +85,19 @@ func (h *ExcelFormatHandler) convertExcelFormulaToGoSheet(formula string) string
 		"DATEDIF":   "DATEDIFF",
 		"BITLSHIFT": "BITSHIFTLEFT",
 		"BITRSHIFT": "BITSHIFTRIGHT",
+
+		"STDEV":           "STDEV.S",
+		"STDEVP":          "STDEV.P",
+		"VAR":             "VAR.S",
+		"VARP":            "VAR.P",
+		"MODE.SNGL":       "MODE",
+		"PERCENTILE.INC":  "PERCENTILE",
+		"QUARTILE.INC":    "QUARTILE",
+		"RANK.EQ":         "RANK",
+		"COVARIANCE.P":    "COVAR",
+		"FORECAST.LINEAR": "FORECAST",
+		"NORMDIST":        "NORM.DIST",
+		"NORMINV":         "NORM.INV",
 	}
 
 	for excel, gosheet := range simpleReplacements {
@@ -98,6 +125,187 @@ func (h *ExcelFormatHandler) convertExcelFormulaToGoSheet(formula string) string
 	return formula
 }
 
+// futureFunctions lists functions Excel stores with the _xlfn. prefix
+// because they were added after the original xlsx specification
+var futureFunctions = []string{
+	"XLOOKUP",
+	"IFNA",
+	"MAXIFS",
+	"MINIFS",
+	"STDEV.S",
+	"STDEV.P",
+	"VAR.S",
+	"VAR.P",
+	"NORM.DIST",
+	"NORM.INV",
+	"T.DIST",
+	"SEQUENCE",
+	"UNIQUE",
+	"ANCHORARRAY",
+	"LET",
+	"LAMBDA",
+	"FORMULATEXT",
+	"ISFORMULA",
+}
+
+// worksheetFunctions lists functions Excel stores with the _xlfn._xlws. prefix
+var worksheetFunctions = []string{
+	"SORT",
+	"FILTER",
+}
+
+// spillRefPattern matches a reference to a spilled array, A1# or Sheet2!$A$1#, which Excel stores as ANCHORARRAY(A1)
+var spillRefPattern = regexp.MustCompile(`((?:(?:'[^']*'|[A-Z0-9_.]+)!)?\$?[A-Z]{1,3}\$?[0-9]+)#`)
+
+// replaceAnchorArray turns Excel's ANCHORARRAY(A1) back into the spill reference A1#
+func replaceAnchorArray(formula string) string {
+	searchStr := "ANCHORARRAY("
+	for {
+		idx := strings.Index(formula, searchStr)
+		if idx == -1 {
+			return formula
+		}
+		args, endIdx := extractFunctionArgs(formula, idx+len(searchStr)-1)
+		if endIdx == -1 {
+			return formula
+		}
+		formula = formula[:idx] + strings.TrimSpace(args) + "#" + formula[endIdx+1:]
+	}
+}
+
+// prefixParameters prepends _xlpm. to the names declared by LET and LAMBDA wherever the call declaring them
+// uses them: LET(X, 1, X+1) -> LET(_xlpm.X, 1, _xlpm.X+1). The same word outside of the call, in a string,
+// as a function, as a sheet name or in a column or row reference like X:X is left alone.
+func prefixParameters(formula string) string {
+	type scope struct {
+		names map[string]bool
+		end   int
+	}
+	var scopes []scope
+	declared := func(word string) bool {
+		for _, s := range scopes {
+			if s.names[word] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var result strings.Builder
+	for i := 0; i < len(formula); {
+		for len(scopes) > 0 && i > scopes[len(scopes)-1].end {
+			scopes = scopes[:len(scopes)-1]
+		}
+
+		ch := formula[i]
+		if ch == '"' || ch == '\'' {
+			end := quotedEnd(formula, i)
+			result.WriteString(formula[i:end])
+			i = end
+			continue
+		}
+		if !isFormulaIdentChar(ch) {
+			result.WriteByte(ch)
+			i++
+			continue
+		}
+
+		end := i
+		for end < len(formula) && isFormulaIdentChar(formula[end]) {
+			end++
+		}
+		word := formula[i:end]
+		next := end
+		for next < len(formula) && formula[next] == ' ' {
+			next++
+		}
+		calls := next < len(formula) && formula[next] == '('
+		if calls && (word == "LET" || word == "LAMBDA") {
+			names, callEnd := declaredNames(formula, word, next)
+			scopes = append(scopes, scope{names: names, end: callEnd})
+		}
+
+		reference := (i > 0 && (formula[i-1] == '$' || formula[i-1] == ':')) ||
+			(end < len(formula) && (formula[end] == '!' || formula[end] == ':' || formula[end] == '$'))
+		if declared(word) && !calls && !reference {
+			result.WriteString("_xlpm.")
+		}
+		result.WriteString(word)
+		i = end
+	}
+	return result.String()
+}
+
+// declaredNames returns the names declared by the LET or LAMBDA call whose parenthesis is at open, and
+// where the call ends
+func declaredNames(formula, funcName string, open int) (map[string]bool, int) {
+	args, end := extractFunctionArgs(formula, open)
+	if end == -1 {
+		return nil, len(formula)
+	}
+
+	names := make(map[string]bool)
+	parts := splitFunctionArgs(args)
+	for i := 0; i < len(parts)-1; i++ {
+		if funcName == "LET" && i%2 == 1 {
+			continue
+		}
+		names[strings.TrimSpace(parts[i])] = true
+	}
+	return names, end
+}
+
+// quotedEnd returns where the string or quoted sheet name starting at i ends. A string escapes its quotes
+// with a backslash, a sheet name doubles them.
+func quotedEnd(formula string, i int) int {
+	quote := formula[i]
+	for j := i + 1; j < len(formula); j++ {
+		switch {
+		case quote == '"' && formula[j] == '\\':
+			j++
+		case formula[j] != quote:
+		case quote == '\'' && j+1 < len(formula) && formula[j+1] == '\'':
+			j++
+		default:
+			return j + 1
+		}
+	}
+	return len(formula)
+}
+
+// isFormulaIdentChar reports whether ch can be part of a function, name or cell reference
+func isFormulaIdentChar(ch byte) bool {
+	return (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9') || ch == '_' || ch == '.'
+}
+
+// prefixFunction prepends prefix to every call of funcName: XLOOKUP(x) -> _xlfn.XLOOKUP(x)
+func prefixFunction(formula, funcName, prefix string) string {
+	var result strings.Builder
+	searchStr := funcName + "("
+
+	for {
+		idx := strings.Index(formula, searchStr)
+		if idx == -1 {
+			break
+		}
+
+		result.WriteString(formula[:idx])
+		if idx > 0 {
+			prev := formula[idx-1]
+			if !((prev >= 'A' && prev <= 'Z') || (prev >= '0' && prev <= '9') || prev == '_' || prev == '.') {
+				result.WriteString(prefix)
+			}
+		} else {
+			result.WriteString(prefix)
+		}
+		result.WriteString(searchStr)
+		formula = formula[idx+len(searchStr):]
+	}
+	result.WriteString(formula)
+
+	return result.String()
+}
+
 // replaceFunction replaces a function name while preserving its arguments
 func replaceFunction(formula, oldFunc, newFunc string) string {
 	result := formula
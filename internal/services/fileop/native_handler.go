@@ -61,6 +61,7 @@ func (h *NativeFormatHandler) Read(filename string) (*WorkbookResult, error) {
 		Sheets:      make([]SheetResult, 0, len(wbData.Sheets)),
 		ActiveSheet: wbData.ActiveSheet,
 		Version:     wbData.Version,
+		Meta:        wbData.WorkbookMeta,
 	}
 
 	for _, sheetData := range wbData.Sheets {
@@ -82,8 +83,9 @@ func (h *NativeFormatHandler) Write(filename string, sheets []SheetInfo, activeS
 	
 	wbData := WorkbookData{
 		Version:     utils.FILEVER,
-		ActiveSheet: activeSheet,
-		Sheets:      make([]SheetData, 0, len(sheets)),
+		ActiveSheet:  activeSheet,
+		Sheets:       make([]SheetData, 0, len(sheets)),
+		WorkbookMeta: GetWorkbookMeta(),
 	}
 
 	for _, sheet := range sheets {
@@ -15,6 +15,7 @@ import (
 )
 
 const recentFilesPath = ".gosheet/recent.cf"
+const localePath = ".gosheet/locale.cf"
 
 // getRecentFileList reads recent files from a config file
 func GetRecentFileList() ([]string, []string) {
@@ -97,4 +98,28 @@ func AddToRecentFiles(filepathtodir string) {
 	os.WriteFile(recentFile, []byte(strings.Join(existing, "\n")), 0644)
 }
 
+// GetLocaleSetting reads the locale formulas and numbers are typed in, empty when none was chosen
+func GetLocaleSetting() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(homeDir, localePath))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
 
+// SaveLocaleSetting keeps the locale formulas and numbers are typed in for the next sessions
+func SaveLocaleSetting(name string) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	localeFile := filepath.Join(homeDir, localePath)
+	if err := os.MkdirAll(filepath.Dir(localeFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(localeFile, []byte(name), 0644)
+}
@@ -146,6 +146,32 @@ type WorkbookData struct {
 	Version     string      `json:"version"`
 	ActiveSheet int         `json:"active_sheet"`
 	Sheets      []SheetData `json:"sheets"`
+	WorkbookMeta
+}
+
+// WorkbookMeta holds workbook-level data that does not belong to a single sheet
+type WorkbookMeta struct {
+	Names []NamedRange `json:"names,omitempty"`
+	Calc  CalcSettings `json:"calc"`
+}
+
+// CalcSettings holds how the workbook recalculates. With Manual set, an edit only calculates the edited
+// cells and the rest waits for a recalculation asked for. With Iterative set, circular references are
+// calculated over and over, up to MaxIterations times or until no value changes by more than MaxChange.
+// A Seed other than 0 makes RAND and RANDBETWEEN give the same numbers on every recalculation.
+type CalcSettings struct {
+	Manual        bool    `json:"manual,omitempty"`
+	Iterative     bool    `json:"iterative,omitempty"`
+	MaxIterations int     `json:"max_iterations,omitempty"`
+	MaxChange     float64 `json:"max_change,omitempty"`
+	Seed          int64   `json:"seed,omitempty"`
+}
+
+// NamedRange is a workbook-level name for a reference (Sheet1!$B$2, A2:A500) or a constant (0.19)
+type NamedRange struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Comment string `json:"comment,omitempty"`
 }
 
 // SheetData represents a single sheet's data
@@ -176,6 +202,7 @@ type WorkbookResult struct {
 	ActiveSheet int
 	Version     string
 	Format      FileFormat
+	Meta        WorkbookMeta
 }
 
 // SheetResult contains loaded sheet data
@@ -0,0 +1,322 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// audit.go shows the precedents and dependents of the selected cell, colored on the grid and listed in a side panel
+
+package table
+
+import (
+	"fmt"
+	"gosheet/internal/services/calc"
+	"gosheet/internal/utils"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Grid colors of the cells the selected formula reads and of the formulas reading the selected cell
+const (
+	precedentColor = tcell.ColorDarkBlue
+	dependentColor = tcell.ColorDarkRed
+)
+
+// auditState is the audit mode in progress: the panel beside the grid and the cells stepped through
+type auditState struct {
+	app        *tview.Application
+	layout     *tview.Flex
+	header     *tview.TextView
+	precedents *tview.List
+	dependents *tview.List
+	precKeys   []calc.CellKey
+	depKeys    []calc.CellKey
+	trail      []calc.CellKey
+}
+
+// auditing is the audit mode in progress, nil while the grid is used as usual
+var auditing *auditState
+
+// ToggleAuditMode shows the grid with the audit panel beside it, or puts the grid back on its own
+func ToggleAuditMode(app *tview.Application, table *tview.Table) {
+	if auditing != nil {
+		auditing.leave(table)
+		return
+	}
+	if globalWorkbook == nil {
+		return
+	}
+
+	a := &auditState{
+		app:        app,
+		header:     tview.NewTextView().SetDynamicColors(true).SetWrap(true),
+		precedents: tview.NewList(),
+		dependents: tview.NewList(),
+	}
+	a.header.SetBorder(true).SetTitle(" Audit ")
+
+	for _, list := range []*tview.List{a.precedents, a.dependents} {
+		list.SetSecondaryTextColor(tcell.ColorGray).SetBorder(true)
+		list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			return a.handleList(table, event)
+		})
+	}
+	a.precedents.SetBorderColor(tcell.ColorBlue)
+	a.dependents.SetBorderColor(tcell.ColorRed)
+	a.precedents.SetSelectedFunc(func(i int, _, _ string, _ rune) {
+		if i < len(a.precKeys) {
+			a.step(table, a.precKeys[i])
+		}
+	})
+	a.dependents.SetSelectedFunc(func(i int, _, _ string, _ rune) {
+		if i < len(a.depKeys) {
+			a.step(table, a.depKeys[i])
+		}
+	})
+
+	help := tview.NewTextView().SetDynamicColors(true).SetWrap(true).
+		SetText("[yellow]Enter[-] jump  [yellow][ ][-] step into\n[yellow]Backspace[-] back  [yellow]Tab[-] panel  [yellow]Esc[-] leave")
+
+	panel := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.header, 6, 0, false).
+		AddItem(a.precedents, 0, 1, false).
+		AddItem(a.dependents, 0, 1, false).
+		AddItem(help, 2, 0, false)
+	a.layout = tview.NewFlex().
+		AddItem(table, 0, 1, true).
+		AddItem(panel, 40, 0, false)
+
+	auditing = a
+	app.SetRoot(a.layout, true).SetFocus(table)
+	a.refresh(table)
+}
+
+// handle applies a key pressed on the grid in audit mode. Arrows and paging move the audited cell as
+// usual; any other command leaves audit mode first, so dialogs come back to the grid on its own.
+func (a *auditState) handle(table *tview.Table, event *tcell.EventKey) *tcell.EventKey {
+	switch {
+	case event.Key() == tcell.KeyEscape, (event.Rune() == 'u' || event.Rune() == 'U') && event.Modifiers()&tcell.ModAlt != 0:
+		a.leave(table)
+		return nil
+	case event.Key() == tcell.KeyTab:
+		a.focusPanel(a.precedents, a.dependents)
+		return nil
+	case event.Key() == tcell.KeyBacktab:
+		a.focusPanel(a.dependents, a.precedents)
+		return nil
+	case event.Key() == tcell.KeyBackspace || event.Key() == tcell.KeyBackspace2:
+		a.back(table)
+		return nil
+	case event.Key() == tcell.KeyRune && event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) == 0:
+		switch event.Rune() {
+		case '[':
+			if len(a.precKeys) > 0 {
+				a.step(table, a.precKeys[0])
+			}
+		case ']':
+			if len(a.depKeys) > 0 {
+				a.step(table, a.depKeys[0])
+			}
+		}
+		return nil
+	}
+
+	if event.Modifiers() == 0 {
+		switch event.Key() {
+		case tcell.KeyUp, tcell.KeyDown, tcell.KeyLeft, tcell.KeyRight,
+			tcell.KeyPgUp, tcell.KeyPgDn, tcell.KeyHome, tcell.KeyEnd:
+			return event
+		}
+	}
+
+	a.leave(table)
+	return event
+}
+
+// handleList applies a key pressed in one of the panel lists; Enter is left to the list, which steps to the entry
+func (a *auditState) handleList(table *tview.Table, event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyTab:
+		if a.app.GetFocus() == a.precedents && a.dependents.GetItemCount() > 0 {
+			a.app.SetFocus(a.dependents)
+		} else {
+			a.app.SetFocus(table)
+		}
+		return nil
+	case tcell.KeyBacktab:
+		if a.app.GetFocus() == a.dependents && a.precedents.GetItemCount() > 0 {
+			a.app.SetFocus(a.precedents)
+		} else {
+			a.app.SetFocus(table)
+		}
+		return nil
+	case tcell.KeyEscape:
+		a.app.SetFocus(table)
+		return nil
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		a.back(table)
+		return nil
+	}
+	return event
+}
+
+// focusPanel moves the focus to the first of the lists which has entries
+func (a *auditState) focusPanel(lists ...*tview.List) {
+	for _, list := range lists {
+		if list.GetItemCount() > 0 {
+			a.app.SetFocus(list)
+			return
+		}
+	}
+}
+
+// step moves to key, remembering the cell it leaves so Backspace can come back to it
+func (a *auditState) step(table *tview.Table, key calc.CellKey) {
+	if current, ok := selectedKey(table); ok {
+		a.trail = append(a.trail, current)
+	}
+	a.goTo(table, key)
+}
+
+// back returns to the cell the last step left
+func (a *auditState) back(table *tview.Table) {
+	if len(a.trail) == 0 {
+		return
+	}
+	key := a.trail[len(a.trail)-1]
+	a.trail = a.trail[:len(a.trail)-1]
+	a.goTo(table, key)
+}
+
+// goTo selects the cell at key, switching to its sheet and scrolling it into view when needed
+func (a *auditState) goTo(table *tview.Table, key calc.CellKey) {
+	for i, sheet := range globalWorkbook.Sheets {
+		if sheet.Sheet == key.Sheet && i != globalWorkbook.ActiveSheet {
+			SwitchSheet(a.app, table, i)
+			break
+		}
+	}
+
+	activeData := GetActiveSheetData()
+	activeViewport := GetActiveViewport()
+	if activeData == nil || activeViewport == nil {
+		return
+	}
+	if !activeViewport.IsVisible(key.Row, key.Col) {
+		activeViewport.TopRow, activeViewport.LeftCol = key.Row, key.Col
+		RenderVisible(table, activeViewport, activeData)
+	}
+	visualRow, visualCol := activeViewport.ToRelative(key.Row, key.Col)
+	table.Select(int(visualRow), int(visualCol))
+}
+
+// refresh lists and colors the precedents and dependents of the selected cell; it runs every time the selection changes
+func (a *auditState) refresh(table *tview.Table) {
+	a.precKeys, a.depKeys = nil, nil
+	key, ok := selectedKey(table)
+	if ok {
+		a.precKeys = globalWorkbook.Precedents(key)
+		a.depKeys = globalWorkbook.Dependents(key)
+		a.color(table, a.precKeys, precedentColor)
+		a.color(table, a.depKeys, dependentColor)
+	}
+
+	a.header.Clear()
+	if ok {
+		ref, value, formula := describeCell(key)
+		fmt.Fprintf(a.header, "[yellow::b]%s[-::-]\n%s", tview.Escape(ref), tview.Escape(value))
+		if formula != "" {
+			fmt.Fprintf(a.header, "\n[gray]%s[-]", tview.Escape(formula))
+		}
+	}
+	if len(a.trail) > 0 {
+		a.header.SetTitle(fmt.Sprintf(" Audit · step %d ", len(a.trail)))
+	} else {
+		a.header.SetTitle(" Audit ")
+	}
+
+	fill(a.precedents, a.precKeys, " Precedents (%d) ")
+	fill(a.dependents, a.depKeys, " Dependents (%d) ")
+
+	// A list left empty by the step hands the focus on
+	switch focus := a.app.GetFocus(); {
+	case focus == a.precedents && len(a.precKeys) == 0, focus == a.dependents && len(a.depKeys) == 0:
+		a.app.SetFocus(table)
+		a.focusPanel(a.precedents, a.dependents)
+	}
+}
+
+// color paints the cells of keys which are shown on the grid
+func (a *auditState) color(table *tview.Table, keys []calc.CellKey, color tcell.Color) {
+	active := globalWorkbook.GetActiveSheet()
+	for _, key := range keys {
+		if key.Sheet != active.Sheet || !active.Viewport.IsVisible(key.Row, key.Col) {
+			continue
+		}
+		visualRow, visualCol := active.Viewport.ToRelative(key.Row, key.Col)
+		if tvCell := table.GetCell(int(visualRow), int(visualCol)); tvCell != nil {
+			tvCell.SetBackgroundColor(color)
+		}
+	}
+}
+
+// leave puts the grid back on its own, without the audit colors
+func (a *auditState) leave(table *tview.Table) {
+	auditing = nil
+	a.app.SetRoot(table, true).SetFocus(table)
+
+	if activeViewport := GetActiveViewport(); activeViewport != nil {
+		RenderVisible(table, activeViewport, GetActiveSheetData())
+		table.Select(table.GetSelection())
+	}
+}
+
+// fill lists the cells of keys, with their value and, below it, their formula
+func fill(list *tview.List, keys []calc.CellKey, title string) {
+	current := list.GetCurrentItem()
+	list.Clear()
+	for _, key := range keys {
+		ref, value, formula := describeCell(key)
+		list.AddItem(tview.Escape(fmt.Sprintf("%-12s %s", ref, value)), tview.Escape(formula), 0, nil)
+	}
+	list.ShowSecondaryText(len(keys) > 0)
+	if current < len(keys) {
+		list.SetCurrentItem(current)
+	}
+	list.SetTitle(fmt.Sprintf(title, len(keys)))
+}
+
+// selectedKey returns the cell selected on the active sheet, false when a header is selected
+func selectedKey(table *tview.Table) (calc.CellKey, bool) {
+	active := globalWorkbook.GetActiveSheet()
+	if active == nil {
+		return calc.CellKey{}, false
+	}
+	visualRow, visualCol := utils.ConvertToInt32(table.GetSelection())
+	absRow, absCol := active.Viewport.ToAbsolute(visualRow, visualCol)
+	if absRow < 1 || absCol < 1 {
+		return calc.CellKey{}, false
+	}
+	return calc.CellKey{Sheet: active.Sheet, Row: absRow, Col: absCol}, true
+}
+
+// describeCell returns the address of the cell, naming its sheet when it is not the active one, what it
+// shows and its formula, empty for a value
+func describeCell(key calc.CellKey) (ref, value, formula string) {
+	ref = utils.FormatCellRef(key.Row, key.Col)
+	if active := globalWorkbook.GetActiveSheet(); active == nil || active.Sheet != key.Sheet {
+		ref = key.String()
+	}
+
+	c, exists := key.Sheet.Data[[2]int{int(key.Row), int(key.Col)}]
+	if !exists {
+		return ref, "(empty)", ""
+	}
+	if c.Display != nil {
+		value = *c.Display
+	}
+	if c.IsFormula() && c.RawValue != nil {
+		formula = utils.CurrentLocale.LocalizeFormula(*c.RawValue)
+	}
+	return ref, value, formula
+}
@@ -9,6 +9,7 @@ package table
 
 import (
 	"fmt"
+	"gosheet/internal/services/calc"
 	"gosheet/internal/services/cell"
 	"gosheet/internal/services/ui"
 	"gosheet/internal/utils"
@@ -180,7 +181,7 @@ func performFill(table *tview.Table, r1, c1, r2, c2 int32, direction FillDirecti
 				r := r2 + 1 + int32(i)
 				for colOffset := int32(0); colOffset <= c2-c1; colOffset++ {
 					c := c1 + colOffset
-					value := pattern.GetNext(fillIndex)
+					value := fillValue(pattern, fillSource(activeData, r1, c1, r2, c2, direction, r, c), fillIndex, r, c)
 					createFilledCell(table, r, c, value)
 					fillIndex++
 				}
@@ -188,7 +189,7 @@ func performFill(table *tview.Table, r1, c1, r2, c2 int32, direction FillDirecti
 				c := c2 + 1 + int32(i)
 				for rowOffset := int32(0); rowOffset <= r2-r1; rowOffset++ {
 					r := r1 + rowOffset
-					value := pattern.GetNext(fillIndex)
+					value := fillValue(pattern, fillSource(activeData, r1, c1, r2, c2, direction, r, c), fillIndex, r, c)
 					createFilledCell(table, r, c, value)
 					fillIndex++
 				}
@@ -200,7 +201,7 @@ func performFill(table *tview.Table, r1, c1, r2, c2 int32, direction FillDirecti
 				r := r1 - int32(count) + int32(i)
 				for colOffset := int32(0); colOffset <= c2-c1; colOffset++ {
 					c := c1 + colOffset
-					value := pattern.GetNext(fillIndex)
+					value := fillValue(pattern, fillSource(activeData, r1, c1, r2, c2, direction, r, c), fillIndex, r, c)
 					createFilledCell(table, r, c, value)
 					fillIndex++
 				}
@@ -208,7 +209,7 @@ func performFill(table *tview.Table, r1, c1, r2, c2 int32, direction FillDirecti
 				c := c1 - int32(count) + int32(i)
 				for rowOffset := int32(0); rowOffset <= r2-r1; rowOffset++ {
 					r := r1 + rowOffset
-					value := pattern.GetNext(fillIndex)
+					value := fillValue(pattern, fillSource(activeData, r1, c1, r2, c2, direction, r, c), fillIndex, r, c)
 					createFilledCell(table, r, c, value)
 					fillIndex++
 				}
@@ -230,8 +231,17 @@ func createFilledCell(table *tview.Table, r, c int32, value string) {
 	}
 
 	newCell := cell.GetOrCreateCell(table, r, c, activeData)
-	*newCell.RawValue = value
-	*newCell.Display = value
+	globalWorkbook.ClearDependencies(newCell)
+	newCell.DependsOn = nil
+
+	rawValue, display := value, value
+	newCell.RawValue = &rawValue
+	newCell.Display = &display
+
+	if newCell.IsFormula() {
+		newCell.ClearFlag(cell.FlagEvaluated)
+	}
+	RecalculateCell(table, newCell)
 
 	if activeViewport.IsVisible(r, c) {
 		visualR, visualC := activeViewport.ToRelative(r, c)
@@ -239,6 +249,32 @@ func createFilledCell(table *tview.Table, r, c int32, value string) {
 	}
 }
 
+// fillSource returns the cell of the selection r1:c1 to r2:c2 which the filled cell at r, c repeats: the one
+// of its column (of its row when filling sideways) at the same place in the selection, counting on from it
+// downwards or rightwards and back from it upwards or leftwards
+func fillSource(data map[[2]int]*cell.Cell, r1, c1, r2, c2 int32, direction FillDirection, r, c int32) *cell.Cell {
+	if direction == FillDown || direction == FillUp {
+		n := r2 - r1 + 1
+		r = r1 + ((r-r1)%n+n)%n
+	} else {
+		n := c2 - c1 + 1
+		c = c1 + ((c-c1)%n+n)%n
+	}
+	if src, exists := data[[2]int{int(r), int(c)}]; exists {
+		return src
+	}
+	return cell.NewCell(r, c, "")
+}
+
+// fillValue returns the value of a filled cell: the pattern value, or the formula of its source cell with
+// the relative references shifted
+func fillValue(pattern Pattern, src *cell.Cell, index int, r, c int32) string {
+	if src.IsFormula() {
+		return "$=" + calc.OffsetFormulaRefs(src.GetFormulaExpression(), r-src.Row, c-src.Column)
+	}
+	return pattern.GetNext(index)
+}
+
 // Pattern interface for different fill types
 type Pattern interface {
 	GetNext(index int) string
@@ -0,0 +1,88 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package table
+
+import (
+	"testing"
+
+	"github.com/rivo/tview"
+)
+
+func TestFillFormulas(t *testing.T) {
+	cases := []struct {
+		name           string
+		cells          [][2]string
+		r1, c1, r2, c2 int32
+		direction      FillDirection
+		count          int
+		want           map[string]string
+	}{
+		{
+			name:  "two columns down",
+			cells: [][2]string{{"A1", "$=C1+1"}, {"A2", "$=C2+2"}, {"B1", "$=A1*10"}, {"B2", "$=$A2*100"}},
+			r1:    1, c1: 1, r2: 2, c2: 2,
+			direction: FillDown,
+			count:     3,
+			want: map[string]string{
+				"A3": "$=C3+1", "B3": "$=A3*10",
+				"A4": "$=C4+2", "B4": "$=$A4*100",
+				"A5": "$=C5+1", "B5": "$=A5*10",
+			},
+		},
+		{
+			name:  "two rows right",
+			cells: [][2]string{{"A1", "$=A3*2"}, {"B1", "$=B3*3"}, {"A2", "$=A1+1"}, {"B2", "$=B1+A$1"}},
+			r1:    1, c1: 1, r2: 2, c2: 2,
+			direction: FillRight,
+			count:     3,
+			want: map[string]string{
+				"C1": "$=C3*2", "C2": "$=C1+1",
+				"D1": "$=D3*3", "D2": "$=D1+C$1",
+				"E1": "$=E3*2", "E2": "$=E1+1",
+			},
+		},
+		{
+			name:  "up",
+			cells: [][2]string{{"A5", "$=B5*1"}, {"A6", "$=B6*2"}},
+			r1:    5, c1: 1, r2: 6, c2: 1,
+			direction: FillUp,
+			count:     3,
+			want:      map[string]string{"A4": "$=B4*2", "A3": "$=B3*1", "A2": "$=B2*2"},
+		},
+		{
+			name:  "left",
+			cells: [][2]string{{"D1", "$=D2+1"}, {"E1", "$=E2+2"}, {"F1", "$=F2+3"}},
+			r1:    1, c1: 4, r2: 1, c2: 6,
+			direction: FillLeft,
+			count:     2,
+			want:      map[string]string{"C1": "$=C2+3", "B1": "$=B2+2"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			globalWorkbook = NewWorkbook()
+			table := tview.NewTable()
+			for _, c := range tc.cells {
+				if _, err := globalWorkbook.SetCell(c[0], c[1]); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			performFill(table, tc.r1, tc.c1, tc.r2, tc.c2, tc.direction, tc.count, 0)
+
+			for ref, want := range tc.want {
+				c, err := globalWorkbook.GetCellByRef(ref)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if c.RawValue == nil || *c.RawValue != want {
+					t.Errorf("%s = %v, want %q", ref, c.RawValue, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// calculation.go provides the Calculation Options dialog, which holds how the workbook recalculates
+
+package table
+
+import (
+	"fmt"
+	"gosheet/internal/services/fileop"
+	"gosheet/internal/services/ui"
+	"gosheet/internal/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// calculationModes are the choices of the calculation mode dropdown, automatic first
+var calculationModes = []string{"Automatic", "Manual"}
+
+// localeNames returns the choices of the locale dropdown and which of them is the current locale
+func localeNames() ([]string, int) {
+	names := make([]string, len(utils.Locales))
+	current := 0
+	for i, l := range utils.Locales {
+		names[i] = l.Name
+		if l.Name == utils.CurrentLocale.Name {
+			current = i
+		}
+	}
+	return names, current
+}
+
+// ShowCalculationDialog lets the user choose between automatic and manual calculation, allow circular
+// references to be calculated iteratively, set when the iteration stops and fix the seed of the random
+// numbers. The workbook is recalculated with the new settings. The locale formulas and numbers are typed
+// in is chosen here as well, for every workbook.
+func ShowCalculationDialog(app *tview.Application, table *tview.Table) {
+	if globalWorkbook == nil {
+		return
+	}
+	settings := globalWorkbook.Calc
+	maxIterations, maxChange := settings.MaxIterations, settings.MaxChange
+	if maxIterations <= 0 {
+		maxIterations = utils.DEFAULT_MAX_ITERATIONS
+	}
+	if maxChange <= 0 {
+		maxChange = utils.DEFAULT_MAX_CHANGE
+	}
+
+	closeDialog := func() {
+		app.SetRoot(table, true).SetFocus(table)
+	}
+
+	mode := 0
+	if settings.Manual {
+		mode = 1
+	}
+	seed := ""
+	if settings.Seed != 0 {
+		seed = strconv.FormatInt(settings.Seed, 10)
+	}
+
+	form := tview.NewForm()
+	layout := tview.NewFlex().SetDirection(tview.FlexRow)
+	form.AddDropDown("Calculation mode:", calculationModes, mode, nil)
+	form.AddCheckbox("Iterative calculation:", settings.Iterative, nil)
+	form.AddInputField("Maximum iterations:", strconv.Itoa(maxIterations), 10, tview.InputFieldInteger, nil)
+	form.AddInputField("Maximum change:", strconv.FormatFloat(maxChange, 'g', -1, 64), 10, tview.InputFieldFloat, nil)
+	form.AddInputField("Random seed:", seed, 20, tview.InputFieldInteger, nil)
+	locales, locale := localeNames()
+	form.AddDropDown("Formula locale:", locales, locale, nil)
+
+	form.AddButton("OK", func() {
+		iterations, err := strconv.Atoi(strings.TrimSpace(form.GetFormItem(2).(*tview.InputField).GetText()))
+		if err != nil || iterations < 1 || iterations > 32767 {
+			ui.ShowWarningModal(app, layout, "Maximum iterations must be a whole number from 1 to 32767!")
+			return
+		}
+		change, err := strconv.ParseFloat(strings.TrimSpace(form.GetFormItem(3).(*tview.InputField).GetText()), 64)
+		if err != nil || change <= 0 {
+			ui.ShowWarningModal(app, layout, "Maximum change must be a number greater than 0!")
+			return
+		}
+		var fixedSeed int64
+		if text := strings.TrimSpace(form.GetFormItem(4).(*tview.InputField).GetText()); text != "" {
+			if fixedSeed, err = strconv.ParseInt(text, 10, 64); err != nil {
+				ui.ShowWarningModal(app, layout, "Random seed must be a whole number, or empty for new numbers on every recalculation!")
+				return
+			}
+		}
+
+		var localeErr error
+		if _, name := form.GetFormItem(5).(*tview.DropDown).GetCurrentOption(); name != utils.CurrentLocale.Name {
+			utils.SetLocale(name)
+			localeErr = fileop.SaveLocaleSetting(name)
+		}
+
+		choice, _ := form.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
+		settings.Manual = choice == 1
+		settings.Iterative = form.GetFormItem(1).(*tview.Checkbox).IsChecked()
+		settings.MaxIterations, settings.MaxChange = iterations, change
+		settings.Seed = fixedSeed
+		if settings != globalWorkbook.Calc {
+			globalWorkbook.Calc = settings
+			RecalculateAllFormulas(table)
+			MarkAsModified(table)
+		}
+		closeDialog()
+		if localeErr != nil {
+			ui.ShowWarningModal(app, table, fmt.Sprintf("The locale could not be kept for the next sessions: %v", localeErr))
+		}
+	})
+	form.AddButton("Cancel", closeDialog)
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			closeDialog()
+			return nil
+		}
+		return event
+	})
+
+	help := tview.NewTextView().SetDynamicColors(true).SetWrap(true).
+		SetText(fmt.Sprintf("In manual mode an edit only calculates the edited cell; [yellow]F9[-] recalculates the workbook and [yellow]Shift+F9[-] the active sheet.\n\n"+
+			"With iterative calculation, formulas in a circular reference are calculated over and over, each time from the values of the last, "+
+			"until no value changes by more than the maximum change or the maximum iterations are reached. Otherwise they show [red]#CIRC![-]. "+
+			"Defaults: %d iterations, a change of %g.\n\n"+
+			"With a random seed, RAND and RANDBETWEEN give the same numbers on every recalculation.\n\n"+
+			"The formula locale is how formulas and numbers are typed: in de-DE and fr-FR arguments are separated by [yellow];[-] "+
+			"and decimals written with a comma, like [yellow]$=ROUND(A1*1,19; 2)[-]. Formulas are saved as [yellow]$=ROUND(A1*1.19, 2)[-] "+
+			"whatever the locale, so a workbook reads the same everywhere.", utils.DEFAULT_MAX_ITERATIONS, utils.DEFAULT_MAX_CHANGE))
+
+	layout.
+		AddItem(form, 15, 0, true).
+		AddItem(help, 0, 1, false)
+	layout.SetBorder(true).SetTitle(" Calculation Options ").SetTitleAlign(tview.AlignCenter)
+
+	app.SetRoot(layout, true).SetFocus(form)
+}
@@ -9,6 +9,7 @@ package table
 
 import (
 	"fmt"
+	"gosheet/internal/services/calc"
 	"gosheet/internal/services/cell"
 	"gosheet/internal/utils"
 
@@ -172,17 +173,27 @@ func copySelection(table *tview.Table) {
 	r1, c1, r2, c2 := getSelectionRange(table)
 
 	clipboard = [][]*cell.Cell{}
+	clipboardRow, clipboardCol = r1, c1
+	clipboardFromCut = false
 
 	for r := r1; r <= r2; r++ {
 		rowSlice := []*cell.Cell{}
 		for c := c1; c <= c2; c++ {
 			key := [2]int{int(r), int(c)}
 			if cellData, exists := activeData[key]; exists {
-				clone := *cellData
+				clone := cellData.Clone()
 				clone.Row = 0
 				clone.Column = 0
 				clone.Dependents = []*string{}
-				rowSlice = append(rowSlice, &clone)
+
+				// A spilled cell is copied as the value it shows
+				if clone.SpilledFrom != nil {
+					value := *clone.Display
+					clone.RawValue = &value
+					clone.SpilledFrom = nil
+					clone.SetFlag(cell.FlagEditable)
+				}
+				rowSlice = append(rowSlice, clone)
 			} else {
 				emptyCell := cell.NewCell(0, 0, "")
 				rowSlice = append(rowSlice, emptyCell)
@@ -263,6 +274,7 @@ func performPaste(table *tview.Table, clipboard [][]*cell.Cell, targetRow, targe
 	c2 := targetCol + int32(len(clipboard[0])) - 1
 	
 	oldCells := captureCellRange(r1, c1, r2, c2)
+	pasted := make([]*cell.Cell, 0, len(clipboard)*len(clipboard[0]))
 	
 	for r, rowSlice := range clipboard {
 		for c, srcCell := range rowSlice {
@@ -273,39 +285,39 @@ func performPaste(table *tview.Table, clipboard [][]*cell.Cell, targetRow, targe
 				continue
 			}
 
-			newCell := *srcCell
+			newCell := srcCell.Clone()
 			newCell.Row = destRow
 			newCell.Column = destCol
-			
+			newCell.Dependents = []*string{}
+
+			key := [2]int{int(destRow), int(destCol)}
+			if oldCell, exists := activeData[key]; exists {
+				globalWorkbook.ClearDependencies(oldCell)
+			}
+			activeData[key] = newCell
+
 			if newCell.IsFormula() {
-				newCell.ClearFlag(cell.FlagEvaluated)
-				newCell.Dependents = []*string{}
-				
-				newCellRef := utils.FormatCellRef(destRow, destCol)
-				
-				for _, depRef := range newCell.DependsOn {
-					depCell, err := GetCellByRef(table, *depRef)
-					if err != nil {
-						continue
-					}
-					
-					if !contains(depCell.Dependents, newCellRef) {
-						depCell.Dependents = append(depCell.Dependents, &newCellRef)
-					}
+				expr := newCell.GetFormulaExpression()
+				if !clipboardFromCut {
+					expr = calc.OffsetFormulaRefs(expr, destRow-clipboardRow-int32(r), destCol-clipboardCol-int32(c))
 				}
-				
-				if err := EvaluateCell(table, &newCell); err != nil {
-					*newCell.Display = "#ERROR!"
-				}
-			}
-			
-			key := [2]int{int(destRow), int(destCol)}
-			activeData[key] = &newCell
-			
-			if activeViewport.IsVisible(destRow, destCol) {
-				visualR, visualC := activeViewport.ToRelative(destRow, destCol)
-				table.SetCell(int(visualR), int(visualC), newCell.ToTViewCell())
+				raw := "$=" + expr
+				newCell.RawValue = &raw
+				newCell.DependsOn = nil
+				newCell.ClearFlag(cell.FlagEvaluated)
 			}
+
+			pasted = append(pasted, newCell)
+		}
+	}
+
+	// One pass over everything the pasted block feeds, instead of one per pasted cell
+	globalWorkbook.Recalculate(pasted...)
+
+	for _, newCell := range pasted {
+		if activeViewport.IsVisible(newCell.Row, newCell.Column) {
+			visualR, visualC := activeViewport.ToRelative(newCell.Row, newCell.Column)
+			table.SetCell(int(visualR), int(visualC), newCell.ToTViewCell())
 		}
 	}
 	
@@ -351,6 +363,7 @@ func cutSelection(app *tview.Application, table *tview.Table) {
 						SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 							if buttonLabel == "Yes" {
 								copySelection(table)
+								clipboardFromCut = true
 								clearCutCells(table, r1, c1, r2, c2)
 							}
 							app.SetRoot(table, true).SetFocus(table)
@@ -364,6 +377,7 @@ func cutSelection(app *tview.Application, table *tview.Table) {
 	}
 
 	copySelection(table)
+	clipboardFromCut = true
 	clearCutCells(table, r1, c1, r2, c2)
 	clearSelectionRange()
 }
@@ -377,33 +391,18 @@ func clearCutCells(table *tview.Table, r1, c1, r2, c2 int32) {
 		return
 	}
 
+	var cleared []*cell.Cell
 	for r := r1; r <= r2; r++ {
 		for c := c1; c <= c2; c++ {
 			key := [2]int{int(r), int(c)}
 			
 			if oldCell, exists := activeData[key]; exists {
-				cellRef := utils.FormatCellRef(int32(r), int32(c))
-				for _, depRef := range oldCell.DependsOn {
-					depCell, err := GetCellByRef(table, *depRef)
-					if err != nil {
-						continue
-					}
-					depCell.Dependents = removeFromSlice(depCell.Dependents, cellRef)
-				}
-				
-				for _, dependentRef := range oldCell.Dependents {
-					dependentCell, err := GetCellByRef(table, *dependentRef)
-					if err != nil {
-						continue
-					}
-					if dependentCell.IsFormula() {
-						RecalculateCell(table, dependentCell)
-					}
-				}
+				globalWorkbook.ClearDependencies(oldCell)
 			}
 			
 			newCell := cell.NewCell(int32(r), int32(c), "")
 			activeData[key] = newCell
+			cleared = append(cleared, newCell)
 			
 			if activeViewport.IsVisible(int32(r), int32(c)) {
 				visualR, visualC := activeViewport.ToRelative(int32(r), int32(c))
@@ -411,6 +410,8 @@ func clearCutCells(table *tview.Table, r1, c1, r2, c2 int32) {
 			}
 		}
 	}
+
+	globalWorkbook.Recalculate(cleared...)
 }
 
 // Delete the selected cells
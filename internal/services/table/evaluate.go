@@ -0,0 +1,160 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// evaluate.go provides the Evaluate Formula dialog, which shows a formula one sub-expression at a time
+
+package table
+
+import (
+	"fmt"
+	"gosheet/internal/services/calc"
+	"gosheet/internal/services/ui"
+	"gosheet/internal/utils"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// ShowEvaluateFormulaDialog shows the expression tree of the selected formula with the value of every
+// sub-expression. It opens on the sub-expression the error of the formula comes from, if there is one.
+func ShowEvaluateFormulaDialog(app *tview.Application, table *tview.Table) {
+	key, ok := selectedKey(table)
+	if !ok {
+		return
+	}
+	ref := utils.FormatCellRef(key.Row, key.Col)
+
+	c, exists := key.Sheet.Data[[2]int{int(key.Row), int(key.Col)}]
+	if !exists || !c.IsFormula() {
+		ui.ShowWarningModal(app, table, fmt.Sprintf("Cell %s holds no formula to evaluate.", ref))
+		return
+	}
+	root, err := globalWorkbook.EvaluateSteps(c)
+	if err != nil {
+		ui.ShowWarningModal(app, table, err.Error())
+		return
+	}
+
+	header := tview.NewTextView().SetDynamicColors(true).SetWrap(true)
+	fmt.Fprintf(header, "[yellow::b]%s[-::-]  %s\n", ref, tview.Escape(utils.CurrentLocale.LocalizeFormula(*c.RawValue)))
+	if root.Failed() {
+		fmt.Fprintf(header, "Result: [red::b]%s[-::-]", tview.Escape(root.Value))
+	} else {
+		fmt.Fprintf(header, "Result: [green::b]%s[-::-]", tview.Escape(root.Value))
+	}
+
+	detail := tview.NewTextView().SetDynamicColors(true).SetWrap(true)
+	detail.SetBorder(true).SetTitle(" Sub-expression ")
+
+	// Only the way to the failing sub-expression starts open; the rest opens a step at a time
+	parents := make(map[*tview.TreeNode]*tview.TreeNode)
+	var culprit *tview.TreeNode
+	var build func(step *calc.EvalStep) *tview.TreeNode
+	build = func(step *calc.EvalStep) *tview.TreeNode {
+		node := tview.NewTreeNode(stepText(step)).SetReference(step).SetExpanded(false)
+		if step.Culprit {
+			culprit = node
+		}
+		for _, sub := range step.Steps {
+			child := build(sub)
+			parents[child] = node
+			node.AddChild(child)
+			if sub.Failed() {
+				node.SetExpanded(node.IsExpanded() || child.IsExpanded() || sub.Culprit)
+			}
+		}
+		return node
+	}
+	rootNode := build(root).SetExpanded(true)
+
+	tree := tview.NewTreeView().SetRoot(rootNode).SetCurrentNode(rootNode)
+	tree.SetBorder(true).SetTitle(" Evaluation ")
+	tree.SetChangedFunc(func(node *tview.TreeNode) {
+		showStepDetail(detail, node.GetReference().(*calc.EvalStep))
+	})
+	tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		node.SetExpanded(!node.IsExpanded())
+	})
+
+	closeDialog := func() {
+		app.SetRoot(table, true).SetFocus(table)
+	}
+	tree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		node := tree.GetCurrentNode()
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closeDialog()
+			return nil
+		case tcell.KeyRight:
+			// Step into the first sub-expression
+			if node != nil && len(node.GetChildren()) > 0 {
+				node.SetExpanded(true)
+				tree.SetCurrentNode(node.GetChildren()[0])
+				showStepDetail(detail, node.GetChildren()[0].GetReference().(*calc.EvalStep))
+			}
+			return nil
+		case tcell.KeyLeft:
+			// Step out to the expression it is part of
+			if parent := parents[node]; parent != nil {
+				tree.SetCurrentNode(parent)
+				showStepDetail(detail, parent.GetReference().(*calc.EvalStep))
+			}
+			return nil
+		}
+		if event.Rune() == 'q' || event.Rune() == 'Q' {
+			closeDialog()
+			return nil
+		}
+		return event
+	})
+
+	if culprit != nil {
+		tree.SetCurrentNode(culprit)
+		showStepDetail(detail, culprit.GetReference().(*calc.EvalStep))
+	} else {
+		showStepDetail(detail, root)
+	}
+
+	help := tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignCenter).
+		SetText("[yellow]→[-] Step in   [yellow]←[-] Step out   [yellow]Enter[-] Expand/Collapse   [yellow]Esc[-] Close")
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(header, 2, 0, false).
+		AddItem(tree, 0, 1, true).
+		AddItem(detail, 6, 0, false).
+		AddItem(help, 1, 0, false)
+	layout.SetBorder(true).SetTitle(" Evaluate Formula ").SetTitleAlign(tview.AlignCenter)
+
+	app.SetRoot(layout, true).SetFocus(tree)
+}
+
+// stepText returns the line of the tree for a sub-expression: its text and value, in red when it failed
+// and marked when the error comes from it
+func stepText(step *calc.EvalStep) string {
+	text := tview.Escape(utils.CurrentLocale.LocalizeFormula(step.Text))
+	value := tview.Escape(step.Value)
+	switch {
+	case step.Culprit:
+		return fmt.Sprintf("[red::b]%s → %s ◀[-::-]", text, value)
+	case step.Failed():
+		return fmt.Sprintf("%s [gray]→[-] [red]%s[-]", text, value)
+	}
+	return fmt.Sprintf("%s [gray]→[-] [green]%s[-]", text, value)
+}
+
+// showStepDetail shows a sub-expression in full, with the reason it failed
+func showStepDetail(detail *tview.TextView, step *calc.EvalStep) {
+	detail.Clear()
+	fmt.Fprintf(detail, "%s\n= %s", tview.Escape(utils.CurrentLocale.LocalizeFormula(step.Text)), tview.Escape(step.Value))
+	if step.Failed() {
+		if reason := step.Err.Error(); reason != step.Value {
+			fmt.Fprintf(detail, "\n[red]%s[-]", tview.Escape(reason))
+		}
+		if step.Culprit {
+			fmt.Fprint(detail, "\n[yellow]The error of the formula comes from here[-]")
+		}
+	}
+	detail.ScrollToBeginning()
+}
@@ -3,188 +3,119 @@
 // This work is licensed under the terms of the MIT license.
 // For a copy, see <https://opensource.org/licenses/MIT>.
 
-// formula.go implements spreadsheet formula evaluation
+// formula.go connects the calc engine to the table view
 
 package table
 
 import (
 	"fmt"
+	"gosheet/internal/services/calc"
 	"gosheet/internal/services/cell"
+	"gosheet/internal/services/ui/cellui"
 	"gosheet/internal/utils"
-	"gosheet/internal/utils/evaluatefuncs"
-	"regexp"
+	"sort"
 	"strings"
 
-	"github.com/expr-lang/expr"
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
-var cellRefRegex = regexp.MustCompile(`\b([A-Z]+)(\d+)\b`)
-var rangeRegex = regexp.MustCompile(`\b([A-Z]+)(\d+):([A-Z]+)(\d+)\b`)
-
-// Token types for formula parsing
-type TokenType int
-
-const (
-	TokenCellRef TokenType = iota
-	TokenStringLiteral
-	TokenOther
-)
-
-type Token struct {
-	Type     TokenType
-	Value    string
-	Original string
+// Evaluates cell and sets the result as the cell display string
+func EvaluateCell(table *tview.Table, c *cell.Cell) error {
+	if globalWorkbook == nil {
+		return fmt.Errorf("no workbook loaded")
+	}
+	return globalWorkbook.EvaluateCell(c)
 }
 
-// Checks recursively for potential circular dependencies such as A1=A2+3 and A2=A1-2
-func hasCircularDependency(table *tview.Table, c *cell.Cell, visited map[string]bool) bool {
-	cellRef := utils.FormatCellRef(c.Row, c.Column)
-	
-	if visited[cellRef] {
-		return true
-	}
-	
-	visited[cellRef] = true
-	
-	for _, depRef := range c.DependsOn {
-		depCell, err := GetCellByRef(table, *depRef)
-		if err != nil {
-			continue
-		}
-		
-		if depCell.IsFormula() {
-			if hasCircularDependency(table, depCell, visited) {
-				return true
-			}
-		}
+// Recalculates a formula and everything depending on it, redrawing the visible cells that changed.
+// In manual mode the title tells the formulas depending on it wait for a recalculation.
+func RecalculateCell(table *tview.Table, c *cell.Cell) error {
+	if globalWorkbook == nil {
+		return fmt.Errorf("no workbook loaded")
 	}
-	
-	delete(visited, cellRef)
-	return false
+	err := globalWorkbook.RecalculateCell(c)
+	updateTableTitle(table)
+	return err
 }
 
-// Evaluates cell using govaluate and sets the result as the cell display string
-func EvaluateCell(table *tview.Table, c *cell.Cell) error {
-	if !c.IsFormula() {
-		return nil
+// Returns the cell based on its address, which may be qualified with a sheet name
+func GetCellByRef(table *tview.Table, ref string) (*cell.Cell, error) {
+	if globalWorkbook == nil {
+		return nil, fmt.Errorf("no workbook loaded")
 	}
+	return globalWorkbook.GetCellByRef(ref)
+}
 
-	if c.RawValue == nil {
-		return fmt.Errorf("cell has nil RawValue")
+// GetFormulaCallbacks returns callbacks for the formula editor of the edit cell dialog
+func GetFormulaCallbacks(app *tview.Application, table *tview.Table) cellui.FormulaCallbacks {
+	return cellui.FormulaCallbacks{
+		GetFunctions: GetUserFunctions,
+		GetNames: func() []string {
+			if globalWorkbook == nil {
+				return nil
+			}
+			var names []string
+			for _, named := range globalWorkbook.Names {
+				if !calc.IsLambda(named.Value) {
+					names = append(names, named.Name)
+				}
+			}
+			return names
+		},
+		GetSheets: func() []string {
+			if globalWorkbook == nil {
+				return nil
+			}
+			sheets := make([]string, 0, len(globalWorkbook.Sheets))
+			for _, sheet := range globalWorkbook.Sheets {
+				sheets = append(sheets, calc.QuoteSheetName(sheet.Name))
+			}
+			return sheets
+		},
+		GetCellRefs: getCellRefs,
+		Preview: func(c *cell.Cell, formula string) (string, error) {
+			if globalWorkbook == nil {
+				return "", fmt.Errorf("no workbook loaded")
+			}
+			return globalWorkbook.PreviewFormula(c, formula)
+		},
+		PointReference: func(c *cell.Cell, formula, start string, key *tcell.EventKey, done func(ref string, next *tcell.EventKey)) {
+			PointReference(app, table, c, formula, start, key, done)
+		},
 	}
+}
 
-	if c.HasFlag(cell.FlagEvaluated) {
+// getCellRefs returns the addresses starting with prefix of the cells holding a value, in row order
+func getCellRefs(sheetName, prefix string) []string {
+	if globalWorkbook == nil || globalWorkbook.GetActiveSheet() == nil {
 		return nil
 	}
-
-	formula := c.GetFormulaExpression()
-	formulaUpper := strings.ToUpper(formula)
-
-	expandedFormula, err := ExpandRangesInFormula(formulaUpper)
-	if err != nil {
-		*c.Display = "#REF!"
-		c.SetFlag(cell.FlagEvaluated)
-		return err
-	}
-
-	if err := checkCircularDependencyForNewFormula(table, c, expandedFormula); err != nil {
-		*c.Display = "#CIRC!"
-		c.SetFlag(cell.FlagEvaluated)
-		return err
-	}
-
-	clearOldDependencies(table, c)
-
-	refs := ParseCellReferences(expandedFormula)
-	c.DependsOn = refs
-
-	cellRef := utils.FormatCellRef(c.Row, c.Column)
-	for _, ref := range refs {
-		depCell, err := GetCellByRef(table, *ref)
-		if err != nil {
-			*c.Display = "#REF!"
-			c.SetFlag(cell.FlagEvaluated)
-			return err
+	sheet := globalWorkbook.GetActiveSheet().Sheet
+	if sheetName != "" {
+		if sheet, _ = globalWorkbook.FindSheet(sheetName); sheet == nil {
+			return nil
 		}
-
-		if !contains(depCell.Dependents, cellRef) {
-			depCell.Dependents = append(depCell.Dependents, &cellRef)
-		}
-	}
-
-	parameters := make(map[string]any)
-	evaluableFormula, err := BuildEvaluableFormula(table, formula, parameters)
-	if err != nil {
-		*c.Display = "#VALUE!"
-		c.SetFlag(cell.FlagEvaluated)
-		return err
 	}
 
-	result, err := evaluateExpression(evaluableFormula, parameters)
-	if err != nil {
-		errMsg := err.Error()
-		if strings.Contains(errMsg, "requires") {
-			*c.Display = "#ARGS!"
-		} else if strings.Contains(errMsg, "division by zero") {
-			*c.Display = "#DIV/0!"
-		} else if strings.Contains(errMsg, "invalid") {
-			*c.Display = "#VALUE!"
-		} else {
-			*c.Display = "#ERROR!"
+	var keys [][2]int
+	for key, c := range sheet.Data {
+		if c.RawValue != nil && strings.TrimSpace(*c.RawValue) != "" && strings.HasPrefix(utils.FormatCellRef(int32(key[0]), int32(key[1])), prefix) {
+			keys = append(keys, key)
 		}
-		c.SetFlag(cell.FlagEvaluated)
-		return err
 	}
-
-	switch v := result.(type) {
-	case float64:
-		if c.Type == nil || *c.Type == "string" {
-			*c.Type = "number"
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
 		}
-		if *c.Type == "financial" {
-			formatted := utils.FormatWithCommas(v, c.ThousandsSeparator, c.DecimalSeparator, c.DecimalPoints, c.FinancialSign)
-			*c.Display = fmt.Sprintf("%c%s", c.FinancialSign, formatted)
-		} else {
-			formatted := utils.FormatWithCommas(v, c.ThousandsSeparator, c.DecimalSeparator, c.DecimalPoints, c.FinancialSign)
-			*c.Display = formatted
-		}
-	case int:
-		if c.Type == nil || *c.Type == "string" {
-			*c.Type = "number"
-		}
-		floatVal := float64(v)
-		if *c.Type == "financial" {
-			formatted := utils.FormatWithCommas(floatVal, c.ThousandsSeparator, c.DecimalSeparator, c.DecimalPoints, c.FinancialSign)
-			*c.Display = fmt.Sprintf("%c%s", c.FinancialSign, formatted)
-		} else {
-			formatted := utils.FormatWithCommas(floatVal, c.ThousandsSeparator, c.DecimalSeparator, c.DecimalPoints, c.FinancialSign)
-			*c.Display = formatted
-		}
-	case string:
-		if res, err := utils.ParseDateTime(v); err == nil {
-			*c.Type = "datetime"
-			*c.Display = utils.FormatDateTime(res, *c.DateTimeFormat)
-		} else {
-			*c.Type = "string"
-			*c.Display = v
-		}
-	case bool:
-		*c.Type = "string"
-		if v {
-			*c.Display = "TRUE"
-		} else {
-			*c.Display = "FALSE"
-		}
-	default:
-		*c.Type = "string"
-		*c.Display = fmt.Sprintf("%v", result)
-	}
-
-	c.SetFlag(cell.FlagEvaluated)
-	c.SetFlag(cell.FlagFormula)
+		return keys[i][1] < keys[j][1]
+	})
 
-	return nil
+	refs := make([]string, len(keys))
+	for i, key := range keys {
+		refs[i] = utils.FormatCellRef(int32(key[0]), int32(key[1]))
+	}
+	return refs
 }
 
 func EvaluateAllFormulasOnLoad(table *tview.Table) error {
@@ -192,109 +123,53 @@ func EvaluateAllFormulasOnLoad(table *tview.Table) error {
 		return fmt.Errorf("no workbook loaded")
 	}
 
-	for _, sheet := range globalWorkbook.Sheets {
-		if sheet == nil {
-			continue
-		}
+	globalWorkbook.EvaluateAll()
+	return nil
+}
 
-		for _, cellData := range sheet.Data {
-			if cellData.IsFormula() {
-				cellData.ClearFlag(cell.FlagEvaluated)
-			}
+// attachView lets the engine redraw cells of the given table when they are recalculated
+func attachView(table *tview.Table) {
+	globalWorkbook.OnCellUpdated = func(sheet *calc.Sheet, c *cell.Cell) {
+		active := globalWorkbook.GetActiveSheet()
+		if active == nil || active.Sheet != sheet {
+			return
 		}
 
-		for _, cellData := range sheet.Data {
-			if cellData.IsFormula() && !cellData.HasFlag(cell.FlagEvaluated) {
-				if err := EvaluateCell(table, cellData); err != nil {
-					continue
-				}
-			}
+		if active.Viewport.IsVisible(c.Row, c.Column) {
+			visualR, visualC := active.Viewport.ToRelative(c.Row, c.Column)
+			table.SetCell(int(visualR), int(visualC), c.ToTViewCell())
 		}
 	}
-
-	return nil
 }
 
-// Uses govaluate to return a result of the formula
-func evaluateExpression(formula string, env map[string]any) (any, error) {
-	functions := evaluatefuncs.GovalFuncs()
-	
-	options := []expr.Option{
-		expr.Env(env),
-		expr.AllowUndefinedVariables(),
-	}
-	
-	for name, fn := range functions {
-		options = append(options, expr.Function(name, fn))
-	}
-
-	program, err := expr.Compile(formula, options...)
-	if err != nil {
-		return nil, fmt.Errorf("compile error: %v", err)
-	}
+// Recalculates every formula of the workbook once, in dependency order, and redraws the active sheet
+func RecalculateAllFormulas(table *tview.Table) error {
+	activeData := GetActiveSheetData()
+	activeViewport := GetActiveViewport()
 
-	result, err := expr.Run(program, env)
-	if err != nil {
-		return nil, fmt.Errorf("runtime error: %v", err)
+	if activeData == nil || activeViewport == nil {
+		return fmt.Errorf("no active sheet")
 	}
 
-	return result, nil
-}
+	globalWorkbook.EvaluateAll()
+	RenderVisible(table, activeViewport, activeData)
+	updateTableTitle(table)
 
-// Recalculates a formula
-func RecalculateCell(table *tview.Table, c *cell.Cell) error {
-	c.ClearFlag(cell.FlagEvaluated)
-	
-	if c.IsFormula() {
-		if err := EvaluateCell(table, c); err != nil {
-			return err
-		}
-		table.SetCell(int(c.Row), int(c.Column), c.ToTViewCell())
-	}
-	
-	for _, depRef := range c.Dependents {
-		depCell, err := GetCellByRef(table, *depRef)
-		if err != nil {
-			continue
-		}
-		
-		if depCell.IsFormula() {
-			if err := RecalculateCell(table, depCell); err != nil {
-				continue
-			}
-		}
-	}
-	
 	return nil
 }
 
-// Same as the previous, but for a series of cell.
-func RecalculateAllFormulas(table *tview.Table) error {
+// Recalculates every formula of the active sheet, reading the other sheets as they are, and redraws it
+func RecalculateActiveSheet(table *tview.Table) error {
 	activeData := GetActiveSheetData()
 	activeViewport := GetActiveViewport()
-	
+
 	if activeData == nil || activeViewport == nil {
 		return fmt.Errorf("no active sheet")
 	}
 
-    for _, cellData := range activeData {
-        if cellData.IsFormula() {
-            cellData.ClearFlag(cell.FlagEvaluated)
-        }
-    }
-    
-    for _, cellData := range activeData {
-        if cellData.IsFormula() && !cellData.HasFlag(cell.FlagEvaluated) {
-            if err := EvaluateCell(table, cellData); err != nil {
-                continue
-            }
-            
-            if activeViewport.IsVisible(cellData.Row, cellData.Column) {
-                visualR, visualC := activeViewport.ToRelative(cellData.Row, cellData.Column)
-                table.SetCell(int(visualR), int(visualC), cellData.ToTViewCell())
-            }
-        }
-    }
-    
-    return nil
+	globalWorkbook.RecalculateSheet(globalWorkbook.GetActiveSheet().Sheet)
+	RenderVisible(table, activeViewport, activeData)
+	updateTableTitle(table)
+
+	return nil
 }
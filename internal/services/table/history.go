@@ -9,7 +9,6 @@ package table
 
 import (
 	"gosheet/internal/services/cell"
-	"gosheet/internal/utils"
 	"github.com/rivo/tview"
 )
 
@@ -114,38 +113,20 @@ func restoreCell(table *tview.Table, row, col int32, cellData *cell.Cell) {
 	cellData.Row = row
 	cellData.Column = col
 
-	if cellData.IsFormula() {
-		newCellRef := utils.FormatCellRef(row, col)
-
-		clearOldDependencies(table, cellData)
-
-		for _, depRef := range cellData.DependsOn {
-			depCell, err := GetCellByRef(table, *depRef)
-			if err != nil {
-				continue
-			}
-
-			if !contains(depCell.Dependents, newCellRef) {
-				depCell.Dependents = append(depCell.Dependents, &newCellRef)
-			}
-		}
-
-		cellData.ClearFlag(cell.FlagEvaluated)
-
-		if err := EvaluateCell(table, cellData); err != nil {
-			*cellData.Display = "#ERROR!"
-		}
-	}
-
 	key := [2]int{int(row), int(col)}
+	if oldCell, exists := activeData[key]; exists {
+		globalWorkbook.ClearDependencies(oldCell)
+	}
 	activeData[key] = cellData
 
+	// Recalculating the restored cell also relinks it and updates whatever reads its position
+	cellData.ClearFlag(cell.FlagEvaluated)
+	RecalculateCell(table, cellData)
+
 	if activeViewport.IsVisible(row, col) {
 		visualR, visualC := activeViewport.ToRelative(row, col)
 		table.SetCell(int(visualR), int(visualC), cellData.ToTViewCell())
 	}
-
-	RecalculateCell(table, cellData)
 }
 
 // clearCell removes a cell from data map
@@ -160,28 +141,12 @@ func clearCell(table *tview.Table, row, col int32) {
 	key := [2]int{int(row), int(col)}
 
 	if oldCell, exists := activeData[key]; exists {
-		cellRef := utils.FormatCellRef(row, col)
-		for _, depRef := range oldCell.DependsOn {
-			depCell, err := GetCellByRef(table, *depRef)
-			if err != nil {
-				continue
-			}
-			depCell.Dependents = removeFromSlice(depCell.Dependents, cellRef)
-		}
-
-		for _, dependentRef := range oldCell.Dependents {
-			dependentCell, err := GetCellByRef(table, *dependentRef)
-			if err != nil {
-				continue
-			}
-			if dependentCell.IsFormula() {
-				RecalculateCell(table, dependentCell)
-			}
-		}
+		globalWorkbook.ClearDependencies(oldCell)
 	}
 
 	newCell := cell.NewCell(row, col, "")
 	activeData[key] = newCell
+	RecalculateCell(table, newCell)
 
 	if activeViewport.IsVisible(row, col) {
 		visualR, visualC := activeViewport.ToRelative(row, col)
@@ -78,6 +78,8 @@ func deleteCol(app *tview.Application, table *tview.Table, col int32) {
         		    delete(activeData, key)
         		}
 				maps.Copy(activeData, keysToUpdate)
+
+				globalWorkbook.ShiftReferences(globalWorkbook.GetActiveSheet().Sheet, false, col, -1)
 				
 				RenderVisible(table, activeViewport, activeData)
 				app.SetRoot(table, true).SetFocus(table)
@@ -122,6 +124,8 @@ func deleteRow(app *tview.Application, table *tview.Table, row int32) {
         		}
         		maps.Copy(activeData, keysToUpdate)	
 
+				globalWorkbook.ShiftReferences(globalWorkbook.GetActiveSheet().Sheet, true, row, -1)
+
 				RenderVisible(table, activeViewport, activeData)
 				app.SetRoot(table, true).SetFocus(table)
 			} else {
@@ -184,6 +188,8 @@ func insertCol(app *tview.Application, table *tview.Table, col int32) {
         		}
         		maps.Copy(activeData, keysToUpdate)	
 
+				globalWorkbook.ShiftReferences(globalWorkbook.GetActiveSheet().Sheet, false, col, 1)
+
 				RenderVisible(table, activeViewport, activeData)
 				app.SetRoot(table, true).SetFocus(table)
 			} else {
@@ -224,6 +230,8 @@ func insertRow(app *tview.Application, table *tview.Table, row int32) {
         		    delete(activeData, key)
         		}
         		maps.Copy(activeData, keysToUpdate)	
+
+				globalWorkbook.ShiftReferences(globalWorkbook.GetActiveSheet().Sheet, true, row, 1)
 				
 				RenderVisible(table, activeViewport, activeData)
 				app.SetRoot(table, true).SetFocus(table)
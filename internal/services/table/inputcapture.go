@@ -20,6 +20,10 @@ import (
 
 var clipboard = [][]*cell.Cell{}
 
+// Top-left cell the clipboard was taken from; cut cells keep their references when pasted
+var clipboardRow, clipboardCol int32
+var clipboardFromCut bool
+
 // Table input capture function. Manages everything from cell selection, to key combinations and calls other services.
 func InputCaptureService(app *tview.Application, table *tview.Table, vp *utils.Viewport, data map[[2]int]*cell.Cell) *tview.Table {
 	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
@@ -27,6 +31,18 @@ func InputCaptureService(app *tview.Application, table *tview.Table, vp *utils.V
 			return event
 		}
 
+		// While a reference is picked for a formula, the keys move the marker
+		if pointing != nil {
+			return pointing.handle(table, event)
+		}
+
+		// In audit mode the grid walks the formula chain; other commands leave audit mode first
+		if auditing != nil {
+			if event = auditing.handle(table, event); event == nil {
+				return nil
+			}
+		}
+
 		if event.Key() == tcell.KeyCtrlC {
 		    modal := tview.NewModal().
 		        SetText("Ctrl+C detected. Exiting...\nUnsaved edits will be lost.").
@@ -97,6 +113,11 @@ func InputCaptureService(app *tview.Application, table *tview.Table, vp *utils.V
 		}
 
 		switch {
+		// Shift + F9 → Recalculate the active sheet; some terminals send it as F21
+		case event.Key() == tcell.KeyF9 && event.Modifiers()&tcell.ModShift != 0, event.Key() == tcell.KeyF21:
+			RecalculateActiveSheet(table)
+			return nil
+
 		// Shift + Arrow Keys - Selection
 		case event.Modifiers()&tcell.ModShift != 0:
 			absRow, absCol := activeViewport.ToAbsolute(visualRow, visualCol)
@@ -106,62 +127,8 @@ func InputCaptureService(app *tview.Application, table *tview.Table, vp *utils.V
 				anchorRow, anchorCol = absRow, absCol
 			}
 
-			switch event.Key() {
-			case tcell.KeyRight:
-				if absCol >= utils.MAX_COLS {
-					return nil
-				}
-				if visualCol == int32(activeViewport.ViewCols) {
-					activeViewport.LeftCol++
-					RenderVisible(table, activeViewport, activeData)
-					absCol++
-					table.Select(int(visualRow), int(activeViewport.ViewCols))
-				} else if visualCol < int32(table.GetColumnCount()-1) {
-					visualCol++
-					absRow, absCol = activeViewport.ToAbsolute(visualRow, visualCol)
-					table.Select(int(visualRow), int(visualCol))
-				}
-
-			case tcell.KeyLeft:
-				if visualCol == 1 && activeViewport.LeftCol > 1 {
-					activeViewport.LeftCol--
-					RenderVisible(table, activeViewport, activeData)
-					absCol--
-					table.Select(int(visualRow), 1)
-				} else if visualCol > 0 {
-					visualCol--
-					absRow, absCol = activeViewport.ToAbsolute(visualRow, visualCol)
-					table.Select(int(visualRow), int(visualCol))
-				}
-
-			case tcell.KeyDown:
-				if absRow >= utils.MAX_ROWS {
-					return nil
-				}
-				if visualRow == int32(activeViewport.ViewRows) {
-					activeViewport.TopRow++
-					RenderVisible(table, activeViewport, activeData)
-					absRow++
-					table.Select(int(activeViewport.ViewRows), int(visualCol))
-				} else if visualRow < int32(table.GetRowCount()-1) {
-					visualRow++
-					absRow, absCol = activeViewport.ToAbsolute(visualRow, visualCol)
-					table.Select(int(visualRow), int(visualCol))
-				}
-
-			case tcell.KeyUp:
-				if visualRow == 1 && activeViewport.TopRow > 1 {
-					activeViewport.TopRow--
-					RenderVisible(table, activeViewport, activeData)
-					absRow--
-					table.Select(1, int(visualCol))
-				} else if visualRow > 0 {
-					visualRow--
-					absRow, absCol = activeViewport.ToAbsolute(visualRow, visualCol)
-					table.Select(int(visualRow), int(visualCol))
-				}
-
-			default:
+			var moved bool
+			if absRow, absCol, moved = stepSelection(table, activeViewport, activeData, event.Key()); !moved {
 				return event
 			}
 
@@ -199,6 +166,11 @@ func InputCaptureService(app *tview.Application, table *tview.Table, vp *utils.V
 		case event.Key() == tcell.KeyF3:
 			navigation.FindPreviousQuick(table, activeData, activeViewport, RenderVisible)
 			return nil
+
+		// F9 → Recalculate the workbook
+		case event.Key() == tcell.KeyF9:
+			RecalculateAllFormulas(table)
+			return nil
 			
 		// ALT + C → Copy
 		case (event.Rune() == 'c' || event.Rune() == 'C') && event.Modifiers()&tcell.ModAlt != 0:
@@ -274,6 +246,11 @@ func InputCaptureService(app *tview.Application, table *tview.Table, vp *utils.V
 			ShowSheetManagerDialog(app, table)
 			return nil
 
+		// Alt + L - Open Name Manager
+		case (event.Rune() == 'l' || event.Rune() == 'L') && event.Modifiers()&tcell.ModAlt != 0:
+			ShowNameManagerDialog(app, table)
+			return nil
+
 		// Alt + T - Open Sheet Context Menu
 		case (event.Rune() == 't' || event.Rune() == 'T') && event.Modifiers()&tcell.ModAlt != 0:
 			ShowSheetContextMenu(app, table)
@@ -301,9 +278,24 @@ func InputCaptureService(app *tview.Application, table *tview.Table, vp *utils.V
     		}
     		return nil
 
+		// Alt + U → Audit precedents and dependents
+		case (event.Rune() == 'u' || event.Rune() == 'U') && event.Modifiers()&tcell.ModAlt != 0:
+			ToggleAuditMode(app, table)
+			return nil
+
+		// Alt + E → Evaluate the formula step by step
+		case (event.Rune() == 'e' || event.Rune() == 'E') && event.Modifiers()&tcell.ModAlt != 0:
+			ShowEvaluateFormulaDialog(app, table)
+			return nil
+
+		// Alt + K → Calculation options
+		case (event.Rune() == 'k' || event.Rune() == 'K') && event.Modifiers()&tcell.ModAlt != 0:
+			ShowCalculationDialog(app, table)
+			return nil
+
 		// Alt + / → Show Help modal
 		case event.Rune() == '/' && event.Modifiers()&tcell.ModAlt != 0:
-			ui.ShowHelpModal(app, table)
+			ui.ShowHelpModal(app, table, GetUserFunctions())
 			return nil
 
 		// ESCAPE / Alt + S - Show Save Dialog
@@ -320,3 +312,72 @@ func InputCaptureService(app *tview.Application, table *tview.Table, vp *utils.V
 	
 	return table
 }	
+
+// stepSelection moves the selected cell one step in the direction of key, scrolling the viewport by one
+// row or column when it is at the edge. It returns the absolute position of the selected cell, and false
+// when key is not an arrow.
+func stepSelection(table *tview.Table, vp *utils.Viewport, data map[[2]int]*cell.Cell, key tcell.Key) (int32, int32, bool) {
+	visualRow, visualCol := utils.ConvertToInt32(table.GetSelection())
+	absRow, absCol := vp.ToAbsolute(visualRow, visualCol)
+
+	switch key {
+	case tcell.KeyRight:
+		if absCol >= utils.MAX_COLS {
+			return absRow, absCol, true
+		}
+		if visualCol == int32(vp.ViewCols) {
+			vp.LeftCol++
+			RenderVisible(table, vp, data)
+			absCol++
+			table.Select(int(visualRow), int(vp.ViewCols))
+		} else if visualCol < int32(table.GetColumnCount()-1) {
+			visualCol++
+			absRow, absCol = vp.ToAbsolute(visualRow, visualCol)
+			table.Select(int(visualRow), int(visualCol))
+		}
+
+	case tcell.KeyLeft:
+		if visualCol == 1 && vp.LeftCol > 1 {
+			vp.LeftCol--
+			RenderVisible(table, vp, data)
+			absCol--
+			table.Select(int(visualRow), 1)
+		} else if visualCol > 0 {
+			visualCol--
+			absRow, absCol = vp.ToAbsolute(visualRow, visualCol)
+			table.Select(int(visualRow), int(visualCol))
+		}
+
+	case tcell.KeyDown:
+		if absRow >= utils.MAX_ROWS {
+			return absRow, absCol, true
+		}
+		if visualRow == int32(vp.ViewRows) {
+			vp.TopRow++
+			RenderVisible(table, vp, data)
+			absRow++
+			table.Select(int(vp.ViewRows), int(visualCol))
+		} else if visualRow < int32(table.GetRowCount()-1) {
+			visualRow++
+			absRow, absCol = vp.ToAbsolute(visualRow, visualCol)
+			table.Select(int(visualRow), int(visualCol))
+		}
+
+	case tcell.KeyUp:
+		if visualRow == 1 && vp.TopRow > 1 {
+			vp.TopRow--
+			RenderVisible(table, vp, data)
+			absRow--
+			table.Select(1, int(visualCol))
+		} else if visualRow > 0 {
+			visualRow--
+			absRow, absCol = vp.ToAbsolute(visualRow, visualCol)
+			table.Select(int(visualRow), int(visualCol))
+		}
+
+	default:
+		return absRow, absCol, false
+	}
+
+	return absRow, absCol, true
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// names.go connects the name manager dialog to the workbook's named ranges and constants
+
+package table
+
+import (
+	"fmt"
+	"gosheet/internal/services/calc"
+	"gosheet/internal/services/ui/sheetmanager"
+	"gosheet/internal/utils"
+	"gosheet/internal/utils/evaluatefuncs"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// GetNames returns the defined names for the name manager
+func GetNames() []sheetmanager.NameInfo {
+	if globalWorkbook == nil {
+		return nil
+	}
+
+	names := make([]sheetmanager.NameInfo, 0, len(globalWorkbook.Names))
+	for _, named := range globalWorkbook.Names {
+		preview := "#NAME?"
+		if calc.IsLambda(named.Value) {
+			preview = "function"
+		} else if val, ok := globalWorkbook.ResolveName(named.Name); ok {
+			preview = fmt.Sprintf("%v", val)
+		} else if strings.Contains(named.Value, ":") {
+			preview = "range"
+		}
+
+		names = append(names, sheetmanager.NameInfo{
+			Name:    named.Name,
+			Value:   named.Value,
+			Comment: named.Comment,
+			Preview: preview,
+		})
+	}
+
+	return names
+}
+
+// GetUserFunctions describes the LAMBDA functions saved in the workbook for the function browser
+func GetUserFunctions() []evaluatefuncs.FunctionInfo {
+	if globalWorkbook == nil {
+		return nil
+	}
+
+	var functions []evaluatefuncs.FunctionInfo
+	for _, fn := range globalWorkbook.UserFunctions() {
+		functions = append(functions, fn.Info())
+	}
+	return functions
+}
+
+// GetNameManagerCallbacks returns callbacks for the name manager dialog
+func GetNameManagerCallbacks(table *tview.Table) sheetmanager.NameManagerCallbacks {
+	return sheetmanager.NameManagerCallbacks{
+		GetNames: GetNames,
+		DefineName: func(name, value, comment string) error {
+			if globalWorkbook == nil {
+				return fmt.Errorf("no workbook loaded")
+			}
+			return globalWorkbook.DefineName(name, value, comment)
+		},
+		DeleteName: func(name string) error {
+			if globalWorkbook == nil {
+				return fmt.Errorf("no workbook loaded")
+			}
+			return globalWorkbook.DeleteName(name)
+		},
+		GetSelectionRef: func() string {
+			r1, c1, r2, c2 := getSelectionRange(table)
+			ref := calc.QualifyRef(globalWorkbook.GetActiveSheet().Name, utils.FormatCellRef(r1, c1))
+			if r1 != r2 || c1 != c2 {
+				ref += ":" + utils.FormatCellRef(r2, c2)
+			}
+			return calc.AbsoluteRef(ref)
+		},
+		MarkAsModified:    func() { MarkAsModifiedView(table) },
+		RenderActiveSheet: func() { RenderActiveSheetView(table) },
+	}
+}
+
+// ShowNameManagerDialog shows the name manager dialog
+func ShowNameManagerDialog(app *tview.Application, table *tview.Table) {
+	sheetmanager.ShowNameManager(app, table, GetNameManagerCallbacks(table))
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// pointmode.go lets the references of a formula be picked on the grid with the arrow keys while it is edited
+
+package table
+
+import (
+	"fmt"
+	"gosheet/internal/services/cell"
+	"gosheet/internal/utils"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// pointState is the reference being picked on the grid, from the anchor to the selected cell
+type pointState struct {
+	formula         string
+	anchorRow       int32
+	anchorCol       int32
+	row, col        int32
+	topRow, leftCol int32
+	selRow, selCol  int
+	done            func(ref string, next *tcell.EventKey)
+}
+
+// pointing is the point mode in progress, nil while the grid is used as usual
+var pointing *pointState
+
+// PointReference shows the grid with a marker on the reference start, or on c when start is empty, and
+// moves it by the arrow key which started point mode. Arrows move the marker and shift+arrows extend it.
+// Enter hands the reference to done; a typed character hands it over along with the key, to be typed
+// after it. Esc gives up, handing over an empty reference.
+func PointReference(app *tview.Application, table *tview.Table, c *cell.Cell, formula, start string, key *tcell.EventKey, done func(ref string, next *tcell.EventKey)) {
+	activeData := GetActiveSheetData()
+	activeViewport := GetActiveViewport()
+	if activeData == nil || activeViewport == nil {
+		done("", nil)
+		return
+	}
+
+	selRow, selCol := table.GetSelection()
+	pointing = &pointState{
+		formula: formula,
+		topRow:  activeViewport.TopRow,
+		leftCol: activeViewport.LeftCol,
+		selRow:  selRow,
+		selCol:  selCol,
+		done:    done,
+	}
+
+	// A reference picked before is picked again from where it was
+	if start == "" {
+		pointing.anchorRow, pointing.anchorCol = c.Row, c.Column
+		pointing.row, pointing.col = c.Row, c.Column
+	} else {
+		first, last, isRange := strings.Cut(start, ":")
+		if !isRange {
+			last = first
+		}
+		pointing.anchorRow, pointing.anchorCol = utils.ParseCellRef(first)
+		pointing.row, pointing.col = utils.ParseCellRef(last)
+	}
+
+	// Show the marker where it starts, like go to cell does for a cell out of view
+	if !activeViewport.IsVisible(pointing.row, pointing.col) {
+		activeViewport.TopRow, activeViewport.LeftCol = pointing.row, pointing.col
+		RenderVisible(table, activeViewport, activeData)
+	}
+	visualRow, visualCol := activeViewport.ToRelative(pointing.row, pointing.col)
+	table.Select(int(visualRow), int(visualCol))
+
+	app.SetRoot(table, true).SetFocus(table)
+	pointing.handle(table, key)
+}
+
+// handle applies a key pressed in point mode; every key is taken, so the grid does nothing else meanwhile
+func (p *pointState) handle(table *tview.Table, event *tcell.EventKey) *tcell.EventKey {
+	activeData := GetActiveSheetData()
+	activeViewport := GetActiveViewport()
+	if activeData == nil || activeViewport == nil {
+		p.finish(table, "", nil)
+		return nil
+	}
+
+	switch event.Key() {
+	case tcell.KeyEscape:
+		p.finish(table, "", nil)
+		return nil
+	case tcell.KeyEnter, tcell.KeyTab:
+		p.finish(table, p.ref(), nil)
+		return nil
+	case tcell.KeyRune:
+		if event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) == 0 {
+			p.finish(table, p.ref(), event)
+		}
+		return nil
+	case tcell.KeyUp, tcell.KeyDown, tcell.KeyLeft, tcell.KeyRight:
+	default:
+		return nil
+	}
+
+	// The marker stays off the row and column headers
+	if (event.Key() == tcell.KeyUp && p.row <= 1) || (event.Key() == tcell.KeyLeft && p.col <= 1) {
+		return nil
+	}
+	p.row, p.col, _ = stepSelection(table, activeViewport, activeData, event.Key())
+	if event.Modifiers()&tcell.ModShift == 0 {
+		p.anchorRow, p.anchorCol = p.row, p.col
+	}
+
+	highlightRange(table, p.anchorRow, p.anchorCol, p.row, p.col)
+	table.SetTitle(fmt.Sprintf(" Point: %s[yellow::b]%s[-::-]  Enter inserts · Esc cancels ", tview.Escape(p.tail()), p.ref()))
+	return nil
+}
+
+// ref returns the picked cell or range, like A1 or A1:C9
+func (p *pointState) ref() string {
+	if p.anchorRow == p.row && p.anchorCol == p.col {
+		return utils.FormatCellRef(p.row, p.col)
+	}
+	r1, r2 := utils.MinMax(p.anchorRow, p.row)
+	c1, c2 := utils.MinMax(p.anchorCol, p.col)
+	return utils.FormatCellRef(r1, c1) + ":" + utils.FormatCellRef(r2, c2)
+}
+
+// tail returns the end of the formula the reference goes after, short enough for the title
+func (p *pointState) tail() string {
+	const width = 40
+	if len(p.formula) <= width {
+		return p.formula
+	}
+	return "…" + p.formula[len(p.formula)-width:]
+}
+
+// finish leaves point mode, putting the grid back as it was before, and hands over the reference
+func (p *pointState) finish(table *tview.Table, ref string, next *tcell.EventKey) {
+	pointing = nil
+	clearSelectionRange()
+
+	if activeViewport := GetActiveViewport(); activeViewport != nil {
+		activeViewport.TopRow, activeViewport.LeftCol = p.topRow, p.leftCol
+		RenderVisible(table, activeViewport, GetActiveSheetData())
+	}
+	table.Select(p.selRow, p.selCol)
+	updateTableTitle(table)
+
+	p.done(ref, next)
+}
@@ -123,6 +123,11 @@ func SelectInTable(app *tview.Application, table *tview.Table, vp *utils.Viewpor
 				}
 			}
 		}
+
+		// Audit mode follows the selected cell
+		if auditing != nil {
+			auditing.refresh(table)
+		}
 	})
 
 	table.SetSelectedFunc(func(row, col int) {
@@ -155,12 +160,17 @@ func SelectInTable(app *tview.Application, table *tview.Table, vp *utils.Viewpor
 			activeData[key] = c
 		}
 
+		if c.SpilledFrom != nil {
+			cellui.ShowSpilledCellModal(app, table, absRow, absCol, *c.SpilledFrom)
+			return
+		}
+
 		if !c.HasFlag(cell.FlagEditable) {
-			cellui.ShowUneditableModal(app, table, absRow, absCol, RecordCellEdit, EvaluateCell, RecalculateCell, activeData, activeViewport)
+			cellui.ShowUneditableModal(app, table, absRow, absCol, RecordCellEdit, EvaluateCell, RecalculateCell, activeData, activeViewport, GetFormulaCallbacks(app, table))
 			return
 		}
 
-		cellui.EditCellDialog(app, table, absRow, absCol, RecordCellEdit, EvaluateCell, RecalculateCell, activeData, activeViewport)
+		cellui.EditCellDialog(app, table, absRow, absCol, RecordCellEdit, EvaluateCell, RecalculateCell, activeData, activeViewport, GetFormulaCallbacks(app, table))
 	})
 
 	table = InputCaptureService(app, table, vp, data)
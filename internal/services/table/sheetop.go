@@ -141,8 +141,12 @@ func DuplicateSheetByIndex(index int) error {
 	newSheet.Viewport.ViewCols = sourceSheet.Viewport.ViewCols
 
 	globalWorkbook.Sheets = append(globalWorkbook.Sheets, newSheet)
+	globalWorkbook.syncSheets()
 	globalWorkbook.HasChanges = true
 
+	// Link the copied formulas to the cells they reference, including those on other sheets
+	globalWorkbook.RebuildDependencies()
+
 	return nil
 }
 
@@ -171,6 +175,7 @@ func MoveSheetByIndex(fromIndex, toIndex int) error {
 	newSheets = append(newSheets, sheet)
 	newSheets = append(newSheets, globalWorkbook.Sheets[toIndex:]...)
 	globalWorkbook.Sheets = newSheets
+	globalWorkbook.syncSheets()
 
 	if globalWorkbook.ActiveSheet == fromIndex {
 		globalWorkbook.ActiveSheet = toIndex
@@ -11,8 +11,10 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"gosheet/internal/services/calc"
 	"gosheet/internal/services/cell"
 	"gosheet/internal/services/fileop"
+	"gosheet/internal/services/ui/datavalidation"
 	"gosheet/internal/utils"
 
 	"github.com/gdamore/tcell/v2"
@@ -65,6 +67,18 @@ func init() {
 		hasWorkbook = globalWorkbook != nil && len(globalWorkbook.Sheets) > 0
 		return
 	}
+	fileop.GetWorkbookMetaFunc = func() fileop.WorkbookMeta {
+		if globalWorkbook == nil {
+			return fileop.WorkbookMeta{}
+		}
+		return fileop.WorkbookMeta{Names: globalWorkbook.Names, Calc: globalWorkbook.Calc}
+	}
+	datavalidation.ResolveNameFunc = func(name string) (any, bool) {
+		if globalWorkbook == nil {
+			return nil, false
+		}
+		return globalWorkbook.ResolveName(name)
+	}
 }
 
 // Creates an empty tview table
@@ -90,10 +104,13 @@ func OpenTable(app *tview.Application, filename string) (*tview.Table, error) {
 	}
 
 	globalWorkbook = &Workbook{
-		Sheets:      make([]*Sheet, 0),
-		ActiveSheet: 0,
-		CurrentFile: filename,
-		HasChanges:  false,
+		Workbook: &calc.Workbook{
+			Names:       workbookResult.Meta.Names,
+			Calc:        workbookResult.Meta.Calc,
+			CurrentFile: filename,
+		},
+		Sheets:     make([]*Sheet, 0),
+		HasChanges: false,
 	}
 
 	for _, sheetResult := range workbookResult.Sheets {
@@ -110,6 +127,7 @@ func OpenTable(app *tview.Application, filename string) (*tview.Table, error) {
 	if len(globalWorkbook.Sheets) == 0 {
 		globalWorkbook.Sheets = append(globalWorkbook.Sheets, NewSheet("Sheet1"))
 	}
+	globalWorkbook.syncSheets()
 
 	if workbookResult.ActiveSheet >= len(globalWorkbook.Sheets) {
 		globalWorkbook.ActiveSheet = 0
@@ -120,7 +138,8 @@ func OpenTable(app *tview.Application, filename string) (*tview.Table, error) {
 	sheet := globalWorkbook.GetActiveSheet()
 
 	table := CreateTable(filename)
-	
+	attachView(table)
+
 	EvaluateAllFormulasOnLoad(table)
 
 	RenderVisible(table, sheet.Viewport, sheet.Data)
@@ -136,6 +155,7 @@ func NewTable(app *tview.Application) *tview.Table {
 	globalWorkbook.HasChanges = false
 
 	table := CreateTable("Untitled")
+	attachView(table)
 
 	sheet := globalWorkbook.GetActiveSheet()
 	RenderVisible(table, sheet.Viewport, sheet.Data)
@@ -169,6 +189,10 @@ func isEmptyCell(c *cell.Cell) bool {
 	if c.RawValue != nil && *c.RawValue != "" {
 		return false
 	}
+	// A spilled cell is only blank in its raw value, the formula it belongs to keeps its display
+	if c.SpilledFrom != nil {
+		return false
+	}
 	if c.IsFormula() {
 		return false
 	}
@@ -283,6 +307,10 @@ func updateTableTitle(table *tview.Table) {
 	if globalWorkbook.HasChanges {
 		title += "● "
 	}
+	// A manual workbook changed since its last recalculation shows results which may be out of date
+	if globalWorkbook.Stale() {
+		title += "[yellow]Calculate (F9)[-] "
+	}
 
 	table.SetTitle(title)
 }
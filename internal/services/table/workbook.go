@@ -9,39 +9,41 @@ package table
 
 import (
 	"fmt"
+	"gosheet/internal/services/calc"
 	"gosheet/internal/services/cell"
 	"gosheet/internal/utils"
 )
 
+// Sheet is a calc sheet together with the state the UI keeps for it
 type Sheet struct {
-	Name     string
-	Data     map[[2]int]*cell.Cell
+	*calc.Sheet
 	Viewport *utils.Viewport
 	History  *History
 }
 
+// Workbook wraps the calc workbook; Sheets shadows the engine's list and syncSheets keeps both in step
 type Workbook struct {
-	Sheets      []*Sheet
-	ActiveSheet int
-	CurrentFile string
-	HasChanges  bool
+	*calc.Workbook
+	Sheets     []*Sheet
+	HasChanges bool
 }
 
 func NewWorkbook() *Workbook {
-	return &Workbook{
+	wb := &Workbook{
+		Workbook: &calc.Workbook{},
 		Sheets: []*Sheet{
 			NewSheet("Sheet1"),
 		},
-		ActiveSheet: 0,
-		HasChanges:  false,
+		HasChanges: false,
 	}
+	wb.syncSheets()
+	return wb
 }
 
 // NewSheet creates a new sheet
 func NewSheet(name string) *Sheet {
 	return &Sheet{
-		Name: name,
-		Data: make(map[[2]int]*cell.Cell),
+		Sheet: calc.NewSheet(name),
 		Viewport: &utils.Viewport{
 			TopRow:   1,
 			LeftCol:  1,
@@ -56,6 +58,14 @@ func NewSheet(name string) *Sheet {
 	}
 }
 
+// syncSheets hands the engine the current list of sheets after one was added, removed or moved
+func (wb *Workbook) syncSheets() {
+	wb.Workbook.Sheets = make([]*calc.Sheet, len(wb.Sheets))
+	for i, sheet := range wb.Sheets {
+		wb.Workbook.Sheets[i] = sheet.Sheet
+	}
+}
+
 // GetActiveSheet returns the currently active sheet
 func (wb *Workbook) GetActiveSheet() *Sheet {
 	if wb.ActiveSheet >= 0 && wb.ActiveSheet < len(wb.Sheets) {
@@ -103,6 +113,7 @@ func GetActiveHistory() *History {
 // AddSheet adds a new sheet to the workbook
 func (wb *Workbook) AddSheet(name string) {
 	wb.Sheets = append(wb.Sheets, NewSheet(name))
+	wb.syncSheets()
 	wb.HasChanges = true
 }
 
@@ -115,13 +126,18 @@ func (wb *Workbook) DeleteSheet(index int) error {
 		return fmt.Errorf("invalid sheet index")
 	}
 
+	deletedName := wb.Sheets[index].Name
 	wb.Sheets = append(wb.Sheets[:index], wb.Sheets[index+1:]...)
+	wb.syncSheets()
 
 	// Adjust active sheet if necessary
 	if wb.ActiveSheet >= len(wb.Sheets) {
 		wb.ActiveSheet = len(wb.Sheets) - 1
 	}
 
+	// Formulas pointing at the deleted sheet become #REF!
+	wb.InvalidateSheetReferences(deletedName)
+
 	wb.HasChanges = true
 	return nil
 }
@@ -131,7 +147,14 @@ func (wb *Workbook) RenameSheet(index int, newName string) error {
 	if index < 0 || index >= len(wb.Sheets) {
 		return fmt.Errorf("invalid sheet index")
 	}
+	oldName := wb.Sheets[index].Name
 	wb.Sheets[index].Name = newName
+
+	// Keep cross-sheet formulas pointing at the renamed sheet
+	if oldName != newName {
+		wb.RenameSheetReferences(oldName, newName)
+	}
+
 	wb.HasChanges = true
 	return nil
 }
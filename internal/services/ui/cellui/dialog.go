@@ -20,7 +20,7 @@ import (
 )
 
 // Core Functionality
-func EditCellDialog(app *tview.Application, table *tview.Table, row, column int32, RecordCellEdit func(table *tview.Table, row, col int32, oldCell, newCell *cell.Cell), EvaluateCell func(table *tview.Table, c *cell.Cell) error, RecalculateCell func(table *tview.Table, c *cell.Cell) error, globalData map[[2]int]*cell.Cell, globalViewport *utils.Viewport) {
+func EditCellDialog(app *tview.Application, table *tview.Table, row, column int32, RecordCellEdit func(table *tview.Table, row, col int32, oldCell, newCell *cell.Cell), EvaluateCell func(table *tview.Table, c *cell.Cell) error, RecalculateCell func(table *tview.Table, c *cell.Cell) error, globalData map[[2]int]*cell.Cell, globalViewport *utils.Viewport, formulaCallbacks FormulaCallbacks) {
 	key := [2]int{int(row), int(column)}
 	c, exists := globalData[key]
 	if !exists {
@@ -66,7 +66,7 @@ func EditCellDialog(app *tview.Application, table *tview.Table, row, column int3
 
 	editCellDialog, leftForm := buildEditCellForm(app, table, c, oldCell, row, column,
 		financialSignDropdown, thousandsSeparatorDropdown, decimalSeparatorDropdown, decimalPointsInput,
-		dateTimeFormatDropdown, typeIndex, alignIndex, colorIndex, bgColorIndex, RecordCellEdit, EvaluateCell, RecalculateCell, globalData, globalViewport)
+		dateTimeFormatDropdown, typeIndex, alignIndex, colorIndex, bgColorIndex, RecordCellEdit, EvaluateCell, RecalculateCell, globalData, globalViewport, formulaCallbacks)
 
 	app.SetRoot(editCellDialog, true).SetFocus(leftForm)
 }
@@ -87,18 +87,21 @@ func buildEditCellForm(app *tview.Application, table *tview.Table, c, oldCell *c
 	colorIndex, bgColorIndex int, RecordCellEdit func(table *tview.Table, row, col int32, oldCell, 
 	newCell *cell.Cell), EvaluateCell func(table *tview.Table, c *cell.Cell) error, 
 	RecalculateCell func(table *tview.Table, c *cell.Cell) error, globalData map[[2]int]*cell.Cell, 
-	globalViewport *utils.Viewport) (*tview.Flex, *tview.Form) {
+	globalViewport *utils.Viewport, formulaCallbacks FormulaCallbacks) (*tview.Flex, *tview.Form) {
 
 	container := tview.NewFlex()
 
 	// Left Column - Content & Type
 	leftForm := tview.NewForm()
 	
-	rawValueStr := safeStringValue(c.RawValue)
+	rawValueStr := editText(c)
+	formulaHint := newFormulaHint(app, c, formulaCallbacks)
 	
 	leftForm.AddInputField("Value", rawValueStr, 0, nil, func(text string) {
 		//updateCellValue(app, container, c, text, leftForm)
+		formulaHint.update(text)
 	})
+	formulaHint.attach(leftForm.GetFormItem(0).(*tview.InputField))
 	
 	leftForm.AddDropDown("Type", utils.TypeOptions, typeIndex, func(option string, _ int) {
 		newType := strings.ToLower(option)
@@ -184,6 +187,7 @@ func buildEditCellForm(app *tview.Application, table *tview.Table, c, oldCell *c
 
 	leftColumn := tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(leftForm, 0, 1, false).
+		AddItem(formulaHint, 6, 0, false).
 		AddItem(formatForm, 0, 1, false)
 
 	rightColumn := tview.NewFlex().SetDirection(tview.FlexRow).
@@ -214,10 +218,18 @@ func buildEditCellForm(app *tview.Application, table *tview.Table, c, oldCell *c
 	currentFormIndex := 0
 
 	app.SetFocus(leftForm)
+	formulaHint.resume = func() {
+		app.SetRoot(container, true).SetFocus(leftForm)
+	}
 
 	container.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		typing := formulaHint.typing()
 		switch {
-		case event.Key() == tcell.KeyEscape || event.Rune() == 'q' || event.Rune() == 'Q':
+		case formulaHint.closesList(event):
+			return event
+		case formulaHint.handlesKey(event):
+			return nil
+		case event.Key() == tcell.KeyEscape || (!typing && (event.Rune() == 'q' || event.Rune() == 'Q')):
 			app.SetRoot(table, true).SetFocus(table)
 			return nil
 		case event.Modifiers()&tcell.ModAlt != 0 && (event.Rune() == 's' || event.Rune() == 'S'):
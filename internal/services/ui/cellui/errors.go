@@ -17,14 +17,14 @@ import (
 )
 
 // Modal used for showing that a cell is uneditable
-func ShowUneditableModal(app *tview.Application, table *tview.Table, row, col int32, RecordCellEdit func(table *tview.Table, row, col int32, oldCell, newCell *cell.Cell), EvaluateCell func(table *tview.Table, c *cell.Cell) error, RecalculateCell func(table *tview.Table, c *cell.Cell) error, globalData map[[2]int]*cell.Cell, globalViewport *utils.Viewport) {
+func ShowUneditableModal(app *tview.Application, table *tview.Table, row, col int32, RecordCellEdit func(table *tview.Table, row, col int32, oldCell, newCell *cell.Cell), EvaluateCell func(table *tview.Table, c *cell.Cell) error, RecalculateCell func(table *tview.Table, c *cell.Cell) error, globalData map[[2]int]*cell.Cell, globalViewport *utils.Viewport, formulaCallbacks FormulaCallbacks) {
 	modal := tview.NewModal().
 		SetText(fmt.Sprintf("Cell %s%d is marked as uneditable.\nDo you wish to continue editing anyway?", utils.ColumnName(col), row)).
 		AddButtons([]string{"Yes", "Cancel"}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 			switch buttonLabel {
 			case "Yes":
-				EditCellDialog(app, table, row, col, RecordCellEdit, EvaluateCell, RecalculateCell, globalData, globalViewport)
+				EditCellDialog(app, table, row, col, RecordCellEdit, EvaluateCell, RecalculateCell, globalData, globalViewport, formulaCallbacks)
 			case "Cancel":
 				app.SetRoot(table, true).SetFocus(table)
 			}
@@ -34,6 +34,18 @@ func ShowUneditableModal(app *tview.Application, table *tview.Table, row, col in
 	app.SetRoot(modal, true).SetFocus(modal)
 }
 
+// Modal used for showing that a cell holds part of the array spilled by another formula
+func ShowSpilledCellModal(app *tview.Application, table *tview.Table, row, col int32, anchor string) {
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Cell %s%d is part of the array spilled by the formula in %s.\nEdit %s to change it.", utils.ColumnName(col), row, anchor, anchor)).
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(_ int, _ string) {
+			app.SetRoot(table, true).SetFocus(table)
+		})
+
+	modal.SetBorder(true).SetTitle(" Spilled Cell ").SetTitleAlign(tview.AlignCenter)
+	app.SetRoot(modal, true).SetFocus(modal)
+}
 
 // Shows a modal in case of a type mismatch
 func ShowTypeErrorModal(app *tview.Application, form *tview.Flex, c *cell.Cell, leftForm *tview.Form) {
@@ -74,6 +86,21 @@ func ShowFormulaErrorModal(app *tview.Application, form *tview.Flex, errorCode s
 	case "#REF!":
 		title = " Reference Error ⚠️"
 		message = "Invalid cell reference.\n\nCheck that all referenced cells exist."
+	case "#NUM!":
+		title = " Number Error ⚠️"
+		message = fmt.Sprintf("The result is not a valid number:\n\n%s\n\nCheck that the arguments are within the function's domain.", errorMessage)
+	case "#NAME?":
+		title = " Unknown Name ⚠️"
+		message = fmt.Sprintf("The formula uses a name that does not exist:\n\n%s\n\nCheck the spelling of functions and named ranges.", errorMessage)
+	case "#N/A":
+		title = " Value Not Available ⚠️"
+		message = "A value the formula needs is not available.\n\nUse IFNA or IFERROR to provide a fallback."
+	case "#SPILL!":
+		title = " Spill Error ⚠️"
+		message = fmt.Sprintf("The array result cannot spill:\n\n%s\n\nClear the cells right and below of the formula.", errorMessage)
+	case "#CALC!":
+		title = " Calculation Error ⚠️"
+		message = fmt.Sprintf("The array could not be calculated:\n\n%s", errorMessage)
 	case "#ERROR!":
 		title = " Formula Error ⚠️"
 		message = fmt.Sprintf("An error occurred in the formula:\n\n%s", errorMessage)
@@ -0,0 +1,473 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// formulahint.go helps typing a formula in the edit cell dialog: completion of functions, names, sheets
+// and cells, the signature of the function being called and a preview of the result
+
+package cellui
+
+import (
+	"fmt"
+	"gosheet/internal/services/cell"
+	"gosheet/internal/utils"
+	"gosheet/internal/utils/evaluatefuncs"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// maxCompletions bounds the entries of the completion list
+const maxCompletions = 12
+
+// FormulaCallbacks defines callbacks for looking into the workbook while a formula is typed
+type FormulaCallbacks struct {
+	GetFunctions func() []evaluatefuncs.FunctionInfo                // the LAMBDA functions saved in the workbook
+	GetNames     func() []string                                    // the defined names which are not functions
+	GetSheets    func() []string                                    // the sheet names, quoted where a reference needs it
+	GetCellRefs  func(sheet, prefix string) []string                // the cells holding a value, "" for the sheet being edited
+	Preview      func(c *cell.Cell, formula string) (string, error) // what c would show with the formula
+
+	// PointReference lets a reference be picked on the grid, starting from start or from c, and moved first
+	// by key. done gets the reference, empty when given up, and the key to type after it, if any.
+	PointReference func(c *cell.Cell, formula, start string, key *tcell.EventKey, done func(ref string, next *tcell.EventKey))
+}
+
+// completion is an entry of the completion list and the text it replaces the word being typed with
+type completion struct {
+	label  string
+	insert string
+}
+
+// formulaHint is the panel below the value field, showing the call being typed and the result
+type formulaHint struct {
+	*tview.TextView
+	c           *cell.Cell
+	callbacks   FormulaCallbacks
+	field       *tview.InputField
+	completions []completion
+	start       int
+	listOpen    bool
+
+	// Point mode: resume shows the dialog again, pointed is the text ending in the reference picked last,
+	// and editing is set by F2 for the arrows to move the cursor instead
+	app        *tview.Application
+	resume     func()
+	pointed    string
+	pointedRef string
+	editing    bool
+}
+
+func newFormulaHint(app *tview.Application, c *cell.Cell, callbacks FormulaCallbacks) *formulaHint {
+	h := &formulaHint{
+		TextView:  tview.NewTextView().SetDynamicColors(true).SetWrap(true),
+		c:         c,
+		callbacks: callbacks,
+		app:       app,
+	}
+	h.SetBorder(true).SetTitle(" Formula ").SetTitleAlign(tview.AlignLeft)
+	return h
+}
+
+// attach completes what is typed in field and shows the hint for its current text
+func (h *formulaHint) attach(field *tview.InputField) {
+	h.field = field
+	field.SetAutocompleteStyles(tcell.ColorDarkSlateGray, tcell.StyleDefault.Foreground(tcell.ColorWhite),
+		tcell.StyleDefault.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack))
+	field.SetAutocompleteFunc(func(text string) []string {
+		h.completions, h.start = h.complete(text)
+		h.listOpen = len(h.completions) > 0
+		labels := make([]string, len(h.completions))
+		for i, entry := range h.completions {
+			labels[i] = entry.label
+		}
+		return labels
+	})
+	field.SetAutocompletedFunc(func(_ string, index, source int) bool {
+		if source == tview.AutocompletedNavigate {
+			return false
+		}
+		h.listOpen = false
+		if index >= 0 && index < len(h.completions) {
+			text := field.GetText()
+			field.SetText(text[:h.start] + h.completions[index].insert)
+		}
+		return true
+	})
+	h.update(field.GetText())
+}
+
+// typing reports whether the value field has focus, where q is part of a formula rather than Cancel
+func (h *formulaHint) typing() bool {
+	return h.field != nil && h.field.HasFocus()
+}
+
+// closesList reports whether event only closes the completion list, rather than the dialog
+func (h *formulaHint) closesList(event *tcell.EventKey) bool {
+	if event.Key() != tcell.KeyEscape || !h.listOpen || !h.typing() {
+		return false
+	}
+	h.listOpen = false
+	return true
+}
+
+// handlesKey takes the keys of point mode pressed in the value field: F2, and the arrows while the
+// formula ends where a reference may be written or in the reference picked last
+func (h *formulaHint) handlesKey(event *tcell.EventKey) bool {
+	if h.callbacks.PointReference == nil || !h.typing() || event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) != 0 {
+		return false
+	}
+	if event.Key() == tcell.KeyF2 {
+		h.editing = !h.editing
+		h.update(h.field.GetText())
+		return true
+	}
+
+	switch event.Key() {
+	case tcell.KeyUp, tcell.KeyDown, tcell.KeyLeft, tcell.KeyRight:
+	default:
+		return false
+	}
+	text := h.field.GetText()
+	before, start := text, ""
+	if h.pointedRef != "" && text == h.pointed {
+		before, start = strings.TrimSuffix(text, h.pointedRef), h.pointedRef
+	} else if h.editing || h.listOpen || !atReference(text) {
+		return false
+	}
+
+	h.callbacks.PointReference(h.c, strings.TrimSpace(before), start, event, func(ref string, next *tcell.EventKey) {
+		if h.resume != nil {
+			h.resume()
+		}
+		if ref != "" {
+			h.field.SetText(before + ref)
+			h.pointed, h.pointedRef = before+ref, ref
+		}
+		if next != nil {
+			h.field.InputHandler()(next, func(p tview.Primitive) { h.app.SetFocus(p) })
+		}
+	})
+	return true
+}
+
+// atReference reports whether text is a formula ending where a reference may be written, like $=SUM( or $=A1+
+func atReference(text string) bool {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "$=") || strings.Count(text, "\"")%2 == 1 {
+		return false
+	}
+	formula := strings.TrimSpace(text[2:])
+	return formula == "" || strings.ContainsRune("(,;:+-*/^&=<>", rune(formula[len(formula)-1]))
+}
+
+// update shows the signature of the call being typed and the result of the formula
+func (h *formulaHint) update(text string) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "$=") {
+		h.SetTitle(" Formula ")
+		h.SetText("[::d]Start the value with $= to write a formula[::-]")
+		return
+	}
+
+	switch {
+	case h.callbacks.PointReference == nil:
+		h.SetTitle(" Formula ")
+	case h.editing:
+		h.SetTitle(" Formula · F2 arrows point at cells ")
+	default:
+		h.SetTitle(" Formula · ←↑↓→ point at cells, F2 move cursor ")
+	}
+	formula := strings.TrimSpace(text[2:])
+
+	// The result comes first, so it stays in sight when a long signature wraps
+	var lines []string
+	if formula != "" && h.callbacks.Preview != nil {
+		display, err := h.callbacks.Preview(h.c, utils.CurrentLocale.CanonicalFormula(formula))
+		switch {
+		case err == nil:
+			lines = append(lines, "[green]= "+tview.Escape(display)+"[-]")
+		case display != "" && display != firstLine(err.Error()):
+			lines = append(lines, fmt.Sprintf("[red]%s[-] %s", tview.Escape(display), tview.Escape(firstLine(err.Error()))))
+		default:
+			lines = append(lines, "[red]"+tview.Escape(firstLine(err.Error()))+"[-]")
+		}
+	}
+
+	if name, pos, ok := openCall(formula); ok {
+		if info, found := h.lookup(name); found {
+			lines = append(lines, highlightArg(info, pos)...)
+		} else {
+			lines = append(lines, fmt.Sprintf("[red]%s[-] is not a known function", tview.Escape(name)))
+		}
+	} else {
+		lines = append(lines, "[::d]Type a function, a name, a sheet or a cell; ↓ lists the completions[::-]")
+	}
+
+	h.SetText(strings.Join(lines, "\n"))
+	h.ScrollToBeginning()
+}
+
+// lookup describes a built-in, plugin or workbook function
+func (h *formulaHint) lookup(name string) (evaluatefuncs.FunctionInfo, bool) {
+	if h.callbacks.GetFunctions != nil {
+		for _, fn := range h.callbacks.GetFunctions() {
+			if strings.EqualFold(fn.Name, name) {
+				return fn, true
+			}
+		}
+	}
+	return evaluatefuncs.LookupFunction(name)
+}
+
+// highlightArg writes the signature of a function with the argument at pos highlighted, and what it means
+func highlightArg(info evaluatefuncs.FunctionInfo, pos int) []string {
+	current, ok := info.ArgAt(pos, pos+1)
+	lastRepeat := -1
+	for i, a := range info.Args {
+		if a.Repeats {
+			lastRepeat = i
+		}
+	}
+
+	parts := make([]string, 0, len(info.Args)+1)
+	for i, a := range info.Args {
+		label := tview.Escape(a.Label())
+		if ok && i == current {
+			label = "[yellow::b]" + label + "[-::-]"
+		}
+		parts = append(parts, label)
+		if i == lastRepeat {
+			parts = append(parts, "...")
+		}
+	}
+	separator := string(utils.CurrentLocale.ArgumentSeparator) + " "
+	lines := []string{fmt.Sprintf("[::b]%s[::-](%s)", tview.Escape(info.Name), strings.Join(parts, separator))}
+
+	switch {
+	case ok && info.Args[current].Description != "":
+		lines = append(lines, fmt.Sprintf("[yellow]%s[-]: %s", tview.Escape(info.Args[current].Name), tview.Escape(info.Args[current].Description)))
+	case !ok:
+		lines = append(lines, fmt.Sprintf("[red]%s takes at most %d argument(s)[-]", tview.Escape(info.Name), len(info.Args)))
+	case info.Description != "":
+		lines = append(lines, "[::d]"+tview.Escape(info.Description)+"[::-]")
+	}
+	return lines
+}
+
+// openCall returns the function whose call is still open at the end of formula, and which of its
+// arguments is being typed, the arguments being separated the way the current locale separates them
+func openCall(formula string) (string, int, bool) {
+	type call struct {
+		name string
+		arg  int
+	}
+	var calls []call
+	inString := false
+
+	for i := 0; i < len(formula); i++ {
+		ch := formula[i]
+		switch {
+		case ch == '"':
+			inString = !inString
+		case inString:
+		case ch == '\'':
+			// A quoted sheet name may hold parentheses and commas; '' stands for a quote in it
+			for i++; i < len(formula); i++ {
+				if formula[i] != '\'' {
+					continue
+				}
+				if i+1 < len(formula) && formula[i+1] == '\'' {
+					i++
+					continue
+				}
+				break
+			}
+		case ch == '(':
+			calls = append(calls, call{name: identBefore(formula, i)})
+		case ch == ')':
+			if len(calls) > 0 {
+				calls = calls[:len(calls)-1]
+			}
+		case rune(ch) == utils.CurrentLocale.ArgumentSeparator:
+			if len(calls) > 0 {
+				calls[len(calls)-1].arg++
+			}
+		}
+	}
+
+	for j := len(calls) - 1; j >= 0; j-- {
+		if calls[j].name != "" {
+			return calls[j].name, calls[j].arg, true
+		}
+	}
+	return "", 0, false
+}
+
+// identBefore returns the name written right before formula[end], skipping blanks
+func identBefore(formula string, end int) string {
+	for end > 0 && formula[end-1] == ' ' {
+		end--
+	}
+	start := end
+	for start > 0 && isFormulaWordChar(formula[start-1]) {
+		start--
+	}
+	name := formula[start:end]
+	if name == "" || !isFormulaWordStart(name[0]) {
+		return ""
+	}
+	return name
+}
+
+// complete returns the completions of the word at the end of text and where that word starts
+func (h *formulaHint) complete(text string) ([]completion, int) {
+	if !strings.HasPrefix(strings.TrimLeft(text, " "), "$=") {
+		return nil, 0
+	}
+
+	// Nothing is completed inside a string, and a quote opens a sheet name
+	inString, quote := false, -1
+	for i := strings.Index(text, "$=") + 2; i < len(text); i++ {
+		switch {
+		case text[i] == '"' && quote == -1:
+			inString = !inString
+		case text[i] == '\'' && !inString:
+			if quote == -1 {
+				quote = i
+			} else {
+				quote = -1
+			}
+		}
+	}
+	if inString {
+		return nil, 0
+	}
+	if quote != -1 {
+		return h.completeSheets(text[quote:]), quote
+	}
+
+	start := len(text)
+	for start > 0 && isFormulaWordChar(text[start-1]) {
+		start--
+	}
+	word := text[start:]
+	if word == "" || !isFormulaWordStart(word[0]) {
+		return nil, 0
+	}
+
+	// After Sheet2! or 'My Sheet'! only the cells of that sheet are offered
+	if start > 0 && text[start-1] == '!' {
+		sheet := sheetBefore(text, start-1)
+		if sheet == "" {
+			return nil, 0
+		}
+		return h.completeCells(sheet, word), start
+	}
+
+	var entries []completion
+	upper := strings.ToUpper(word)
+	for _, fn := range h.functions() {
+		if strings.HasPrefix(fn, upper) {
+			entries = append(entries, completion{label: fn + "(", insert: fn + "("})
+		}
+	}
+	if h.callbacks.GetNames != nil {
+		for _, name := range h.callbacks.GetNames() {
+			if strings.HasPrefix(strings.ToUpper(name), upper) && !strings.EqualFold(name, word) {
+				entries = append(entries, completion{label: name, insert: name})
+			}
+		}
+	}
+	entries = append(entries, h.completeSheets(word)...)
+	if strings.ContainsAny(word, "0123456789") {
+		entries = append(entries, h.completeCells("", word)...)
+	}
+
+	if len(entries) > maxCompletions {
+		entries = entries[:maxCompletions]
+	}
+	return entries, start
+}
+
+// functions returns the names of every function which may be called, in name order
+func (h *formulaHint) functions() []string {
+	var names []string
+	for _, fn := range evaluatefuncs.Functions() {
+		names = append(names, fn.Name)
+	}
+	if h.callbacks.GetFunctions != nil {
+		for _, fn := range h.callbacks.GetFunctions() {
+			names = append(names, strings.ToUpper(fn.Name))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completeSheets offers the sheets whose name, as written in a reference, starts with prefix
+func (h *formulaHint) completeSheets(prefix string) []completion {
+	if h.callbacks.GetSheets == nil {
+		return nil
+	}
+	var entries []completion
+	for _, sheet := range h.callbacks.GetSheets() {
+		if strings.HasPrefix(strings.ToUpper(sheet), strings.ToUpper(prefix)) {
+			entries = append(entries, completion{label: sheet + "!", insert: sheet + "!"})
+		}
+	}
+	return entries
+}
+
+// completeCells offers the cells of a sheet holding a value whose address starts with prefix
+func (h *formulaHint) completeCells(sheet, prefix string) []completion {
+	if h.callbacks.GetCellRefs == nil {
+		return nil
+	}
+	var entries []completion
+	for _, ref := range h.callbacks.GetCellRefs(sheet, strings.ToUpper(prefix)) {
+		if ref != strings.ToUpper(prefix) {
+			entries = append(entries, completion{label: ref, insert: ref})
+		}
+		if len(entries) == maxCompletions {
+			break
+		}
+	}
+	return entries
+}
+
+// sheetBefore returns the sheet name qualifying the reference whose ! is at text[bang], unquoted
+func sheetBefore(text string, bang int) string {
+	if bang > 0 && text[bang-1] == '\'' {
+		open := strings.LastIndex(text[:bang-1], "'")
+		for open > 0 && text[open-1] == '\'' {
+			open = strings.LastIndex(text[:open-1], "'")
+		}
+		if open < 0 {
+			return ""
+		}
+		return strings.ReplaceAll(text[open+1:bang-1], "''", "'")
+	}
+	start := bang
+	for start > 0 && isFormulaWordChar(text[start-1]) {
+		start--
+	}
+	return text[start:bang]
+}
+
+// firstLine drops the lines after the first of an error message, like the excerpt of a compile error
+func firstLine(message string) string {
+	line, _, _ := strings.Cut(message, "\n")
+	return line
+}
+
+func isFormulaWordStart(ch byte) bool {
+	return (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') || ch == '_'
+}
+
+func isFormulaWordChar(ch byte) bool {
+	return isFormulaWordStart(ch) || (ch >= '0' && ch <= '9') || ch == '.'
+}
@@ -10,6 +10,7 @@ package cellui
 import (
 	"gosheet/internal/services/cell"
 	"gosheet/internal/utils"
+	"strconv"
 	"strings"
 
 	"github.com/rivo/tview"
@@ -86,6 +87,49 @@ func safeStringValue(ptr *string) string {
 	return *ptr
 }
 
+// editText returns the value of c the way it is typed in the current locale, like $=ROUND(A1*1,19; 2) in de-DE
+func editText(c *cell.Cell) string {
+	raw := safeStringValue(c.RawValue)
+	switch {
+	case strings.HasPrefix(raw, "$="):
+		return utils.CurrentLocale.LocalizeFormula(raw)
+	case isNumberType(c) || isGeneralType(c):
+		return utils.CurrentLocale.LocalizeNumber(raw)
+	}
+	return raw
+}
+
+// canonicalInput writes a value typed in the current locale the way cells store it: formulas with , between
+// the arguments and . before the decimals, and numbers with . before the decimals, in number cells and in
+// general ones, where 1,5 typed in de-DE is a number and not text
+func canonicalInput(c *cell.Cell, text string) string {
+	l := utils.CurrentLocale
+	switch {
+	case strings.HasPrefix(text, "$="):
+		return l.CanonicalFormula(text)
+	case isNumberType(c) && l.DecimalSeparator != '.':
+		if val, err := l.ParseNumber(strings.TrimPrefix(text, string(c.FinancialSign))); err == nil {
+			return strconv.FormatFloat(val, 'f', -1, 64)
+		}
+	case isGeneralType(c):
+		return l.CanonicalNumber(text)
+	}
+	return text
+}
+
+// isGeneralType reports whether c takes any value, numbers being read from what is typed
+func isGeneralType(c *cell.Cell) bool {
+	return c.Type == nil || strings.EqualFold(*c.Type, "string")
+}
+
+func isNumberType(c *cell.Cell) bool {
+	if c.Type == nil {
+		return false
+	}
+	cellType := strings.ToLower(*c.Type)
+	return cellType == "number" || cellType == "financial"
+}
+
 func disableFormattingFields(items ...tview.Primitive) {
 	for _, item := range items {
 		if d, ok := item.(interface{ SetDisabled(bool) *tview.DropDown }); ok { d.SetDisabled(true) }
@@ -75,7 +75,7 @@ func updateCellValue(app *tview.Application, container *tview.Flex, c *cell.Cell
 
 func SaveCellFormButtonAndKeyMap(app *tview.Application, table *tview.Table, container *tview.Flex, c, oldCell *cell.Cell, row, column int32, leftForm *tview.Form, RecordCellEdit func(table *tview.Table, row, col int32, oldCell, newCell *cell.Cell), EvaluateCell func(table *tview.Table, c *cell.Cell) error, RecalculateCell func(table *tview.Table, c *cell.Cell) error, globalData map[[2]int]*cell.Cell, globalViewport *utils.Viewport) {
 	valueField := leftForm.GetFormItem(0).(*tview.InputField)
-	currentValue := strings.TrimSpace(valueField.GetText())
+	currentValue := canonicalInput(c, strings.TrimSpace(valueField.GetText()))
 
 	updateCellValue(app, container, c, currentValue, leftForm)
 
@@ -11,6 +11,9 @@ import "regexp"
 
 var validationCellRefRegex = regexp.MustCompile(`\b([A-Z]+)(\d+)\b`)
 
+// ResolveNameFunc is a hook set by the table package to look up the value of a workbook name used in a rule
+var ResolveNameFunc func(name string) (any, bool)
+
 // ValidationPreset represents a predefined validation type
 type ValidationPreset struct {
 	Name        string
@@ -25,7 +25,7 @@ func ValidateValidationRule(ruleText string, cellData *cell.Cell) bool {
 		return true
 	}
 
-	upperRule := strings.ToUpper(ruleText)
+	upperRule := strings.ToUpper(expandRuleNames(ruleText))
 	matches := validationCellRefRegex.FindAllString(upperRule, -1)
 
 	for _, match := range matches {
@@ -64,6 +64,89 @@ func ValidateValidationRule(ruleText string, cellData *cell.Cell) bool {
 	return true
 }
 
+// expandRuleNames replaces workbook names in a rule, such as THIS <= MaxDiscount, by their current values
+func expandRuleNames(rule string) string {
+	if ResolveNameFunc == nil {
+		return rule
+	}
+
+	var result strings.Builder
+	i := 0
+	for i < len(rule) {
+		ch := rule[i]
+
+		if ch == '"' {
+			j := i + 1
+			for j < len(rule) && (rule[j] != '"' || rule[j-1] == '\\') {
+				j++
+			}
+			if j < len(rule) {
+				j++
+			}
+			result.WriteString(rule[i:j])
+			i = j
+			continue
+		}
+
+		if isRuleIdentStart(ch) && (i == 0 || !isRuleIdentChar(rule[i-1])) {
+			j := i
+			for j < len(rule) && isRuleIdentChar(rule[j]) {
+				j++
+			}
+			name := rule[i:j]
+
+			k := j
+			for k < len(rule) && rule[k] == ' ' {
+				k++
+			}
+			isCall := k < len(rule) && rule[k] == '('
+
+			if !isCall && !strings.EqualFold(name, "THIS") {
+				if val, ok := ResolveNameFunc(name); ok {
+					result.WriteString(ruleLiteral(val))
+					i = j
+					continue
+				}
+			}
+
+			result.WriteString(name)
+			i = j
+			continue
+		}
+
+		result.WriteByte(ch)
+		i++
+	}
+
+	return result.String()
+}
+
+// ruleLiteral writes a resolved name value as an expression literal
+func ruleLiteral(val any) string {
+	switch v := val.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		// Rules are upper-cased before compiling, so boolean keywords cannot be used directly
+		if v {
+			return "(1 == 1)"
+		}
+		return "(1 == 0)"
+	default:
+		return strconv.Quote(fmt.Sprintf("%v", v))
+	}
+}
+
+func isRuleIdentStart(ch byte) bool {
+	return (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') || ch == '_'
+}
+
+func isRuleIdentChar(ch byte) bool {
+	return isRuleIdentStart(ch) || (ch >= '0' && ch <= '9') || ch == '.'
+}
+
 // EnforceValidationOnEdit checks validation before saving a cell edit
 func EnforceValidationOnEdit(app *tview.Application, returnTo tview.Primitive, cellData *cell.Cell, newValue string) bool {
 	if strings.TrimSpace(newValue) == "" {
@@ -127,7 +210,7 @@ func CheckValidationRule(cellData *cell.Cell, newValue string) (bool, string) {
 		testValue = newValue
 	}
 
-	rule := strings.TrimSpace(*cellData.Valrule)
+	rule := expandRuleNames(strings.TrimSpace(*cellData.Valrule))
 	upperRule := strings.ToUpper(rule)
 
 	var replacementValue string
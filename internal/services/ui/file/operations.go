@@ -76,14 +76,14 @@ func performSave(app *tview.Application, table *tview.Table, filename string, fo
 
 
 // Modal used for showing that a cell is uneditable
-func ShowUneditableModal(app *tview.Application, table *tview.Table, row, col int32, RecordCellEdit func(table *tview.Table, row, col int32, oldCell, newCell *cell.Cell), EvaluateCell func(table *tview.Table, c *cell.Cell) error, RecalculateCell func(table *tview.Table, c *cell.Cell) error, globalData map[[2]int]*cell.Cell, globalViewport *utils.Viewport) {
+func ShowUneditableModal(app *tview.Application, table *tview.Table, row, col int32, RecordCellEdit func(table *tview.Table, row, col int32, oldCell, newCell *cell.Cell), EvaluateCell func(table *tview.Table, c *cell.Cell) error, RecalculateCell func(table *tview.Table, c *cell.Cell) error, globalData map[[2]int]*cell.Cell, globalViewport *utils.Viewport, formulaCallbacks cellui.FormulaCallbacks) {
 	modal := tview.NewModal().
 		SetText(fmt.Sprintf("Cell %s%d is marked as uneditable.\nDo you wish to continue editing anyway?", utils.ColumnName(col), row)).
 		AddButtons([]string{"Yes", "Cancel"}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 			switch buttonLabel {
 			case "Yes":
-				cellui.EditCellDialog(app, table, row, col, RecordCellEdit, EvaluateCell, RecalculateCell, globalData, globalViewport)
+				cellui.EditCellDialog(app, table, row, col, RecordCellEdit, EvaluateCell, RecalculateCell, globalData, globalViewport, formulaCallbacks)
 			case "Cancel":
 				app.SetRoot(table, true).SetFocus(table)
 			}
@@ -3,18 +3,22 @@
 // This work is licensed under the terms of the MIT license.
 // For a copy, see <https://opensource.org/licenses/MIT>.
 
-// helpUI.go contains the help modal
+// helpUI.go contains the help modal: a searchable browser of the functions, and the keyboard shortcuts
 
 package ui
 
 import (
+	"fmt"
+	"gosheet/internal/utils/evaluatefuncs"
+	"sort"
+	"strings"
+
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
-// Help Modal
-func ShowHelpModal(app *tview.Application, table *tview.Table) {
-	helpText := `[yellow]NAVIGATION:[white]
+// shortcutsHelp lists the keyboard shortcuts, the first entry of the help
+const shortcutsHelp = `[yellow]NAVIGATION:[white]
   Arrow Keys           Navigate cells
   Shift + Arrows       Select range
   Alt + G              Go to cell
@@ -43,6 +47,14 @@ func ShowHelpModal(app *tview.Application, table *tview.Table) {
   Alt+C           Duplicate Sheet
   Alt+S           Switch to Sheet
 
+[yellow]NAMED RANGES:[white]
+  Alt + L              Name Manager
+  
+[yellow]In Name Manager:[white]
+  Alt+N           New Name (prefilled with selection)
+  Alt+E           Edit Name
+  Alt+D           Delete Name
+
 [yellow]CLIPBOARD:[white]
   Alt + C              Copy
   Alt + V              Paste
@@ -65,33 +77,231 @@ func ShowHelpModal(app *tview.Application, table *tview.Table) {
 [yellow]SORTING:[white]
   Alt + O              Sort dialog
 
+[yellow]AUDITING:[white]
+  Alt + U              Audit mode: precedents and dependents
+  [ / ]                Step into the first precedent/dependent
+  Backspace            Step back
+  Tab                  Panel; Enter jumps to the entry
+  Alt + E              Evaluate formula step by step
+
+[yellow]CALCULATION:[white]
+  Alt + K              Calculation options (mode, iteration, seed, locale)
+  F9                   Recalculate the workbook
+  Shift + F9           Recalculate the active sheet
+
 [yellow]HELP:[white]
-  Alt + /              Show this help`
+  Alt + /              Function browser and this help`
+
+// helpEntry is one line of the help browser and the details shown for it
+type helpEntry struct {
+	title    string
+	subtitle string
+	details  string
+	search   string // what the search matches against, in lower case
+	function bool
+}
+
+// Help Modal
+func ShowHelpModal(app *tview.Application, table *tview.Table, workbookFunctions []evaluatefuncs.FunctionInfo) {
+	entries := helpEntries(workbookFunctions)
+
+	search := tview.NewInputField().
+		SetLabel(" Search: ").
+		SetPlaceholder("function name, category or description").
+		SetFieldBackgroundColor(tcell.ColorBlack)
 
-	textView := tview.NewTextView().
+	list := tview.NewList().
+		SetSelectedBackgroundColor(tcell.ColorDarkCyan).
+		SetSelectedTextColor(tcell.ColorWhite).
+		SetMainTextColor(tcell.ColorWhite).
+		SetSecondaryTextColor(tcell.ColorGray).
+		ShowSecondaryText(true)
+	list.SetBorder(true).
+		SetTitle(" Functions ").
+		SetBorderColor(tcell.ColorLightBlue).
+		SetTitleAlign(tview.AlignLeft)
+
+	details := tview.NewTextView().
 		SetDynamicColors(true).
-		SetText(helpText).
 		SetScrollable(true).
-		SetWrap(false)
-	
-	textView.SetBorder(true).
-		SetTitle(" Keyboard Shortcuts - Use arrow keys to scroll • Press ESC to close ").
+		SetWordWrap(true)
+	details.SetBorder(true).
+		SetTitle(" Details ").
+		SetBorderColor(tcell.ColorLightBlue).
+		SetTitleAlign(tview.AlignLeft)
+
+	hint := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(" [yellow]↑/↓[-] Select  [yellow]Tab[-] Scroll details  [yellow]Esc[-] Close")
+
+	browser := tview.NewFlex().
+		AddItem(list, 36, 0, false).
+		AddItem(details, 0, 1, false)
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(search, 1, 0, true).
+		AddItem(browser, 0, 1, false).
+		AddItem(hint, 1, 0, false)
+	layout.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Help - %d Functions & Keyboard Shortcuts ", countFunctions(entries))).
 		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorYellow).
 		SetBackgroundColor(tcell.ColorBlack)
-	
-	textView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+
+	var shown []helpEntry
+	list.SetChangedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		if index >= 0 && index < len(shown) {
+			details.SetText(shown[index].details).ScrollToBeginning()
+		}
+	})
+	filter := func(query string) {
+		shown = filterHelpEntries(entries, query)
+		list.Clear()
+		for _, entry := range shown {
+			list.AddItem(" "+tview.Escape(entry.title), "   "+tview.Escape(entry.subtitle), 0, nil)
+		}
+		if len(shown) == 0 {
+			details.SetText("[gray]Nothing matches the search.[-]")
+			return
+		}
+		details.SetText(shown[0].details).ScrollToBeginning()
+	}
+	search.SetChangedFunc(filter)
+	filter("")
+
+	layout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Key() {
 		case tcell.KeyEscape:
 			app.SetRoot(table, true).SetFocus(table)
 			return nil
-		case tcell.KeyEnter:
-			app.SetRoot(table, true).SetFocus(table)
+		case tcell.KeyTab, tcell.KeyBacktab:
+			if search.HasFocus() {
+				app.SetFocus(details)
+			} else {
+				app.SetFocus(search)
+			}
 			return nil
+		case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn:
+			// The search keeps the focus while the arrows move through the list
+			if search.HasFocus() {
+				list.InputHandler()(event, func(p tview.Primitive) {})
+				return nil
+			}
+		case tcell.KeyEnter:
+			if search.HasFocus() {
+				app.SetFocus(details)
+				return nil
+			}
 		}
 		return event
 	})
 
-	app.SetRoot(textView, true).SetFocus(textView)
+	app.SetRoot(layout, true).SetFocus(search)
+}
+
+// helpEntries lists the keyboard shortcuts, the problems of plugins if any, and every function
+func helpEntries(workbookFunctions []evaluatefuncs.FunctionInfo) []helpEntry {
+	entries := []helpEntry{{
+		title:    "Keyboard shortcuts",
+		subtitle: "Navigation, editing, sheets...",
+		details:  shortcutsHelp,
+		search:   "keyboard shortcuts keys help",
+	}}
+
+	if errs := evaluatefuncs.PluginErrors(); len(errs) > 0 {
+		var text strings.Builder
+		fmt.Fprintf(&text, "[yellow]PLUGIN PROBLEMS:[white]\n  Plugins are loaded from %s\n\n", tview.Escape(evaluatefuncs.PluginDir()))
+		for _, err := range errs {
+			fmt.Fprintf(&text, "  [red]%s[white]\n", tview.Escape(err.Error()))
+		}
+		entries = append(entries, helpEntry{
+			title:    "Plugin problems",
+			subtitle: fmt.Sprintf("%d problem(s) loading plugins", len(errs)),
+			details:  text.String(),
+			search:   "plugins problems errors",
+		})
+	}
+
+	functions := append(evaluatefuncs.Functions(), workbookFunctions...)
+	sort.SliceStable(functions, func(i, j int) bool { return functions[i].Name < functions[j].Name })
+	for _, f := range functions {
+		entries = append(entries, helpEntry{
+			title:    f.Name,
+			subtitle: f.Category,
+			details:  functionDetails(f),
+			search:   strings.ToLower(f.Name + "\n" + f.Category + "\n" + f.Description),
+			function: true,
+		})
+	}
+	return entries
+}
+
+// filterHelpEntries returns the entries matching the query: names starting with it first, then names
+// containing it, then the ones whose category or description mention it
+func filterHelpEntries(entries []helpEntry, query string) []helpEntry {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return entries
+	}
+
+	var prefix, name, other []helpEntry
+	for _, entry := range entries {
+		title := strings.ToLower(entry.title)
+		switch {
+		case strings.HasPrefix(title, query):
+			prefix = append(prefix, entry)
+		case strings.Contains(title, query):
+			name = append(name, entry)
+		case strings.Contains(entry.search, query):
+			other = append(other, entry)
+		}
+	}
+	return append(append(prefix, name...), other...)
+}
+
+// functionDetails describes a function: signature, arguments and an example
+func functionDetails(f evaluatefuncs.FunctionInfo) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "[yellow::b]%s[-::-]\n", tview.Escape(f.Signature()))
+	fmt.Fprintf(&text, "[gray]%s[-]\n\n", tview.Escape(f.Category))
+	if f.Description != "" {
+		fmt.Fprintf(&text, "%s\n\n", tview.Escape(f.Description))
+	}
+
+	if len(f.Args) > 0 {
+		text.WriteString("[lightblue]Arguments:[-]\n")
+		for _, a := range f.Args {
+			label := a.Label()
+			if a.Repeats {
+				label += " ..."
+			}
+			fmt.Fprintf(&text, "  [white::b]%s[-::-]", tview.Escape(label))
+			if a.Description != "" {
+				fmt.Fprintf(&text, "  %s", tview.Escape(a.Description))
+			}
+			text.WriteString("\n")
+		}
+		text.WriteString("\n")
+	}
+
+	if f.Example != "" {
+		fmt.Fprintf(&text, "[lightblue]Example:[-]\n  $=%s\n\n", tview.Escape(f.Example))
+	}
+	if f.Volatile {
+		text.WriteString("[yellow]Volatile:[-] recalculated whenever the workbook is, even if nothing it reads changed\n")
+	}
+	return strings.TrimSuffix(text.String(), "\n")
+}
+
+// countFunctions returns how many of the entries are functions
+func countFunctions(entries []helpEntry) int {
+	n := 0
+	for _, entry := range entries {
+		if entry.function {
+			n++
+		}
+	}
+	return n
 }
 
 // Warning Modal
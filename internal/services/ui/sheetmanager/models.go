@@ -40,4 +40,20 @@ type WorkbookInfo struct {
 	HasChanges  bool
 }
 
+// NameManagerCallbacks defines callbacks for named range operations
+type NameManagerCallbacks struct {
+	GetNames          func() []NameInfo
+	DefineName        func(name, value, comment string) error
+	DeleteName        func(name string) error
+	GetSelectionRef   func() string
+	MarkAsModified    func()
+	RenderActiveSheet func()
+}
 
+// NameInfo contains information about a single defined name
+type NameInfo struct {
+	Name    string
+	Value   string
+	Comment string
+	Preview string
+}
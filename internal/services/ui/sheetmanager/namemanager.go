@@ -0,0 +1,264 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// namemanager.go provides the dialog for managing named ranges and constants
+
+package sheetmanager
+
+import (
+	"fmt"
+	"gosheet/internal/utils"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// ShowNameManager displays the dialog listing the workbook's defined names
+func ShowNameManager(app *tview.Application, table *tview.Table, callbacks NameManagerCallbacks) {
+	list := tview.NewList().
+		SetSelectedBackgroundColor(tcell.ColorDarkCyan).
+		SetSelectedTextColor(tcell.ColorWhite).
+		SetMainTextColor(tcell.ColorWhite).
+		SetSecondaryTextColor(tcell.ColorGray).
+		ShowSecondaryText(true)
+	list.SetBorder(true).
+		SetTitle(" Names ").
+		SetBorderColor(tcell.ColorLightBlue).
+		SetTitleAlign(tview.AlignLeft)
+
+	infoPanel := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft).
+		SetWordWrap(true)
+	infoPanel.SetBorder(true).
+		SetTitle(" Details ").
+		SetBorderColor(tcell.ColorLightBlue).
+		SetTitleAlign(tview.AlignLeft)
+
+	helpPanel := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(
+			"[yellow]Alt+N[-]  New name\n" +
+				"[yellow]Alt+E[-]  Edit selected\n" +
+				"[yellow]Alt+D[-]  Delete selected\n" +
+				"[yellow]Esc[-]    Close\n\n" +
+				"[gray]Use names in formulas and validation rules, e.g. $=Price * TaxRate[-]")
+	helpPanel.SetBorder(true).
+		SetTitle(" Actions ").
+		SetBorderColor(tcell.ColorLightBlue).
+		SetTitleAlign(tview.AlignLeft)
+
+	rightPanel := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(infoPanel, 0, 1, false).
+		AddItem(helpPanel, 10, 0, false)
+
+	mainLayout := tview.NewFlex().
+		AddItem(list, 0, 2, true).
+		AddItem(rightPanel, 45, 0, false)
+	mainLayout.SetBorder(true).
+		SetTitle(" Name Manager ").
+		SetBorderColor(tcell.ColorYellow).
+		SetTitleAlign(tview.AlignCenter)
+
+	updateNameList(list, infoPanel, callbacks)
+
+	list.SetChangedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		names := callbacks.GetNames()
+		if index >= 0 && index < len(names) {
+			infoPanel.SetText(getNameInfoText(names[index]))
+		}
+	})
+
+	mainLayout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			app.SetRoot(table, true).SetFocus(table)
+			return nil
+		}
+
+		if event.Modifiers()&tcell.ModAlt != 0 {
+			switch event.Rune() {
+			case 'n', 'N':
+				showNameForm(app, callbacks, mainLayout, list, infoPanel, nil)
+				return nil
+			case 'e', 'E':
+				if selected := selectedName(list, callbacks); selected != nil {
+					showNameForm(app, callbacks, mainLayout, list, infoPanel, selected)
+				}
+				return nil
+			case 'd', 'D':
+				confirmDeleteName(app, callbacks, mainLayout, list, infoPanel)
+				return nil
+			}
+		}
+
+		return event
+	})
+
+	app.SetRoot(mainLayout, true).SetFocus(list)
+}
+
+// updateNameList refreshes the list of names
+func updateNameList(list *tview.List, infoPanel *tview.TextView, callbacks NameManagerCallbacks) {
+	list.Clear()
+	names := callbacks.GetNames()
+
+	for _, name := range names {
+		list.AddItem(
+			fmt.Sprintf(" %s", name.Name),
+			fmt.Sprintf("   └─ %s = %s", utils.CurrentLocale.LocalizeFormula(name.Value), name.Preview),
+			0,
+			nil,
+		)
+	}
+
+	if len(names) == 0 {
+		infoPanel.SetText("[gray]No names defined yet.\n\nPress Alt+N to define one.[-]")
+		return
+	}
+	infoPanel.SetText(getNameInfoText(names[list.GetCurrentItem()]))
+}
+
+// selectedName returns the name under the list cursor
+func selectedName(list *tview.List, callbacks NameManagerCallbacks) *NameInfo {
+	names := callbacks.GetNames()
+	index := list.GetCurrentItem()
+	if index < 0 || index >= len(names) {
+		return nil
+	}
+	return &names[index]
+}
+
+// getNameInfoText returns the details of a defined name
+func getNameInfoText(name NameInfo) string {
+	comment := name.Comment
+	if strings.TrimSpace(comment) == "" {
+		comment = "[gray]none[-]"
+	}
+
+	return fmt.Sprintf(
+		"[::b]NAME DETAILS[::-]\n"+
+			"[gray]━━━━━━━━━━━━━━━━━━━━[-]\n"+
+			"[lightblue]Name:[-]  [white::b]%s[::-]\n"+
+			"[lightblue]Refers to:[-]\n  [white]%s[-]\n"+
+			"[lightblue]Value:[-]\n  [white]%s[-]\n"+
+			"[lightblue]Comment:[-]\n  %s",
+		name.Name,
+		utils.CurrentLocale.LocalizeFormula(name.Value),
+		name.Preview,
+		comment,
+	)
+}
+
+// showNameForm shows the dialog to define a new name or edit an existing one
+func showNameForm(app *tview.Application, callbacks NameManagerCallbacks,
+	returnTo tview.Primitive, list *tview.List, infoPanel *tview.TextView, existing *NameInfo) {
+
+	form := tview.NewForm()
+	form.SetFieldBackgroundColor(tcell.ColorBlack)
+	form.SetButtonBackgroundColor(tcell.ColorDarkGreen)
+	form.SetButtonTextColor(tcell.ColorWhite)
+
+	name, value, comment := "", callbacks.GetSelectionRef(), ""
+	title := " + New Name "
+	if existing != nil {
+		name, value, comment = existing.Name, utils.CurrentLocale.LocalizeFormula(existing.Value), existing.Comment
+		title = " Edit Name "
+	}
+
+	nameInput := tview.NewInputField().
+		SetLabel("Name: ").
+		SetText(name).
+		SetFieldWidth(30)
+	valueInput := tview.NewInputField().
+		SetLabel("Refers to: ").
+		SetText(value).
+		SetFieldWidth(30).
+		SetPlaceholder(fmt.Sprintf("Sheet1!$B$2, A2:A500, %s or %s",
+			utils.CurrentLocale.LocalizeFormula("0.19"), utils.CurrentLocale.LocalizeFormula("LAMBDA(x, x*2)")))
+	commentInput := tview.NewInputField().
+		SetLabel("Comment: ").
+		SetText(comment).
+		SetFieldWidth(30)
+
+	form.AddFormItem(nameInput).
+		AddFormItem(valueInput).
+		AddFormItem(commentInput).
+		AddButton("Save", func() {
+			newName := strings.TrimSpace(nameInput.GetText())
+
+			if existing != nil && !strings.EqualFold(existing.Name, newName) {
+				if err := callbacks.DeleteName(existing.Name); err != nil {
+					ShowWarningModal(app, form, err.Error())
+					return
+				}
+			}
+
+			newValue := utils.CurrentLocale.CanonicalFormula(valueInput.GetText())
+			if err := callbacks.DefineName(newName, newValue, commentInput.GetText()); err != nil {
+				if existing != nil && !strings.EqualFold(existing.Name, newName) {
+					callbacks.DefineName(existing.Name, existing.Value, existing.Comment)
+				}
+				ShowWarningModal(app, form, err.Error())
+				return
+			}
+
+			updateNameList(list, infoPanel, callbacks)
+			callbacks.RenderActiveSheet()
+			callbacks.MarkAsModified()
+
+			app.SetRoot(returnTo, true).SetFocus(list)
+		}).
+		AddButton("Cancel", func() {
+			app.SetRoot(returnTo, true).SetFocus(list)
+		})
+
+	form.SetBorder(true).
+		SetTitle(title).
+		SetBorderColor(tcell.ColorGreen).
+		SetTitleAlign(tview.AlignCenter)
+
+	app.SetRoot(form, true).SetFocus(form)
+}
+
+// confirmDeleteName asks before removing the selected name
+func confirmDeleteName(app *tview.Application, callbacks NameManagerCallbacks,
+	returnTo tview.Primitive, list *tview.List, infoPanel *tview.TextView) {
+
+	selected := selectedName(list, callbacks)
+	if selected == nil {
+		return
+	}
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf(
+			"[red::b]DELETE NAME[::-]\n\n"+
+				"Delete [yellow]'%s'[-]?\n\n"+
+				"Formulas using it will show #NAME?.",
+			selected.Name,
+		)).
+		AddButtons([]string{"X Delete", "x Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if strings.Contains(buttonLabel, "Delete") {
+				if err := callbacks.DeleteName(selected.Name); err != nil {
+					ShowWarningModal(app, returnTo, err.Error())
+					return
+				}
+
+				if list.GetCurrentItem() > 0 {
+					list.SetCurrentItem(list.GetCurrentItem() - 1)
+				}
+				updateNameList(list, infoPanel, callbacks)
+				callbacks.RenderActiveSheet()
+				callbacks.MarkAsModified()
+			}
+			app.SetRoot(returnTo, true).SetFocus(list)
+		})
+
+	modal.SetBackgroundColor(tcell.ColorDarkRed).
+		SetBorderColor(tcell.ColorRed)
+
+	app.SetRoot(modal, true).SetFocus(modal)
+}
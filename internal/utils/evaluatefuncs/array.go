@@ -0,0 +1,382 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// array.go provides array values and the dynamic array functions whose results spill into neighbouring cells
+
+package evaluatefuncs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxArrayCells bounds the arrays functions build, far beyond what a sheet can show but short of exhausting memory
+const maxArrayCells = 1 << 22
+
+// Array is a block of values computed by a formula, like the result of SORT or of A1:A5*2.
+// A formula resulting in an array spills it into the cells right and below of its own.
+type Array [][]any
+
+func (a Array) Dims() (int, int) {
+	if len(a) == 0 {
+		return 0, 0
+	}
+	return len(a), len(a[0])
+}
+
+func (a Array) At(row, col int) any {
+	if row < 0 || row >= len(a) || col < 0 || col >= len(a[row]) {
+		return nil
+	}
+	return a[row][col]
+}
+
+func (a Array) Each(fn func(row, col int, value any) bool) {
+	for r, values := range a {
+		for c, v := range values {
+			if v == nil {
+				continue
+			}
+			if !fn(r, c, v) {
+				return
+			}
+		}
+	}
+}
+
+// Trimmer is a range which can leave out the empty rows and columns past the filled part of its sheet,
+// so that a whole column like A:A used as an array is only as long as the data
+type Trimmer interface {
+	Trim() Range
+}
+
+// newArray allocates a rows x cols array sharing one backing slice
+func newArray(rows, cols int) (Array, error) {
+	if rows*cols > maxArrayCells {
+		return nil, newError(ErrNum, "the array of %d x %d values is too large", rows, cols)
+	}
+	backing := make([]any, rows*cols)
+	a := make(Array, rows)
+	for r := range a {
+		a[r] = backing[r*cols : (r+1)*cols]
+	}
+	return a, nil
+}
+
+// toArray reads every value of an argument, blanks included, into an array
+func toArray(v any) (Array, error) {
+	r := asRange(v)
+	if a, ok := r.(Array); ok {
+		return a, nil
+	}
+	if t, ok := r.(Trimmer); ok {
+		r = t.Trim()
+	}
+	rows, cols := r.Dims()
+	a, err := newArray(rows, cols)
+	if err != nil {
+		return nil, err
+	}
+	r.Each(func(row, col int, value any) bool {
+		a[row][col] = value
+		return true
+	})
+	return a, nil
+}
+
+// isArray reports whether v holds more than one value, so operators must apply to each of them
+func isArray(v any) bool {
+	r, ok := v.(Range)
+	if !ok {
+		return false
+	}
+	rows, cols := r.Dims()
+	return rows != 1 || cols != 1
+}
+
+// elementwise lets a scalar operator take arrays: it applies to each pair of values, a single row or
+// column being repeated to match the other operand and missing values giving #N/A, like Excel does
+func elementwise(fn ExprFunction) ExprFunction {
+	return func(args ...any) (any, error) {
+		if !isArray(args[0]) && !isArray(args[1]) {
+			return fn(args...)
+		}
+
+		a, err := toArray(args[0])
+		if err != nil {
+			return ErrorOf(err), nil
+		}
+		b, err := toArray(args[1])
+		if err != nil {
+			return ErrorOf(err), nil
+		}
+		aRows, aCols := a.Dims()
+		bRows, bCols := b.Dims()
+		result, err := newArray(max(aRows, bRows), max(aCols, bCols))
+		if err != nil {
+			return ErrorOf(err), nil
+		}
+
+		pick := func(a Array, rows, cols, row, col int) (any, bool) {
+			if rows == 1 {
+				row = 0
+			}
+			if cols == 1 {
+				col = 0
+			}
+			if row >= rows || col >= cols {
+				return nil, false
+			}
+			return a[row][col], true
+		}
+		for row := range result {
+			for col := range result[row] {
+				x, okX := pick(a, aRows, aCols, row, col)
+				y, okY := pick(b, bRows, bCols, row, col)
+				if !okX || !okY {
+					result[row][col] = ErrNA
+					continue
+				}
+				value, err := fn(x, y)
+				if err != nil {
+					value = ErrorOf(err)
+				}
+				result[row][col] = value
+			}
+		}
+		return result, nil
+	}
+}
+
+// transpose swaps the rows and columns of an array
+func transpose(a Array) Array {
+	rows, cols := a.Dims()
+	t, _ := newArray(cols, rows)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			t[c][r] = a[r][c]
+		}
+	}
+	return t
+}
+
+// rowKey identifies a row of values for UNIQUE, text compared without regard to case
+func rowKey(values []any) string {
+	var key strings.Builder
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			v = strings.ToLower(s)
+		}
+		fmt.Fprintf(&key, "%T:%v|", v, v)
+	}
+	return key.String()
+}
+
+func ArrayFunctions() map[string]ExprFunction {
+	return map[string]ExprFunction{
+		"SEQUENCE": func(args ...any) (any, error) {
+			if err := validateArgs("SEQUENCE", args, 1, 4); err != nil {
+				return nil, err
+			}
+			p := []float64{0, 1, 1, 1}
+			for i, arg := range args {
+				if arg == nil || arg == "" {
+					continue
+				}
+				f, err := toFloat(arg)
+				if err != nil {
+					return nil, fmt.Errorf("SEQUENCE: %v", err)
+				}
+				p[i] = f
+			}
+			rows, cols := int(p[0]), int(p[1])
+			if rows < 1 || cols < 1 {
+				return nil, newError(ErrValue, "SEQUENCE: rows and columns must be at least 1")
+			}
+			result, err := newArray(rows, cols)
+			if err != nil {
+				return nil, err
+			}
+			for r := range result {
+				for c := range result[r] {
+					result[r][c] = p[2] + float64(r*cols+c)*p[3]
+				}
+			}
+			return result, nil
+		},
+
+		"TRANSPOSE": func(args ...any) (any, error) {
+			if err := validateArgs("TRANSPOSE", args, 1, 1); err != nil {
+				return nil, err
+			}
+			a, err := toArray(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return transpose(a), nil
+		},
+
+		"SORT": func(args ...any) (any, error) {
+			if err := validateArgs("SORT", args, 1, 4); err != nil {
+				return nil, err
+			}
+			a, err := toArray(args[0])
+			if err != nil {
+				return nil, err
+			}
+			index, descending, byCol := 1, false, false
+			if len(args) > 1 && args[1] != "" {
+				if index, err = toIndex(args[1]); err != nil {
+					return nil, err
+				}
+			}
+			if len(args) > 2 && args[2] != "" {
+				order, err := toIndex(args[2])
+				if err != nil {
+					return nil, err
+				}
+				if order != 1 && order != -1 {
+					return nil, newError(ErrValue, "SORT: sort order must be 1 or -1")
+				}
+				descending = order == -1
+			}
+			if len(args) > 3 {
+				if byCol, err = toBool(args[3]); err != nil {
+					return nil, err
+				}
+			}
+
+			if byCol {
+				a = transpose(a)
+			}
+			if _, cols := a.Dims(); index < 1 || index > cols {
+				return nil, newError(ErrValue, "SORT: sort index is outside of the array")
+			}
+			sorted := append(Array(nil), a...)
+			sort.SliceStable(sorted, func(i, j int) bool {
+				x, y := sorted[i][index-1], sorted[j][index-1]
+				// Blanks go last whichever the order
+				if x == nil || y == nil {
+					return y == nil && x != nil
+				}
+				if descending {
+					return compareValues(x, y) > 0
+				}
+				return compareValues(x, y) < 0
+			})
+			if byCol {
+				return transpose(sorted), nil
+			}
+			return sorted, nil
+		},
+
+		"FILTER": func(args ...any) (any, error) {
+			if err := validateArgs("FILTER", args, 2, 3); err != nil {
+				return nil, err
+			}
+			a, err := toArray(args[0])
+			if err != nil {
+				return nil, err
+			}
+			include, err := toArray(args[1])
+			if err != nil {
+				return nil, err
+			}
+			rows, cols := a.Dims()
+			incRows, incCols := include.Dims()
+
+			byRow := incCols == 1 && incRows == rows
+			if !byRow && (incRows != 1 || incCols != cols) {
+				return nil, newError(ErrValue, "FILTER: include must be one column as tall or one row as wide as the array")
+			}
+
+			keep := make([]int, 0)
+			for i := 0; i < max(incRows, incCols); i++ {
+				flag := include.At(i, 0)
+				if !byRow {
+					flag = include.At(0, i)
+				}
+				if ev, ok := flag.(ErrorValue); ok {
+					return nil, ev
+				}
+				ok, err := toBool(flag)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					keep = append(keep, i)
+				}
+			}
+
+			if len(keep) == 0 {
+				if len(args) == 3 {
+					return args[2], nil
+				}
+				return nil, newError(ErrCalc, "FILTER: no values match")
+			}
+			if byRow {
+				result := make(Array, len(keep))
+				for i, r := range keep {
+					result[i] = a[r]
+				}
+				return result, nil
+			}
+			result, _ := newArray(rows, len(keep))
+			for r := range result {
+				for i, c := range keep {
+					result[r][i] = a[r][c]
+				}
+			}
+			return result, nil
+		},
+
+		"UNIQUE": func(args ...any) (any, error) {
+			if err := validateArgs("UNIQUE", args, 1, 3); err != nil {
+				return nil, err
+			}
+			a, err := toArray(args[0])
+			if err != nil {
+				return nil, err
+			}
+			byCol, exactlyOnce := false, false
+			if len(args) > 1 {
+				if byCol, err = toBool(args[1]); err != nil {
+					return nil, err
+				}
+			}
+			if len(args) > 2 {
+				if exactlyOnce, err = toBool(args[2]); err != nil {
+					return nil, err
+				}
+			}
+
+			if byCol {
+				a = transpose(a)
+			}
+			counts := make(map[string]int)
+			for _, row := range a {
+				counts[rowKey(row)]++
+			}
+			var result Array
+			seen := make(map[string]bool)
+			for _, row := range a {
+				key := rowKey(row)
+				if seen[key] || (exactlyOnce && counts[key] > 1) {
+					continue
+				}
+				seen[key] = true
+				result = append(result, row)
+			}
+			if len(result) == 0 {
+				return nil, newError(ErrCalc, "UNIQUE: no value occurs exactly once")
+			}
+			if byCol {
+				return transpose(result), nil
+			}
+			return result, nil
+		},
+	}
+}
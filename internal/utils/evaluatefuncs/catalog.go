@@ -0,0 +1,361 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// catalog.go lists the description of every built-in function
+
+package evaluatefuncs
+
+// fn describes a function of the catalog
+func fn(name, category, description, example string, args ...ArgInfo) FunctionInfo {
+	return FunctionInfo{Name: name, Category: category, Description: description, Example: example, Args: args}
+}
+
+// volatile marks a function whose result changes without its arguments changing
+func volatile(f FunctionInfo) FunctionInfo {
+	f.Volatile = true
+	return f
+}
+
+// number is the single argument of most math functions
+var number = arg("number", "the value to work on")
+
+var catalog = []FunctionInfo{
+	// Trigonometry, angles in radians
+	fn("SIN", CategoryMath, "Sine of an angle in radians", "SIN(PI()/2)", number),
+	fn("COS", CategoryMath, "Cosine of an angle in radians", "COS(0)", number),
+	fn("TAN", CategoryMath, "Tangent of an angle in radians", "TAN(PI()/4)", number),
+	fn("CTAN", CategoryMath, "Cotangent of an angle in radians", "CTAN(1)", number),
+	fn("SEC", CategoryMath, "Secant of an angle in radians", "SEC(0)", number),
+	fn("CSEC", CategoryMath, "Cosecant of an angle in radians", "CSEC(PI()/2)", number),
+	fn("ASIN", CategoryMath, "Angle in radians whose sine is the number", "ASIN(1)", number),
+	fn("ACOS", CategoryMath, "Angle in radians whose cosine is the number", "ACOS(0)", number),
+	fn("ATAN", CategoryMath, "Angle in radians whose tangent is the number", "ATAN(1)", number),
+	fn("ATAN2", CategoryMath, "Angle in radians of the point (x, y) from the x axis", "ATAN2(1, 1)",
+		arg("y", "the y coordinate"), arg("x", "the x coordinate")),
+	fn("ACTAN", CategoryMath, "Angle in radians whose cotangent is the number", "ACTAN(1)", number),
+	fn("ASEC", CategoryMath, "Angle in radians whose secant is the number", "ASEC(2)", number),
+	fn("ACSC", CategoryMath, "Angle in radians whose cosecant is the number", "ACSC(2)", number),
+	fn("RAD", CategoryMath, "Converts degrees to radians", "RAD(180)", arg("degrees", "the angle in degrees")),
+	fn("DEG", CategoryMath, "Converts radians to degrees", "DEG(PI())", arg("radians", "the angle in radians")),
+	fn("SINH", CategoryMath, "Hyperbolic sine", "SINH(1)", number),
+	fn("COSH", CategoryMath, "Hyperbolic cosine", "COSH(1)", number),
+	fn("TANH", CategoryMath, "Hyperbolic tangent", "TANH(1)", number),
+	fn("CTANH", CategoryMath, "Hyperbolic cotangent", "CTANH(1)", number),
+	fn("SECH", CategoryMath, "Hyperbolic secant", "SECH(1)", number),
+	fn("CSCH", CategoryMath, "Hyperbolic cosecant", "CSCH(1)", number),
+	fn("ASINH", CategoryMath, "Inverse hyperbolic sine", "ASINH(1)", number),
+	fn("ACOSH", CategoryMath, "Inverse hyperbolic cosine", "ACOSH(2)", number),
+	fn("ATANH", CategoryMath, "Inverse hyperbolic tangent", "ATANH(0.5)", number),
+	fn("ASECH", CategoryMath, "Inverse hyperbolic secant", "ASECH(0.5)", number),
+	fn("ACSCH", CategoryMath, "Inverse hyperbolic cosecant", "ACSCH(2)", number),
+	fn("ACOTH", CategoryMath, "Inverse hyperbolic cotangent", "ACOTH(2)", number),
+
+	// Powers, logarithms and rounding
+	fn("EXP", CategoryMath, "e raised to the power of the number", "EXP(1)", number),
+	fn("LOG", CategoryMath, "Natural logarithm", "LOG(E())", number),
+	fn("LOG10", CategoryMath, "Base 10 logarithm", "LOG10(1000)", number),
+	fn("LOG2", CategoryMath, "Base 2 logarithm", "LOG2(8)", number),
+	fn("SQRT", CategoryMath, "Square root", "SQRT(16)", number),
+	fn("CBRT", CategoryMath, "Cube root", "CBRT(27)", number),
+	fn("POW", CategoryMath, "A number raised to a power", "POW(2, 10)",
+		arg("base", "the number to raise"), arg("exponent", "the power to raise it to")),
+	fn("ABS", CategoryMath, "Absolute value", "ABS(-5)", number),
+	fn("SIGN", CategoryMath, "1 for a positive number, -1 for a negative one, 0 for zero", "SIGN(A1)", number),
+	fn("CEIL", CategoryMath, "Rounds up to the next integer", "CEIL(4.2)", number),
+	fn("FLOOR", CategoryMath, "Rounds down to the previous integer", "FLOOR(4.8)", number),
+	fn("ROUND", CategoryMath, "Rounds to the nearest integer", "ROUND(4.5)", number),
+	fn("ROUNDTO", CategoryMath, "Rounds to a number of decimal places", "ROUNDTO(3.14159, 2)",
+		number, arg("places", "how many decimal places to keep")),
+	fn("TRUNC", CategoryMath, "Drops the decimal part", "TRUNC(-4.7)", number),
+	fn("MIN", CategoryMath, "Smallest of the numbers", "MIN(A1:A10)",
+		arg("number1", "a number or range"), more("number2", "more numbers or ranges")),
+	fn("MAX", CategoryMath, "Largest of the numbers", "MAX(A1:A10, 0)",
+		arg("number1", "a number or range"), more("number2", "more numbers or ranges")),
+	fn("CLAMP", CategoryMath, "Keeps a number between a lower and an upper bound", "CLAMP(A1, 0, 100)",
+		number, arg("min", "the lower bound"), arg("max", "the upper bound")),
+	fn("LERP", CategoryMath, "Linear interpolation from a to b", "LERP(0, 10, 0.25)",
+		arg("a", "the value at t = 0"), arg("b", "the value at t = 1"), arg("t", "how far between a and b")),
+	fn("HYPOT", CategoryMath, "Length of the hypotenuse, sqrt(x² + y²)", "HYPOT(3, 4)",
+		arg("x", "the first side"), arg("y", "the second side")),
+	fn("MOD", CategoryMath, "Remainder of a division, with the sign of the divisor", "MOD(10, 3)",
+		arg("number", "the dividend"), arg("divisor", "the number to divide by")),
+	fn("REMAINDER", CategoryMath, "IEEE remainder of a division, rounding the quotient to the nearest integer", "REMAINDER(10, 3)",
+		arg("number", "the dividend"), arg("divisor", "the number to divide by")),
+	fn("FACTORIAL", CategoryMath, "Product of the integers from 1 to the number", "FACTORIAL(5)", number),
+	fn("GCD", CategoryMath, "Greatest common divisor of two integers", "GCD(12, 18)",
+		arg("number1", "the first integer"), arg("number2", "the second integer")),
+	fn("LCM", CategoryMath, "Least common multiple of two integers", "LCM(4, 6)",
+		arg("number1", "the first integer"), arg("number2", "the second integer")),
+	volatile(fn("RAND", CategoryMath, "Random number from 0 up to but not including 1", "RAND()")),
+	volatile(fn("RANDBETWEEN", CategoryMath, "Random integer between two integers, both included", "RANDBETWEEN(1, 6)",
+		arg("bottom", "the smallest integer it can return"), arg("top", "the largest integer it can return"))),
+
+	// Matrices
+	fn("MMULT", CategoryMath, "Matrix product of two arrays", "MMULT(A1:B3, D1:E2)",
+		arg("array1", "the first matrix"), arg("array2", "the second matrix, with as many rows as the first has columns")),
+	fn("MINVERSE", CategoryMath, "Inverse of a square matrix", "MINVERSE(A1:C3)", arg("array", "a square matrix of numbers")),
+	fn("MDETERM", CategoryMath, "Determinant of a square matrix", "MDETERM(A1:C3)", arg("array", "a square matrix of numbers")),
+	fn("SUMPRODUCT", CategoryMath, "Sum of the products of the matching values of arrays", "SUMPRODUCT(B2:B10, C2:C10)",
+		arg("array1", "the first range or array"), more("array2", "more arrays of the same size")),
+
+	// Engineering
+	fn("ERF", CategoryEngineering, "Error function", "ERF(1)", number),
+	fn("ERFC", CategoryEngineering, "Complementary error function, 1 - ERF", "ERFC(1)", number),
+	fn("GAMMA", CategoryEngineering, "Gamma function", "GAMMA(5)", number),
+	fn("J0", CategoryEngineering, "Bessel function of the first kind, order 0", "J0(1)", arg("x", "the value to work on")),
+	fn("J1", CategoryEngineering, "Bessel function of the first kind, order 1", "J1(1)", arg("x", "the value to work on")),
+	fn("YN", CategoryEngineering, "Bessel function of the second kind, order n", "YN(2, 1.5)",
+		arg("n", "the order"), arg("x", "the value to work on")),
+
+	// Bitwise
+	fn("BITAND", CategoryBitwise, "Bitwise AND of two integers", "BITAND(12, 10)",
+		arg("number1", "the first integer"), arg("number2", "the second integer")),
+	fn("BITOR", CategoryBitwise, "Bitwise OR of two integers", "BITOR(12, 10)",
+		arg("number1", "the first integer"), arg("number2", "the second integer")),
+	fn("BITXOR", CategoryBitwise, "Bitwise exclusive OR of two integers", "BITXOR(12, 10)",
+		arg("number1", "the first integer"), arg("number2", "the second integer")),
+	fn("BITSHIFTLEFT", CategoryBitwise, "Shifts the bits of an integer left", "BITSHIFTLEFT(1, 4)",
+		number, arg("shift", "how many bits to shift by")),
+	fn("BITSHIFTRIGHT", CategoryBitwise, "Shifts the bits of an integer right", "BITSHIFTRIGHT(16, 2)",
+		number, arg("shift", "how many bits to shift by")),
+
+	// Constants
+	fn("PI", CategoryConstants, "The number π, 3.14159...", "2*PI()*A1"),
+	fn("E", CategoryConstants, "Euler's number e, 2.71828...", "E()^2"),
+	fn("PHI", CategoryConstants, "The golden ratio φ, 1.61803...", "A1*PHI()"),
+	fn("INF", CategoryConstants, "Positive infinity, shown as #NUM!", "INF()"),
+	fn("NAN", CategoryConstants, "Not a number, shown as #NUM!", "NAN()"),
+
+	// Statistical
+	fn("SUM", CategoryStatistical, "Adds the numbers", "SUM(A1:A10, 5)",
+		arg("number1", "a number or range"), more("number2", "more numbers or ranges")),
+	fn("AVG", CategoryStatistical, "Arithmetic mean of the numbers", "AVG(A1:A10)",
+		arg("number1", "a number or range"), more("number2", "more numbers or ranges")),
+	fn("COUNT", CategoryStatistical, "How many of the values are numbers", "COUNT(A:A)",
+		arg("value1", "a value or range"), more("value2", "more values or ranges")),
+	fn("PRODUCT", CategoryStatistical, "Multiplies the numbers", "PRODUCT(A1:A3)",
+		arg("number1", "a number or range"), more("number2", "more numbers or ranges")),
+	fn("MEDIAN", CategoryStatistical, "Middle value of the numbers", "MEDIAN(A1:A10)",
+		arg("number1", "a number or range"), more("number2", "more numbers or ranges")),
+	fn("MODE", CategoryStatistical, "Most frequent of the numbers", "MODE(A1:A10)",
+		arg("number1", "a number or range"), more("number2", "more numbers or ranges")),
+	fn("VAR.S", CategoryStatistical, "Variance of a sample", "VAR.S(B2:B50)",
+		arg("number1", "a number or range"), more("number2", "more numbers or ranges")),
+	fn("VAR.P", CategoryStatistical, "Variance of a whole population", "VAR.P(B2:B50)",
+		arg("number1", "a number or range"), more("number2", "more numbers or ranges")),
+	fn("STDEV.S", CategoryStatistical, "Standard deviation of a sample", "STDEV.S(B2:B50)",
+		arg("number1", "a number or range"), more("number2", "more numbers or ranges")),
+	fn("STDEV.P", CategoryStatistical, "Standard deviation of a whole population", "STDEV.P(B2:B50)",
+		arg("number1", "a number or range"), more("number2", "more numbers or ranges")),
+	fn("PERCENTILE", CategoryStatistical, "Value below which a fraction of the data falls, interpolating", "PERCENTILE(B:B, 0.9)",
+		arg("array", "the data"), arg("k", "the fraction, from 0 to 1")),
+	fn("QUARTILE", CategoryStatistical, "Quartile of the data: 0 minimum, 1 to 3 quartiles, 4 maximum", "QUARTILE(B:B, 1)",
+		arg("array", "the data"), arg("quart", "which quartile, 0 to 4")),
+	fn("RANK", CategoryStatistical, "Position of a number in a list, 1 for the largest unless ascending", "RANK(B2, B2:B20)",
+		arg("number", "the number to rank"), arg("ref", "the list of numbers"), opt("order", "0 or omitted for descending, 1 for ascending")),
+	fn("LARGE", CategoryStatistical, "k-th largest value", "LARGE(B2:B20, 2)",
+		arg("array", "the data"), arg("k", "the position from the largest, 1 for the largest")),
+	fn("SMALL", CategoryStatistical, "k-th smallest value", "SMALL(B2:B20, 1)",
+		arg("array", "the data"), arg("k", "the position from the smallest, 1 for the smallest")),
+	fn("CORREL", CategoryStatistical, "Correlation coefficient of two data sets", "CORREL(A2:A20, B2:B20)",
+		arg("array1", "the first data set"), arg("array2", "the second data set, as large as the first")),
+	fn("COVAR", CategoryStatistical, "Population covariance of two data sets", "COVAR(A2:A20, B2:B20)",
+		arg("array1", "the first data set"), arg("array2", "the second data set, as large as the first")),
+	fn("SLOPE", CategoryStatistical, "Slope of the linear regression line", "SLOPE(B2:B13, A2:A13)",
+		arg("known_ys", "the dependent values"), arg("known_xs", "the independent values")),
+	fn("INTERCEPT", CategoryStatistical, "Where the linear regression line crosses the y axis", "INTERCEPT(B2:B13, A2:A13)",
+		arg("known_ys", "the dependent values"), arg("known_xs", "the independent values")),
+	fn("FORECAST", CategoryStatistical, "Value of the linear regression line at x", "FORECAST(13, B2:B13, A2:A13)",
+		arg("x", "where to forecast"), arg("known_ys", "the dependent values"), arg("known_xs", "the independent values")),
+	fn("TREND", CategoryStatistical, "Value of the linear trend at new_x, optionally through the origin", "TREND(B2:B13, A2:A13, 13)",
+		arg("known_ys", "the dependent values"), arg("known_xs", "the independent values"), arg("new_x", "where to read the trend"),
+		opt("const", "FALSE to force the line through the origin")),
+	fn("NORM.DIST", CategoryStatistical, "Normal distribution, cumulative or density", "NORM.DIST(1, 0, 1, TRUE)",
+		arg("x", "the value"), arg("mean", "the mean"), arg("standard_dev", "the standard deviation"),
+		arg("cumulative", "TRUE for the distribution, FALSE for the density")),
+	fn("NORM.INV", CategoryStatistical, "Inverse of the cumulative normal distribution", "NORM.INV(0.95, 0, 1)",
+		arg("probability", "the probability"), arg("mean", "the mean"), arg("standard_dev", "the standard deviation")),
+	fn("T.DIST", CategoryStatistical, "Student's t distribution, cumulative or density", "T.DIST(2, 10, TRUE)",
+		arg("x", "the value"), arg("deg_freedom", "the degrees of freedom"), arg("cumulative", "TRUE for the distribution, FALSE for the density")),
+
+	// Conditional aggregates
+	fn("SUMIF", CategoryConditional, "Adds the values whose cell in range meets the criteria", `SUMIF(C:C, "east", B:B)`,
+		arg("range", "the cells to test"), arg("criteria", `a value, a comparison like ">10", or a pattern like "ap*"`),
+		opt("sum_range", "the cells to add, range itself when omitted")),
+	fn("SUMIFS", CategoryConditional, "Adds the values meeting every criteria", `SUMIFS(B:B, C:C, "east", D:D, ">=2024-01-01")`,
+		arg("sum_range", "the cells to add"), arg("criteria_range1", "the cells to test"), arg("criteria1", "the condition they must meet"),
+		more("criteria_range2", "more cells to test"), more("criteria2", "the condition they must meet")),
+	fn("COUNTIF", CategoryConditional, "Counts the cells meeting the criteria", `COUNTIF(A:A, ">=20")`,
+		arg("range", "the cells to test"), arg("criteria", `a value, a comparison like ">10", or a pattern like "ap*"`)),
+	fn("COUNTIFS", CategoryConditional, "Counts the rows meeting every criteria", `COUNTIFS(A1:A50, "ap*", B1:B50, ">=20")`,
+		arg("criteria_range1", "the cells to test"), arg("criteria1", "the condition they must meet"),
+		more("criteria_range2", "more cells to test"), more("criteria2", "the condition they must meet")),
+	fn("AVERAGEIF", CategoryConditional, "Mean of the values whose cell in range meets the criteria", `AVERAGEIF(B1:B50, "<>0")`,
+		arg("range", "the cells to test"), arg("criteria", `a value, a comparison like ">10", or a pattern like "ap*"`),
+		opt("average_range", "the cells to average, range itself when omitted")),
+	fn("AVERAGEIFS", CategoryConditional, "Mean of the values meeting every criteria", `AVERAGEIFS(B:B, C:C, "east")`,
+		arg("average_range", "the cells to average"), arg("criteria_range1", "the cells to test"), arg("criteria1", "the condition they must meet"),
+		more("criteria_range2", "more cells to test"), more("criteria2", "the condition they must meet")),
+	fn("MAXIFS", CategoryConditional, "Largest of the values meeting every criteria", `MAXIFS(B:B, C:C, "east")`,
+		arg("max_range", "the cells to compare"), arg("criteria_range1", "the cells to test"), arg("criteria1", "the condition they must meet"),
+		more("criteria_range2", "more cells to test"), more("criteria2", "the condition they must meet")),
+	fn("MINIFS", CategoryConditional, "Smallest of the values meeting every criteria", `MINIFS(B:B, C:C, "east")`,
+		arg("min_range", "the cells to compare"), arg("criteria_range1", "the cells to test"), arg("criteria1", "the condition they must meet"),
+		more("criteria_range2", "more cells to test"), more("criteria2", "the condition they must meet")),
+
+	// Financial
+	fn("PMT", CategoryFinancial, "Payment per period of a loan or annuity", "PMT(0.05/12, 360, 200000)",
+		arg("rate", "the interest rate per period"), arg("nper", "the number of periods"), arg("pv", "the present value, the loan amount"),
+		opt("fv", "the value left after the last payment, 0 by default"), opt("type", "1 when paying at the start of each period")),
+	fn("PV", CategoryFinancial, "Present value of a series of payments", "PV(0.04, 10, -1000)",
+		arg("rate", "the interest rate per period"), arg("nper", "the number of periods"), arg("pmt", "the payment per period"),
+		opt("fv", "the value left after the last payment, 0 by default"), opt("type", "1 when paying at the start of each period")),
+	fn("FV", CategoryFinancial, "Future value of an investment", "FV(0.04, 10, -1000)",
+		arg("rate", "the interest rate per period"), arg("nper", "the number of periods"), arg("pmt", "the payment per period"),
+		opt("pv", "the value at the start, 0 by default"), opt("type", "1 when paying at the start of each period")),
+	fn("NPER", CategoryFinancial, "Number of periods needed to pay off a loan", "NPER(0.01, -100, 1000)",
+		arg("rate", "the interest rate per period"), arg("pmt", "the payment per period"), arg("pv", "the present value"),
+		opt("fv", "the value left after the last payment, 0 by default"), opt("type", "1 when paying at the start of each period")),
+	fn("RATE", CategoryFinancial, "Interest rate per period of an annuity, searched from a guess", "RATE(360, -1073.64, 200000)*12",
+		arg("nper", "the number of periods"), arg("pmt", "the payment per period"), arg("pv", "the present value"),
+		opt("fv", "the value left after the last payment, 0 by default"), opt("type", "1 when paying at the start of each period"),
+		opt("guess", "where the search starts, 10% by default")),
+	fn("NPV", CategoryFinancial, "Net present value of periodic cash flows", "NPV(0.1, B2:B8)",
+		arg("rate", "the discount rate per period"), arg("value1", "a cash flow or range of them"), more("value2", "more cash flows")),
+	fn("IRR", CategoryFinancial, "Internal rate of return of periodic cash flows", "IRR(B2:B8)",
+		arg("values", "the cash flows, with at least one payment and one receipt"), opt("guess", "where the search starts, 10% by default")),
+	fn("XNPV", CategoryFinancial, "Net present value of cash flows on given dates", "XNPV(0.1, B2:B8, A2:A8)",
+		arg("rate", "the yearly discount rate"), arg("values", "the cash flows"), arg("dates", "the date of each cash flow")),
+	fn("XIRR", CategoryFinancial, "Internal rate of return of cash flows on given dates", "XIRR(B2:B8, A2:A8)",
+		arg("values", "the cash flows"), arg("dates", "the date of each cash flow"), opt("guess", "where the search starts, 10% by default")),
+
+	// Text
+	fn("LEFT", CategoryText, "First characters of a text", `LEFT("GoSheet", 2)`,
+		arg("text", "the text"), arg("num_chars", "how many characters to take")),
+	fn("RIGHT", CategoryText, "Last characters of a text", `RIGHT("GoSheet", 5)`,
+		arg("text", "the text"), arg("num_chars", "how many characters to take")),
+	fn("MID", CategoryText, "Characters from the middle of a text", `MID("GoSheet", 3, 3)`,
+		arg("text", "the text"), arg("start_num", "the position of the first character, from 1"), arg("num_chars", "how many characters to take")),
+	fn("UPPER", CategoryText, "Text in upper case", "UPPER(A1)", arg("text", "the text")),
+	fn("LOWER", CategoryText, "Text in lower case", "LOWER(A1)", arg("text", "the text")),
+	fn("PROPER", CategoryText, "Text with each word capitalized", "PROPER(A1)", arg("text", "the text")),
+	fn("TRIM", CategoryText, "Text without leading, trailing and repeated spaces", "TRIM(A1)", arg("text", "the text")),
+	fn("FIND", CategoryText, "Position of a text inside another, -1 when not found", `FIND("-", A1)`,
+		arg("find_text", "the text to look for"), arg("within_text", "the text to look in"), opt("start_num", "where to start looking, from 1")),
+	fn("SUBSTITUTE", CategoryText, "Replaces occurrences of a text", `SUBSTITUTE(A1, "-", "/")`,
+		arg("text", "the text"), arg("old_text", "the text to replace"), arg("new_text", "the replacement"),
+		opt("instance_num", "which occurrence to replace, all when omitted")),
+	fn("LEN", CategoryText, "Number of characters of a text", "LEN(A1)", arg("text", "the text")),
+	fn("CONCAT", CategoryText, "Joins texts and the values of ranges", `CONCAT(A1, " ", B1)`,
+		arg("text1", "a text or range"), more("text2", "more texts or ranges")),
+
+	// Date & time
+	volatile(fn("NOW", CategoryDateTime, "Current date and time", "NOW()")),
+	volatile(fn("TODAY", CategoryDateTime, "Current date", "TODAY()")),
+	fn("DATE", CategoryDateTime, "Date from a year, month and day", "DATE(2025, 12, 31)",
+		arg("year", "the year"), arg("month", "the month, 1 to 12"), arg("day", "the day of the month")),
+	fn("TIME", CategoryDateTime, "Time from hours, minutes and seconds", "TIME(14, 30)",
+		arg("hour", "the hour, 0 to 23"), arg("minute", "the minute"), opt("second", "the second, 0 by default")),
+	fn("YEAR", CategoryDateTime, "Year of a date", "YEAR(A1)", arg("date", "the date")),
+	fn("MONTH", CategoryDateTime, "Month of a date, 1 to 12", "MONTH(A1)", arg("date", "the date")),
+	fn("DAY", CategoryDateTime, "Day of the month of a date", "DAY(A1)", arg("date", "the date")),
+	fn("HOUR", CategoryDateTime, "Hour of a time, 0 to 23", "HOUR(A1)", arg("time", "the time or date and time")),
+	fn("MINUTE", CategoryDateTime, "Minute of a time", "MINUTE(A1)", arg("time", "the time or date and time")),
+	fn("SECOND", CategoryDateTime, "Second of a time", "SECOND(A1)", arg("time", "the time or date and time")),
+	fn("WEEKDAY", CategoryDateTime, "Day of the week, 1 for Sunday to 7 for Saturday", "WEEKDAY(A1)", arg("date", "the date")),
+	fn("DATEDIFF", CategoryDateTime, "Days from the first date to the second", "DATEDIFF(A1, B1)",
+		arg("start_date", "the first date"), arg("end_date", "the second date")),
+	fn("DATEADD", CategoryDateTime, "Date a number of days later", "DATEADD(A1, 30)",
+		arg("date", "the date"), arg("days", "the days to add, negative to go back")),
+
+	// Logical
+	fn("IF", CategoryLogical, "One value when a condition holds, another when not", `IF(A1 > 10, "high", "low")`,
+		arg("condition", "the test"), arg("value_if_true", "the result when it holds"), arg("value_if_false", "the result when it does not")),
+	fn("IFS", CategoryLogical, "Value of the first condition which holds", `IFS(A1 > 90, "A", A1 > 80, "B", TRUE, "C")`,
+		arg("condition1", "the first test"), arg("value1", "the result when it holds"),
+		more("condition2", "the next test"), more("value2", "the result when it holds")),
+	fn("AND", CategoryLogical, "TRUE when every condition holds", "AND(A1 > 0, A1 < 10)",
+		arg("logical1", "a condition"), more("logical2", "more conditions")),
+	fn("OR", CategoryLogical, "TRUE when any condition holds", "OR(A1 < 0, A1 > 10)",
+		arg("logical1", "a condition"), more("logical2", "more conditions")),
+	fn("NOT", CategoryLogical, "Reverses a condition", "NOT(A1 > 0)", arg("logical", "the condition")),
+	fn("XOR", CategoryLogical, "TRUE when an odd number of the conditions hold", "XOR(A1 > 0, B1 > 0)",
+		arg("logical1", "a condition"), more("logical2", "more conditions")),
+
+	// Information
+	fn("CHOOSE", CategoryInformation, "Value at a position of the list", `CHOOSE(2, "a", "b", "c")`,
+		arg("index", "the position, from 1"), arg("value1", "the first value"), more("value2", "more values")),
+	fn("ISNUMBER", CategoryInformation, "TRUE when the value is a number", "ISNUMBER(A1)", arg("value", "the value to check")),
+	fn("ISTEXT", CategoryInformation, "TRUE when the value is text", "ISTEXT(A1)", arg("value", "the value to check")),
+	fn("ISBLANK", CategoryInformation, "TRUE when the cell is empty", "ISBLANK(A1)", arg("value", "the value to check")),
+	fn("ISFORMULA", CategoryInformation, "TRUE when the cell holds a formula", "ISFORMULA(B2)", arg("reference", "the cell to check")),
+	fn("TYPE", CategoryInformation, "Kind of a value: 1 number or empty, 2 text, 4 logical, 16 error, 64 array", "TYPE(A1)",
+		arg("value", "the value to check")),
+	volatile(fn("CELL", CategoryInformation, "Information about a cell: its address, row, column, contents, type, format or file", `CELL("address", B2)`,
+		arg("info_type", `"address", "row", "col", "contents", "type" (b blank, l text, v value), "format" or "filename"`),
+		opt("reference", "the cell, the one with the formula by default"))),
+
+	// Error handling
+	fn("IFERROR", CategoryErrors, "The value, or a fallback when it is an error", "IFERROR(A1/B1, 0)",
+		arg("value", "the value to check"), arg("value_if_error", "the result when it is an error")),
+	fn("IFNA", CategoryErrors, "The value, or a fallback when it is #N/A", `IFNA(VLOOKUP(E1, A:B, 2, FALSE), "missing")`,
+		arg("value", "the value to check"), arg("value_if_na", "the result when it is #N/A")),
+	fn("ISERROR", CategoryErrors, "TRUE when the value is an error", "ISERROR(A1)", arg("value", "the value to check")),
+	fn("ISNA", CategoryErrors, "TRUE when the value is #N/A", "ISNA(A1)", arg("value", "the value to check")),
+	fn("ERROR.TYPE", CategoryErrors, "Number of an error: 2 for #DIV/0!, 7 for #N/A, ...", "ERROR.TYPE(A1)", arg("error_val", "the error")),
+	fn("NA", CategoryErrors, "The #N/A error, for values not available", "NA()"),
+
+	// Lookup & reference
+	fn("VLOOKUP", CategoryLookup, "Looks a value up in the first column of a table and returns one of its row", `VLOOKUP("banana", A1:C10, 3, FALSE)`,
+		arg("lookup_value", "the value to find"), arg("table_array", "the table"), arg("col_index_num", "the column to return, from 1"),
+		opt("range_lookup", "FALSE for an exact match, TRUE or omitted for sorted data")),
+	fn("HLOOKUP", CategoryLookup, "Looks a value up in the first row of a table and returns one of its column", `HLOOKUP("Q2", A1:E5, 3, FALSE)`,
+		arg("lookup_value", "the value to find"), arg("table_array", "the table"), arg("row_index_num", "the row to return, from 1"),
+		opt("range_lookup", "FALSE for an exact match, TRUE or omitted for sorted data")),
+	fn("XLOOKUP", CategoryLookup, "Looks a value up in one range and returns the matching value of another", `XLOOKUP(E1, A1:A10, B1:B10, "none")`,
+		arg("lookup_value", "the value to find"), arg("lookup_array", "where to find it"), arg("return_array", "what to return"),
+		opt("if_not_found", "the result when nothing matches"), opt("match_mode", "0 exact, -1 or 1 next smaller or larger, 2 wildcard"),
+		opt("search_mode", "1 from the first, -1 from the last, 2 or -2 binary search")),
+	fn("MATCH", CategoryLookup, "Position of a value in a row or column", "MATCH(E1, A1:A10, 0)",
+		arg("lookup_value", "the value to find"), arg("lookup_array", "the row or column"),
+		opt("match_type", "0 exact, 1 largest not above (sorted), -1 smallest not below")),
+	fn("INDEX", CategoryLookup, "Value at a row and column of a range", "INDEX(B1:B10, 3)",
+		arg("array", "the range"), arg("row_num", "the row, from 1"), opt("column_num", "the column, from 1")),
+	volatile(fn("INDIRECT", CategoryLookup, "Value of the cell or range written as text", `INDIRECT(CONCAT("B", A1))`,
+		arg("ref_text", "an address like B3 or Sheet2!A1:A5"), opt("a1", "TRUE or omitted; R1C1 addresses are not supported"))),
+	volatile(fn("OFFSET", CategoryLookup, "Range moved from a reference by rows and columns", "SUM(OFFSET(A1, 0, 1, 5, 1))",
+		arg("reference", "the starting cell or range"), arg("rows", "rows to move down, negative for up"),
+		arg("cols", "columns to move right, negative for left"), opt("height", "the rows of the result"), opt("width", "the columns of the result"))),
+	fn("ROW", CategoryLookup, "Row number of a reference, or of the cell with the formula", "ROW(B5)",
+		opt("reference", "the cell or range, the one with the formula by default; a range spills its row numbers")),
+	fn("COLUMN", CategoryLookup, "Column number of a reference, or of the cell with the formula", "COLUMN(C1)",
+		opt("reference", "the cell or range, the one with the formula by default; a range spills its column numbers")),
+	fn("ROWS", CategoryLookup, "Number of rows of a range or array", "ROWS(A2:C10)", arg("array", "the range or array")),
+	fn("COLUMNS", CategoryLookup, "Number of columns of a range or array", "COLUMNS(A2:C10)", arg("array", "the range or array")),
+	fn("FORMULATEXT", CategoryLookup, "The formula of a cell as text, #N/A when it holds none", "FORMULATEXT(B2)",
+		arg("reference", "the cell holding the formula")),
+
+	// Dynamic arrays
+	fn("SEQUENCE", CategoryArray, "Array of evenly spaced numbers", "SEQUENCE(10)",
+		arg("rows", "the rows of the array"), opt("columns", "the columns, 1 by default"),
+		opt("start", "the first number, 1 by default"), opt("step", "the increment, 1 by default")),
+	fn("TRANSPOSE", CategoryArray, "Swaps the rows and columns of an array", "TRANSPOSE(A1:C2)", arg("array", "the range or array")),
+	fn("SORT", CategoryArray, "Sorts the rows of an array", "SORT(A2:B20, 2, -1)",
+		arg("array", "the range or array"), opt("sort_index", "the column to sort by, 1 by default"),
+		opt("sort_order", "1 ascending, -1 descending"), opt("by_col", "TRUE to sort columns instead of rows")),
+	fn("FILTER", CategoryArray, "Rows of an array meeting a condition", `FILTER(A2:B20, B2:B20 > 100, "none")`,
+		arg("array", "the range or array"), arg("include", "a condition per row, like B2:B20 > 100"),
+		opt("if_empty", "the result when no row matches")),
+	fn("UNIQUE", CategoryArray, "Distinct rows of an array", "UNIQUE(C:C)",
+		arg("array", "the range or array"), opt("by_col", "TRUE to compare columns instead of rows"),
+		opt("exactly_once", "TRUE to keep only the rows occurring once")),
+
+	// Names inside a formula and workbook functions
+	fn("LET", CategoryNames, "Names values inside a formula, each computed once", "LET(net, B2-C2, net * (1 + net/B2))",
+		arg("name1", "the first name"), arg("value1", "its value"),
+		more("name2", "more names"), more("value2", "their values"), arg("calculation", "the result, using the names")),
+	fn("LAMBDA", CategoryNames, "A function, saved under a name in the Name Manager or called in place", "LAMBDA(x, x*x)(A1)",
+		more("parameter", "a parameter name"), arg("calculation", "the result, using the parameters")),
+}
@@ -0,0 +1,394 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// criteria.go provides the criteria matching behind the conditional aggregates (SUMIF, COUNTIFS, ...)
+
+package evaluatefuncs
+
+import (
+	"strconv"
+	"strings"
+)
+
+// criterion is a condition written like Excel does: 10, ">=10", "<>x", "app*" or "" for a blank cell
+type criterion struct {
+	op       string
+	operand  any
+	wildcard bool
+}
+
+// parseCriterion reads a criteria argument. Text starting with a comparison operator compares against the
+// rest of the text, anything else is an equality test.
+func parseCriterion(v any) criterion {
+	text, ok := scalar(v).(string)
+	if !ok {
+		value := scalar(v)
+		if value == nil {
+			value = ""
+		}
+		return criterion{op: "=", operand: value}
+	}
+
+	c := criterion{op: "="}
+	for _, op := range []string{"<=", ">=", "<>", "<", ">", "="} {
+		if strings.HasPrefix(text, op) {
+			c.op, text = op, text[len(op):]
+			break
+		}
+	}
+
+	if f, err := strconv.ParseFloat(strings.TrimSpace(text), 64); err == nil {
+		c.operand = f
+	} else if strings.EqualFold(text, "TRUE") || strings.EqualFold(text, "FALSE") {
+		c.operand = strings.EqualFold(text, "TRUE")
+	} else if ev, ok := ParseError(text); ok {
+		c.operand = ev
+	} else {
+		c.operand = text
+		c.wildcard = hasWildcards(text)
+	}
+	return c
+}
+
+// matches reports whether a cell value satisfies the criterion, nil standing for an empty cell
+func (c criterion) matches(v any) bool {
+	if s, ok := v.(string); ok && s == "" {
+		v = nil
+	}
+
+	if c.operand == "" {
+		switch c.op {
+		case "=":
+			return v == nil
+		case "<>":
+			return v != nil
+		}
+		return false
+	}
+	if v == nil {
+		return c.op == "<>"
+	}
+
+	if ev, ok := c.operand.(ErrorValue); ok {
+		cell, isErr := v.(ErrorValue)
+		equal := isErr && cell.Is(ev)
+		return equal == (c.op == "=")
+	}
+	if !sameKind(v, c.operand) {
+		return c.op == "<>"
+	}
+
+	if c.wildcard && (c.op == "=" || c.op == "<>") {
+		return wildcardMatch(c.operand.(string), v.(string)) == (c.op == "=")
+	}
+
+	order := compareValues(v, c.operand)
+	switch c.op {
+	case "=":
+		return order == 0
+	case "<>":
+		return order != 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	case ">=":
+		return order >= 0
+	}
+	return false
+}
+
+// condition pairs a criteria range with the criterion its cells must satisfy
+type condition struct {
+	rng  Range
+	test criterion
+}
+
+// asRange treats a single value, such as a lone cell reference, as a one-cell range
+func asRange(v any) Range {
+	if r, ok := v.(Range); ok {
+		return r
+	}
+	return singleValue{value: v}
+}
+
+// singleValue is a one-cell range holding a plain value
+type singleValue struct {
+	value any
+}
+
+func (s singleValue) Dims() (int, int) {
+	return 1, 1
+}
+
+func (s singleValue) At(row, col int) any {
+	if row != 0 || col != 0 {
+		return nil
+	}
+	return s.value
+}
+
+func (s singleValue) Each(fn func(row, col int, value any) bool) {
+	if s.value != nil && s.value != "" {
+		fn(0, 0, s.value)
+	}
+}
+
+// parseConditions reads range/criteria pairs. Every criteria range must have the dimensions of shape.
+func parseConditions(name string, shape Range, args []any) ([]condition, error) {
+	if len(args)%2 != 0 {
+		return nil, newError(ErrArgs, "%s: every criteria range needs a criteria", name)
+	}
+	rows, cols := shape.Dims()
+	conditions := make([]condition, 0, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		rng := asRange(args[i])
+		if r, c := rng.Dims(); r != rows || c != cols {
+			return nil, newError(ErrValue, "%s: ranges must have the same dimensions", name)
+		}
+		conditions = append(conditions, condition{rng: rng, test: parseCriterion(args[i+1])})
+	}
+	return conditions, nil
+}
+
+// matchAll reports whether the cell at row, col of every criteria range satisfies its criterion
+func matchAll(conditions []condition, row, col int) bool {
+	for _, cond := range conditions {
+		if !cond.test.matches(cond.rng.At(row, col)) {
+			return false
+		}
+	}
+	return true
+}
+
+// eachMatch calls fn for every non-empty cell of values whose position satisfies all conditions
+func eachMatch(values Range, conditions []condition, fn func(value any) bool) {
+	values.Each(func(row, col int, value any) bool {
+		if !matchAll(conditions, row, col) {
+			return true
+		}
+		return fn(value)
+	})
+}
+
+// countMatches counts the positions satisfying all conditions. Empty cells are counted without being
+// visited, since a whole-column range holds mostly those.
+func countMatches(conditions []condition) int {
+	for _, cond := range conditions {
+		if cond.test.matches(nil) {
+			continue
+		}
+		count := 0
+		eachMatch(cond.rng, conditions, func(any) bool {
+			count++
+			return true
+		})
+		return count
+	}
+
+	// Every criterion accepts an empty cell: count the filled positions which match, then
+	// add every position which is empty in all ranges
+	seen := make(map[[2]int]bool)
+	count := 0
+	for _, cond := range conditions {
+		cond.rng.Each(func(row, col int, _ any) bool {
+			pos := [2]int{row, col}
+			if seen[pos] {
+				return true
+			}
+			seen[pos] = true
+			if matchAll(conditions, row, col) {
+				count++
+			}
+			return true
+		})
+	}
+	rows, cols := conditions[0].rng.Dims()
+	return count + rows*cols - len(seen)
+}
+
+// resizedValues returns the cells SUMIF or AVERAGEIF aggregates, given the resolver, range, criteria and
+// the optional values. Like Excel, the values take the shape of the criteria range from their top-left
+// cell, so SUMIF(B1:B9, "y", A1) adds from A1:A9.
+func resizedValues(name string, args []any) (Range, error) {
+	rng := asRange(args[1])
+	if len(args) < 4 {
+		return rng, nil
+	}
+
+	values := asRange(args[3])
+	rows, cols := rng.Dims()
+	if r, c := values.Dims(); r == rows && c == cols {
+		return values, nil
+	}
+	resolver, ok := args[0].(Resolver)
+	if !ok {
+		return nil, newError(ErrRef, "%s: no workbook to resolve references in", name)
+	}
+	resized, err := resolver.Offset(values, 0, 0, rows, cols)
+	if err != nil {
+		return nil, newError(ErrValue, "%s: the values cannot take the size of the range: %v", name, err)
+	}
+	return resized, nil
+}
+
+// conditionalAggregate collects the numbers of values at the positions satisfying all conditions.
+// An error value at such a position is returned as the error.
+func conditionalAggregate(name string, values Range, pairs []any) ([]float64, error) {
+	conditions, err := parseConditions(name, values, pairs)
+	if err != nil {
+		return nil, err
+	}
+	var numbers []float64
+	var firstErr error
+	eachMatch(values, conditions, func(value any) bool {
+		switch v := value.(type) {
+		case float64:
+			numbers = append(numbers, v)
+		case int:
+			numbers = append(numbers, float64(v))
+		case ErrorValue:
+			firstErr = v
+			return false
+		}
+		return true
+	})
+	return numbers, firstErr
+}
+
+func ConditionalFunctions() map[string]ExprFunction {
+	sum := func(numbers []float64) float64 {
+		total := 0.0
+		for _, n := range numbers {
+			total += n
+		}
+		return total
+	}
+
+	return map[string]ExprFunction{
+		"SUMIF": func(args ...any) (any, error) {
+			if err := validateArgs("SUMIF", args, 3, 4); err != nil {
+				return nil, err
+			}
+			values, err := resizedValues("SUMIF", args)
+			if err != nil {
+				return nil, err
+			}
+			numbers, err := conditionalAggregate("SUMIF", values, args[1:3])
+			if err != nil {
+				return nil, err
+			}
+			return sum(numbers), nil
+		},
+
+		"SUMIFS": func(args ...any) (any, error) {
+			if err := validateArgs("SUMIFS", args, 3, -1); err != nil {
+				return nil, err
+			}
+			numbers, err := conditionalAggregate("SUMIFS", asRange(args[0]), args[1:])
+			if err != nil {
+				return nil, err
+			}
+			return sum(numbers), nil
+		},
+
+		"COUNTIF": func(args ...any) (any, error) {
+			if err := validateArgs("COUNTIF", args, 2, 2); err != nil {
+				return nil, err
+			}
+			rng := asRange(args[0])
+			conditions, err := parseConditions("COUNTIF", rng, args)
+			if err != nil {
+				return nil, err
+			}
+			return float64(countMatches(conditions)), nil
+		},
+
+		"COUNTIFS": func(args ...any) (any, error) {
+			if err := validateArgs("COUNTIFS", args, 2, -1); err != nil {
+				return nil, err
+			}
+			conditions, err := parseConditions("COUNTIFS", asRange(args[0]), args)
+			if err != nil {
+				return nil, err
+			}
+			return float64(countMatches(conditions)), nil
+		},
+
+		"AVERAGEIF": func(args ...any) (any, error) {
+			if err := validateArgs("AVERAGEIF", args, 3, 4); err != nil {
+				return nil, err
+			}
+			values, err := resizedValues("AVERAGEIF", args)
+			if err != nil {
+				return nil, err
+			}
+			numbers, err := conditionalAggregate("AVERAGEIF", values, args[1:3])
+			if err != nil {
+				return nil, err
+			}
+			if len(numbers) == 0 {
+				return nil, newError(ErrDiv0, "AVERAGEIF: no cell matches the criteria")
+			}
+			return sum(numbers) / float64(len(numbers)), nil
+		},
+
+		"AVERAGEIFS": func(args ...any) (any, error) {
+			if err := validateArgs("AVERAGEIFS", args, 3, -1); err != nil {
+				return nil, err
+			}
+			numbers, err := conditionalAggregate("AVERAGEIFS", asRange(args[0]), args[1:])
+			if err != nil {
+				return nil, err
+			}
+			if len(numbers) == 0 {
+				return nil, newError(ErrDiv0, "AVERAGEIFS: no cell matches the criteria")
+			}
+			return sum(numbers) / float64(len(numbers)), nil
+		},
+
+		"MAXIFS": func(args ...any) (any, error) {
+			if err := validateArgs("MAXIFS", args, 3, -1); err != nil {
+				return nil, err
+			}
+			numbers, err := conditionalAggregate("MAXIFS", asRange(args[0]), args[1:])
+			if err != nil {
+				return nil, err
+			}
+			if len(numbers) == 0 {
+				return 0.0, nil
+			}
+			max := numbers[0]
+			for _, n := range numbers[1:] {
+				if n > max {
+					max = n
+				}
+			}
+			return max, nil
+		},
+
+		"MINIFS": func(args ...any) (any, error) {
+			if err := validateArgs("MINIFS", args, 3, -1); err != nil {
+				return nil, err
+			}
+			numbers, err := conditionalAggregate("MINIFS", asRange(args[0]), args[1:])
+			if err != nil {
+				return nil, err
+			}
+			if len(numbers) == 0 {
+				return 0.0, nil
+			}
+			min := numbers[0]
+			for _, n := range numbers[1:] {
+				if n < min {
+					min = n
+				}
+			}
+			return min, nil
+		},
+	}
+}
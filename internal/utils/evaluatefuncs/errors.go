@@ -0,0 +1,184 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// errors.go provides spreadsheet error values (#DIV/0!, #N/A, ...) and the functions handling them
+
+package evaluatefuncs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorValue is a spreadsheet error such as #DIV/0! or #N/A. Functions return it as an ordinary value,
+// so it flows into every formula reading the cell until a function like IFERROR handles it.
+// It is also a Go error, which lets functions simply return it.
+type ErrorValue struct {
+	Code   string // the text shown in the cell, e.g. #DIV/0!
+	Reason string // why it happened, when known
+}
+
+var (
+	ErrNull  = ErrorValue{Code: "#NULL!"}
+	ErrDiv0  = ErrorValue{Code: "#DIV/0!"}
+	ErrValue = ErrorValue{Code: "#VALUE!"}
+	ErrRef   = ErrorValue{Code: "#REF!"}
+	ErrName  = ErrorValue{Code: "#NAME?"}
+	ErrNum   = ErrorValue{Code: "#NUM!"}
+	ErrNA    = ErrorValue{Code: "#N/A"}
+	ErrSpill = ErrorValue{Code: "#SPILL!"}
+	ErrCalc  = ErrorValue{Code: "#CALC!"}
+
+	// GoSheet's own errors, which Excel does not have
+	ErrArgs  = ErrorValue{Code: "#ARGS!"}
+	ErrCirc  = ErrorValue{Code: "#CIRC!"}
+	ErrError = ErrorValue{Code: "#ERROR!"}
+)
+
+// errorValues lists every error by its code, with the number ERROR.TYPE returns for it (0 when Excel has none)
+var errorValues = []struct {
+	err    ErrorValue
+	number float64
+}{
+	{ErrNull, 1}, {ErrDiv0, 2}, {ErrValue, 3}, {ErrRef, 4}, {ErrName, 5}, {ErrNum, 6}, {ErrNA, 7}, {ErrSpill, 9}, {ErrCalc, 14},
+	{ErrArgs, 0}, {ErrCirc, 0}, {ErrError, 0},
+}
+
+func (e ErrorValue) Error() string {
+	if e.Reason != "" {
+		return e.Reason
+	}
+	return e.Code
+}
+
+func (e ErrorValue) String() string {
+	return e.Code
+}
+
+// Is matches errors by code, so errors.Is(err, ErrDiv0) holds whatever the reason
+func (e ErrorValue) Is(target error) bool {
+	t, ok := target.(ErrorValue)
+	return ok && t.Code == e.Code
+}
+
+// newError returns the error value code with a reason
+func newError(code ErrorValue, format string, args ...any) ErrorValue {
+	return ErrorValue{Code: code.Code, Reason: fmt.Sprintf(format, args...)}
+}
+
+// ParseError returns the error value written as text, like the display of a cell holding #N/A
+func ParseError(s string) (ErrorValue, bool) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "#") {
+		return ErrorValue{}, false
+	}
+	for _, known := range errorValues {
+		if strings.EqualFold(s, known.err.Code) {
+			return known.err, true
+		}
+	}
+	return ErrorValue{}, false
+}
+
+// ErrorOf returns the error value a failed function or formula shows as; errors without one become #VALUE!
+func ErrorOf(err error) ErrorValue {
+	var ev ErrorValue
+	if errors.As(err, &ev) {
+		return ev
+	}
+	return ErrorValue{Code: ErrValue.Code, Reason: err.Error()}
+}
+
+// errorHandling lists the functions which receive error arguments themselves; every other function
+// returns the first error among its arguments without being called
+var errorHandling = map[string]bool{
+	"IFERROR": true, "IFNA": true, "ISERROR": true, "ISNA": true, "ERROR.TYPE": true,
+	"ISNUMBER": true, "ISTEXT": true, "ISBLANK": true, "TYPE": true,
+	"IF": true, "IFS": true, "CHOOSE": true,
+}
+
+// propagateErrors wraps a function so error arguments pass through it and its failures become error values
+func propagateErrors(name string, fn ExprFunction) ExprFunction {
+	handles := errorHandling[name]
+	return func(args ...any) (any, error) {
+		if !handles {
+			for _, arg := range args {
+				if ev, ok := arg.(ErrorValue); ok {
+					return ev, nil
+				}
+			}
+		}
+
+		result, err := fn(args...)
+		if err != nil {
+			return ErrorOf(err), nil
+		}
+		return result, nil
+	}
+}
+
+func ErrorFunctions() map[string]ExprFunction {
+	return map[string]ExprFunction{
+		"IFERROR": func(args ...any) (any, error) {
+			if err := validateArgs("IFERROR", args, 2, 2); err != nil {
+				return nil, err
+			}
+			if _, ok := args[0].(ErrorValue); ok {
+				return args[1], nil
+			}
+			return args[0], nil
+		},
+
+		"IFNA": func(args ...any) (any, error) {
+			if err := validateArgs("IFNA", args, 2, 2); err != nil {
+				return nil, err
+			}
+			if ev, ok := args[0].(ErrorValue); ok && ev.Is(ErrNA) {
+				return args[1], nil
+			}
+			return args[0], nil
+		},
+
+		"ISERROR": func(args ...any) (any, error) {
+			if err := validateArgs("ISERROR", args, 1, 1); err != nil {
+				return nil, err
+			}
+			_, ok := args[0].(ErrorValue)
+			return ok, nil
+		},
+
+		"ISNA": func(args ...any) (any, error) {
+			if err := validateArgs("ISNA", args, 1, 1); err != nil {
+				return nil, err
+			}
+			ev, ok := args[0].(ErrorValue)
+			return ok && ev.Is(ErrNA), nil
+		},
+
+		"ERROR.TYPE": func(args ...any) (any, error) {
+			if err := validateArgs("ERROR.TYPE", args, 1, 1); err != nil {
+				return nil, err
+			}
+			ev, ok := args[0].(ErrorValue)
+			if !ok {
+				return nil, ErrNA
+			}
+			for _, known := range errorValues {
+				if known.err.Is(ev) && known.number > 0 {
+					return known.number, nil
+				}
+			}
+			return nil, ErrNA
+		},
+
+		"NA": func(args ...any) (any, error) {
+			if err := validateArgs("NA", args, 0, 0); err != nil {
+				return nil, err
+			}
+			return nil, ErrNA
+		},
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// financial.go provides time value of money functions
+
+package evaluatefuncs
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// solverIterations and solverTolerance bound the search of IRR, RATE and XIRR, which give #NUM! past them
+	solverIterations = 100
+	solverTolerance  = 1e-10
+	defaultGuess     = 0.1
+)
+
+// floatArgs reads the first count arguments as numbers, the omitted optional ones being 0
+func floatArgs(name string, args []any, count int) ([]float64, error) {
+	values := make([]float64, count)
+	for i := 0; i < count && i < len(args); i++ {
+		if args[i] == nil || args[i] == "" {
+			continue
+		}
+		f, err := toFloat(args[i])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		values[i] = f
+	}
+	return values, nil
+}
+
+// guessArg reads the optional starting point of a solver
+func guessArg(name string, args []any, pos int) (float64, error) {
+	if pos >= len(args) || args[pos] == nil || args[pos] == "" {
+		return defaultGuess, nil
+	}
+	guess, err := toFloat(args[pos])
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", name, err)
+	}
+	return guess, nil
+}
+
+// annuity returns the future value of pv plus nper payments of pmt, the last two arguments of PV, FV and friends
+func annuity(rate, nper, pmt, pv, due float64) float64 {
+	if rate == 0 {
+		return pv + pmt*nper
+	}
+	growth := math.Pow(1+rate, nper)
+	return pv*growth + pmt*(1+rate*due)*(growth-1)/rate
+}
+
+// solveRate finds where f crosses zero with Newton's method, starting from guess
+func solveRate(name string, f func(rate float64) float64, guess float64) (float64, error) {
+	rate := guess
+	for i := 0; i < solverIterations; i++ {
+		value := f(rate)
+		if math.Abs(value) < solverTolerance {
+			return rate, nil
+		}
+		step := math.Max(math.Abs(rate)*1e-6, 1e-9)
+		slope := (f(rate+step) - value) / step
+		if slope == 0 || math.IsNaN(slope) || math.IsInf(slope, 0) {
+			break
+		}
+		next := rate - value/slope
+		if next <= -1 {
+			next = (rate - 1) / 2
+		}
+		if math.Abs(next-rate) < solverTolerance {
+			// A standstill is only a solution if it lands on zero, not against the -100% bound
+			if math.Abs(f(next)) < 1e-7 {
+				return next, nil
+			}
+			break
+		}
+		rate = next
+	}
+	return 0, newError(ErrNum, "%s: no solution found from guess %v", name, guess)
+}
+
+// toDate reads a date argument, either a date text or a day count since 1899-12-30 like Excel serial dates
+func toDate(v any) (time.Time, error) {
+	if f, ok := numberOf(scalar(v)); ok {
+		return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).Add(time.Duration(f * 24 * float64(time.Hour))), nil
+	}
+	t, err := ParseDateTime(toString(v))
+	if err != nil {
+		return time.Time{}, newError(ErrValue, "%v is not a date", v)
+	}
+	return t, nil
+}
+
+// cashFlows pairs the values with their dates for XNPV and XIRR, cell by cell
+func cashFlows(name string, values, dates any) ([]float64, []float64, error) {
+	vr, dr := asRange(values), asRange(dates)
+	vRows, vCols := vr.Dims()
+	dRows, dCols := dr.Dims()
+	if vRows*vCols != dRows*dCols {
+		return nil, nil, newError(ErrNum, "%s: values and dates differ in size", name)
+	}
+
+	var amounts, days []float64
+	var first time.Time
+	var firstErr error
+	vr.Each(func(row, col int, value any) bool {
+		amount, ok := value.(float64)
+		if !ok {
+			firstErr = newError(ErrValue, "%s: %v is not a number", name, value)
+			return false
+		}
+		pos := row*vCols + col
+		date, err := toDate(dr.At(pos/dCols, pos%dCols))
+		if err != nil {
+			firstErr = err
+			return false
+		}
+		if len(amounts) == 0 {
+			first = date
+		} else if date.Before(first) {
+			firstErr = newError(ErrNum, "%s: a date falls before the first one", name)
+			return false
+		}
+		amounts = append(amounts, amount)
+		days = append(days, date.Sub(first).Hours()/24)
+		return true
+	})
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+	if len(amounts) == 0 {
+		return nil, nil, newError(ErrNum, "%s: no cash flows", name)
+	}
+	return amounts, days, nil
+}
+
+// mixedSigns reports whether the flows hold both a payment and a receipt, without which no rate exists
+func mixedSigns(amounts []float64) bool {
+	positive, negative := false, false
+	for _, a := range amounts {
+		positive = positive || a > 0
+		negative = negative || a < 0
+	}
+	return positive && negative
+}
+
+func FinancialFunctions() map[string]ExprFunction {
+	return map[string]ExprFunction{
+		"PMT": func(args ...any) (any, error) {
+			if err := validateArgs("PMT", args, 3, 5); err != nil {
+				return nil, err
+			}
+			p, err := floatArgs("PMT", args, 5)
+			if err != nil {
+				return nil, err
+			}
+			rate, nper, pv, fv, due := p[0], p[1], p[2], p[3], p[4]
+			if nper == 0 {
+				return nil, newError(ErrNum, "PMT: the number of periods cannot be zero")
+			}
+			if rate == 0 {
+				return -(pv + fv) / nper, nil
+			}
+			growth := math.Pow(1+rate, nper)
+			return -rate * (fv + pv*growth) / ((1 + rate*due) * (growth - 1)), nil
+		},
+
+		"PV": func(args ...any) (any, error) {
+			if err := validateArgs("PV", args, 3, 5); err != nil {
+				return nil, err
+			}
+			p, err := floatArgs("PV", args, 5)
+			if err != nil {
+				return nil, err
+			}
+			rate, nper, pmt, fv, due := p[0], p[1], p[2], p[3], p[4]
+			// The present value is the pv for which the loan ends at fv
+			return -(fv + annuity(rate, nper, pmt, 0, due)) / math.Pow(1+rate, nper), nil
+		},
+
+		"FV": func(args ...any) (any, error) {
+			if err := validateArgs("FV", args, 3, 5); err != nil {
+				return nil, err
+			}
+			p, err := floatArgs("FV", args, 5)
+			if err != nil {
+				return nil, err
+			}
+			rate, nper, pmt, pv, due := p[0], p[1], p[2], p[3], p[4]
+			return -annuity(rate, nper, pmt, pv, due), nil
+		},
+
+		"NPER": func(args ...any) (any, error) {
+			if err := validateArgs("NPER", args, 3, 5); err != nil {
+				return nil, err
+			}
+			p, err := floatArgs("NPER", args, 5)
+			if err != nil {
+				return nil, err
+			}
+			rate, pmt, pv, fv, due := p[0], p[1], p[2], p[3], p[4]
+			if rate == 0 {
+				if pmt == 0 {
+					return nil, newError(ErrNum, "NPER: payment and rate cannot both be zero")
+				}
+				return -(pv + fv) / pmt, nil
+			}
+			num := pmt*(1+rate*due) - fv*rate
+			den := pv*rate + pmt*(1+rate*due)
+			if den == 0 || num/den <= 0 {
+				return nil, newError(ErrNum, "NPER: the loan is never paid off")
+			}
+			return math.Log(num/den) / math.Log(1+rate), nil
+		},
+
+		"RATE": func(args ...any) (any, error) {
+			if err := validateArgs("RATE", args, 3, 6); err != nil {
+				return nil, err
+			}
+			p, err := floatArgs("RATE", args, 5)
+			if err != nil {
+				return nil, err
+			}
+			guess, err := guessArg("RATE", args, 5)
+			if err != nil {
+				return nil, err
+			}
+			nper, pmt, pv, fv, due := p[0], p[1], p[2], p[3], p[4]
+			return solveRate("RATE", func(rate float64) float64 {
+				return annuity(rate, nper, pmt, pv, due) + fv
+			}, guess)
+		},
+
+		"NPV": func(args ...any) (any, error) {
+			if err := validateArgs("NPV", args, 2, -1); err != nil {
+				return nil, err
+			}
+			rate, err := toFloat(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("NPV: %v", err)
+			}
+			if rate == -1 {
+				return nil, newError(ErrDiv0, "NPV: rate cannot be -100%%")
+			}
+			values, err := numbersOf(args[1:])
+			if err != nil {
+				return nil, err
+			}
+			npv := 0.0
+			for i, v := range values {
+				npv += v / math.Pow(1+rate, float64(i+1))
+			}
+			return npv, nil
+		},
+
+		"IRR": func(args ...any) (any, error) {
+			if err := validateArgs("IRR", args, 1, 2); err != nil {
+				return nil, err
+			}
+			values, err := numbersOf(args[:1])
+			if err != nil {
+				return nil, err
+			}
+			if !mixedSigns(values) {
+				return nil, newError(ErrNum, "IRR: values need both a positive and a negative amount")
+			}
+			guess, err := guessArg("IRR", args, 1)
+			if err != nil {
+				return nil, err
+			}
+			return solveRate("IRR", func(rate float64) float64 {
+				npv := 0.0
+				for i, v := range values {
+					npv += v / math.Pow(1+rate, float64(i))
+				}
+				return npv
+			}, guess)
+		},
+
+		"XNPV": func(args ...any) (any, error) {
+			if err := validateArgs("XNPV", args, 3, 3); err != nil {
+				return nil, err
+			}
+			rate, err := toFloat(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("XNPV: %v", err)
+			}
+			if rate <= -1 {
+				return nil, newError(ErrNum, "XNPV: rate must be above -100%%")
+			}
+			amounts, days, err := cashFlows("XNPV", args[1], args[2])
+			if err != nil {
+				return nil, err
+			}
+			npv := 0.0
+			for i, a := range amounts {
+				npv += a / math.Pow(1+rate, days[i]/365)
+			}
+			return npv, nil
+		},
+
+		"XIRR": func(args ...any) (any, error) {
+			if err := validateArgs("XIRR", args, 2, 3); err != nil {
+				return nil, err
+			}
+			amounts, days, err := cashFlows("XIRR", args[0], args[1])
+			if err != nil {
+				return nil, err
+			}
+			if !mixedSigns(amounts) {
+				return nil, newError(ErrNum, "XIRR: values need both a positive and a negative amount")
+			}
+			guess, err := guessArg("XIRR", args, 2)
+			if err != nil {
+				return nil, err
+			}
+			return solveRate("XIRR", func(rate float64) float64 {
+				npv := 0.0
+				for i, a := range amounts {
+					npv += a / math.Pow(1+rate, days[i]/365)
+				}
+				return npv
+			}, guess)
+		},
+	}
+}
@@ -15,7 +15,7 @@ import (
 
 // Helper function to convert any type to string
 func toString(v any) string {
-	switch val := v.(type) {
+	switch val := scalar(v).(type) {
 	case string:
 		return val
 	case float64:
@@ -37,7 +37,7 @@ func toString(v any) string {
 
 // Helper function to convert any type to float64
 func toFloat(v any) (float64, error) {
-	switch val := v.(type) {
+	switch val := scalar(v).(type) {
 	case float64:
 		return val, nil
 	case int:
@@ -52,14 +52,19 @@ func toFloat(v any) (float64, error) {
 }
 
 func validateArgs(funcName string, args []any, minArgs, maxArgs int) error {
-	if len(args) < minArgs {
+	return checkArity(funcName, len(args), minArgs, maxArgs)
+}
+
+// checkArity returns the #ARGS! error of a call with count arguments, maxArgs being -1 for any number
+func checkArity(funcName string, count, minArgs, maxArgs int) error {
+	if count < minArgs {
 		if minArgs == maxArgs {
-			return fmt.Errorf("%s requires exactly %d argument(s), got %d", funcName, minArgs, len(args))
+			return newError(ErrArgs, "%s requires exactly %d argument(s), got %d", funcName, minArgs, count)
 		}
-		return fmt.Errorf("%s requires at least %d argument(s), got %d", funcName, minArgs, len(args))
+		return newError(ErrArgs, "%s requires at least %d argument(s), got %d", funcName, minArgs, count)
 	}
-	if maxArgs != -1 && len(args) > maxArgs {
-		return fmt.Errorf("%s accepts at most %d argument(s), got %d", funcName, maxArgs, len(args))
+	if maxArgs != -1 && count > maxArgs {
+		return newError(ErrArgs, "%s accepts at most %d argument(s), got %d", funcName, maxArgs, count)
 	}
 	return nil
 }
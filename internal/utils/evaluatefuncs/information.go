@@ -0,0 +1,226 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// information.go provides the functions describing the cell a formula is in, the cells it refers to and
+// the kind of a value
+
+package evaluatefuncs
+
+import (
+	"fmt"
+	"gosheet/internal/utils"
+	"path/filepath"
+	"strings"
+)
+
+// describedCell returns the top-left cell of the reference following the resolver in args, or the cell the
+// formula is in when the reference was left out. With read set the cell is calculated first, for the
+// information coming from its value.
+func describedCell(name string, args []any, read bool) (CellInfo, error) {
+	resolver, ok := args[0].(Resolver)
+	if !ok {
+		return CellInfo{}, newError(ErrValue, "%s: no workbook to look into", name)
+	}
+	if len(args) < 2 {
+		info, ok := resolver.Caller()
+		if !ok {
+			return CellInfo{}, newError(ErrValue, "%s: the formula is not in a cell, a reference must be given", name)
+		}
+		return info, nil
+	}
+	if ref, ok := args[1].(Range); ok {
+		if read {
+			if err := resolver.Evaluate(ref); err != nil {
+				return CellInfo{}, err
+			}
+		}
+		if info, ok := resolver.Describe(ref); ok {
+			return info, nil
+		}
+	}
+	return CellInfo{}, newError(ErrValue, "%s: the argument must be a reference, like A1", name)
+}
+
+// positions returns the row or column numbers a reference covers: a single number for one row or column,
+// an array spilling along the reference for more. A whole column or row gives its first number alone, the
+// way Excel does outside of an array formula.
+func positions(name string, args []any, rows bool) (any, error) {
+	info, err := describedCell(name, args, false)
+	if err != nil {
+		return nil, err
+	}
+	first, count := info.Col, 1
+	if rows {
+		first = info.Row
+	}
+	if len(args) > 1 {
+		height, width := args[1].(Range).Dims()
+		if count = width; rows {
+			count = height
+		}
+	}
+	if count == 1 || (rows && count >= int(utils.MAX_ROWS)) || (!rows && count >= int(utils.MAX_COLS)) {
+		return float64(first), nil
+	}
+
+	height, width := 1, count
+	if rows {
+		height, width = count, 1
+	}
+	result, err := newArray(height, width)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < count; i++ {
+		if rows {
+			result[i][0] = float64(first + i)
+		} else {
+			result[0][i] = float64(first + i)
+		}
+	}
+	return result, nil
+}
+
+// cellFormat returns the format code CELL("format") gives for a cell, like Excel's ,2 or C2
+func cellFormat(info CellInfo) string {
+	switch strings.ToLower(info.Type) {
+	case "number":
+		return fmt.Sprintf(",%d", info.Decimals)
+	case "financial":
+		return fmt.Sprintf("C%d", info.Decimals)
+	case "datetime":
+		return "D1"
+	}
+	return "G"
+}
+
+// workbookFileName writes the file and sheet of a cell the way CELL("filename") gives them, like
+// /home/me/[budget.gsheet]Sheet1, or "" for a workbook never saved
+func workbookFileName(info CellInfo) string {
+	if info.File == "" {
+		return ""
+	}
+	file := info.File
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+	return filepath.Join(filepath.Dir(file), "["+filepath.Base(file)+"]"+info.Sheet)
+}
+
+func InformationFunctions() map[string]ExprFunction {
+	return map[string]ExprFunction{
+		"ROW": func(args ...any) (any, error) {
+			if err := validateArgs("ROW", args, 1, 2); err != nil {
+				return nil, err
+			}
+			return positions("ROW", args, true)
+		},
+
+		"COLUMN": func(args ...any) (any, error) {
+			if err := validateArgs("COLUMN", args, 1, 2); err != nil {
+				return nil, err
+			}
+			return positions("COLUMN", args, false)
+		},
+
+		"ROWS": func(args ...any) (any, error) {
+			if err := validateArgs("ROWS", args, 1, 1); err != nil {
+				return nil, err
+			}
+			rows, _ := asRange(args[0]).Dims()
+			return float64(rows), nil
+		},
+
+		"COLUMNS": func(args ...any) (any, error) {
+			if err := validateArgs("COLUMNS", args, 1, 1); err != nil {
+				return nil, err
+			}
+			_, cols := asRange(args[0]).Dims()
+			return float64(cols), nil
+		},
+
+		"ISFORMULA": func(args ...any) (any, error) {
+			if err := validateArgs("ISFORMULA", args, 2, 2); err != nil {
+				return nil, err
+			}
+			info, err := describedCell("ISFORMULA", args, false)
+			if err != nil {
+				return nil, err
+			}
+			return info.Formula != "", nil
+		},
+
+		"FORMULATEXT": func(args ...any) (any, error) {
+			if err := validateArgs("FORMULATEXT", args, 2, 2); err != nil {
+				return nil, err
+			}
+			info, err := describedCell("FORMULATEXT", args, false)
+			if err != nil {
+				return nil, err
+			}
+			if info.Formula == "" {
+				return nil, newError(ErrNA, "FORMULATEXT: %s holds no formula", info.Address)
+			}
+			return utils.CurrentLocale.LocalizeFormula(info.Formula), nil
+		},
+
+		"TYPE": func(args ...any) (any, error) {
+			if err := validateArgs("TYPE", args, 1, 1); err != nil {
+				return nil, err
+			}
+			if isArray(args[0]) {
+				return 64.0, nil
+			}
+			switch scalar(args[0]).(type) {
+			case string:
+				return 2.0, nil
+			case bool:
+				return 4.0, nil
+			case ErrorValue:
+				return 16.0, nil
+			}
+			return 1.0, nil
+		},
+
+		"CELL": func(args ...any) (any, error) {
+			if err := validateArgs("CELL", args, 2, 3); err != nil {
+				return nil, err
+			}
+			infoType := strings.ToLower(strings.TrimSpace(toString(args[1])))
+			read := infoType == "contents" || infoType == "type"
+			info, err := describedCell("CELL", append([]any{args[0]}, args[2:]...), read)
+			if err != nil {
+				return nil, err
+			}
+
+			switch infoType {
+			case "address":
+				return info.Address, nil
+			case "row":
+				return float64(info.Row), nil
+			case "col":
+				return float64(info.Col), nil
+			case "contents":
+				if info.Value == nil {
+					return "", nil
+				}
+				return info.Value, nil
+			case "type":
+				switch info.Value.(type) {
+				case nil:
+					return "b", nil
+				case string:
+					return "l", nil
+				}
+				return "v", nil
+			case "format":
+				return cellFormat(info), nil
+			case "filename":
+				return workbookFileName(info), nil
+			}
+			return nil, newError(ErrValue, "CELL: unknown info_type %q, use address, col, contents, filename, format, row or type", infoType)
+		},
+	}
+}
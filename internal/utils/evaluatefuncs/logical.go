@@ -13,6 +13,9 @@ func LogicalFunctions() map[string]ExprFunction {
 			if err := validateArgs("IF", args, 3, 3); err != nil {
 				return nil, err
 			}
+			if ev, ok := args[0].(ErrorValue); ok {
+				return ev, nil
+			}
 			condition := args[0].(bool)
 			if condition {
 				return args[1], nil
@@ -25,6 +28,9 @@ func LogicalFunctions() map[string]ExprFunction {
 				return nil, err
 			}
 			for i := 0; i < len(args)-1; i += 2 {
+				if ev, ok := args[i].(ErrorValue); ok {
+					return ev, nil
+				}
 				if args[i].(bool) {
 					return args[i+1], nil
 				}
@@ -33,7 +39,11 @@ func LogicalFunctions() map[string]ExprFunction {
 		},
 
 		"AND": func(args ...any) (any, error) {
-			if err := validateArgs("AND", args, 2, -1); err != nil {
+			if err := validateArgs("AND", args, 1, -1); err != nil {
+				return nil, err
+			}
+			args, err := flattenArgs(args)
+			if err != nil {
 				return nil, err
 			}
 			for _, arg := range args {
@@ -45,7 +55,11 @@ func LogicalFunctions() map[string]ExprFunction {
 		},
 
 		"OR": func(args ...any) (any, error) {
-			if err := validateArgs("OR", args, 2, -1); err != nil {
+			if err := validateArgs("OR", args, 1, -1); err != nil {
+				return nil, err
+			}
+			args, err := flattenArgs(args)
+			if err != nil {
 				return nil, err
 			}
 			for _, arg := range args {
@@ -64,7 +78,11 @@ func LogicalFunctions() map[string]ExprFunction {
 		},
 
 		"XOR": func(args ...any) (any, error) {
-			if err := validateArgs("XOR", args, 2, -1); err != nil {
+			if err := validateArgs("XOR", args, 1, -1); err != nil {
+				return nil, err
+			}
+			args, err := flattenArgs(args)
+			if err != nil {
 				return nil, err
 			}
 			count := 0
@@ -0,0 +1,595 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// lookup.go provides lookup and reference functions
+
+package evaluatefuncs
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Resolver is the context a formula is evaluated in. It turns computed references into ranges for INDIRECT
+// and OFFSET, draws the numbers of RAND and describes the cell the formula is in and the cells it refers
+// to. The formula engine passes one as the hidden first argument of these functions and records the cells
+// they reach as dependencies.
+type Resolver interface {
+	// Reference resolves reference text like "B2", "Sheet2!A1:C3" or a defined name
+	Reference(ref string) (Range, error)
+	// Offset returns the block of height x width cells moved rows down and cols right from base
+	Offset(base Range, rows, cols, height, width int) (Range, error)
+	// Random returns the next random number of the formula, from 0 up to but not including 1
+	Random() float64
+	// Caller describes the cell the formula is in, false when it is in none, like a defined name
+	Caller() (CellInfo, bool)
+	// Describe describes the top-left cell of a reference, false when ref holds computed values instead
+	Describe(ref Range) (CellInfo, bool)
+	// Evaluate calculates the top-left cell of a reference passed by its address alone, for a function
+	// about to read the value in it, and makes the formula depend on it
+	Evaluate(ref Range) error
+}
+
+// CellInfo describes a cell a formula is in or refers to
+type CellInfo struct {
+	Address  string // the absolute reference, like $B$2, with its sheet when it is not the formula's
+	Sheet    string // the name of its sheet
+	File     string // the file of the workbook, "" before it is saved
+	Row      int    // the row, from 1
+	Col      int    // the column, from 1
+	Formula  string // the formula it holds, like $=SUM(A1:A3), "" when it holds none
+	Value    any    // the value it shows, nil when it is empty
+	Type     string // its type, like number, financial, string or datetime
+	Decimals int    // the decimal places a number is shown with
+}
+
+// resolverFunctions lists the functions which take a Resolver as their first argument
+var resolverFunctions = map[string]bool{
+	"INDIRECT":    true,
+	"OFFSET":      true,
+	"RAND":        true,
+	"RANDBETWEEN": true,
+	"ROW":         true,
+	"COLUMN":      true,
+	"ISFORMULA":   true,
+	"FORMULATEXT": true,
+	"CELL":        true,
+	"SUMIF":       true,
+	"AVERAGEIF":   true,
+}
+
+// NeedsResolver reports whether the formula engine must pass a Resolver to the function
+func NeedsResolver(name string) bool {
+	return resolverFunctions[strings.ToUpper(name)]
+}
+
+// referenceArgs lists, by position, the arguments which are references rather than the values they hold
+var referenceArgs = map[string]int{
+	"OFFSET":      0,
+	"ROW":         0,
+	"COLUMN":      0,
+	"ISFORMULA":   0,
+	"FORMULATEXT": 0,
+	"CELL":        1,
+	"TYPE":        0,
+	"ROWS":        0,
+	"COLUMNS":     0,
+	"SUMIF":       2,
+	"AVERAGEIF":   2,
+}
+
+// addressOnly lists the functions of referenceArgs which read where their reference is rather than what
+// it holds. CELL reads the value only for some kinds of information and asks the Resolver to evaluate it.
+var addressOnly = map[string]bool{
+	"OFFSET":    true,
+	"ROW":       true,
+	"COLUMN":    true,
+	"ROWS":      true,
+	"COLUMNS":   true,
+	"ISFORMULA": true,
+	"CELL":      true,
+}
+
+// TakesReference reports whether a single cell written as the given argument must be passed as a Range
+func TakesReference(name string, arg int) bool {
+	pos, ok := referenceArgs[strings.ToUpper(name)]
+	return ok && pos == arg
+}
+
+// TakesAddress reports whether the given argument is a reference of which only the address is read, so
+// its cells need not be calculated first and the formula does not depend on them, like the A1 of ROW(A1)
+func TakesAddress(name string, arg int) bool {
+	return TakesReference(name, arg) && addressOnly[strings.ToUpper(name)]
+}
+
+// subRange is a block inside another range
+type subRange struct {
+	base                   Range
+	top, left, rows, cols int
+}
+
+func (s subRange) Dims() (int, int) {
+	return s.rows, s.cols
+}
+
+func (s subRange) At(row, col int) any {
+	if row < 0 || col < 0 || row >= s.rows || col >= s.cols {
+		return nil
+	}
+	return s.base.At(s.top+row, s.left+col)
+}
+
+func (s subRange) Each(fn func(row, col int, value any) bool) {
+	s.base.Each(func(row, col int, value any) bool {
+		row, col = row-s.top, col-s.left
+		if row < 0 || col < 0 || row >= s.rows || col >= s.cols {
+			return true
+		}
+		return fn(row, col, value)
+	})
+}
+
+// rowOf and columnOf return a single row or column of a range
+func rowOf(r Range, row int) Range {
+	_, cols := r.Dims()
+	return subRange{base: r, top: row, rows: 1, cols: cols}
+}
+
+func columnOf(r Range, col int) Range {
+	rows, _ := r.Dims()
+	return subRange{base: r, left: col, rows: rows, cols: 1}
+}
+
+// scalar returns the value of a single-cell range and any other value unchanged
+func scalar(v any) any {
+	if r, ok := v.(Range); ok {
+		if rows, cols := r.Dims(); rows == 1 && cols == 1 {
+			return r.At(0, 0)
+		}
+	}
+	return v
+}
+
+// Match modes shared by MATCH, VLOOKUP, HLOOKUP and XLOOKUP
+const (
+	matchExact       = 0
+	matchNextSmaller = -1 // exact, or else the largest value below
+	matchNextLarger  = 1  // exact, or else the smallest value above
+	matchWildcard    = 2  // exact, where * and ? in the lookup text match any characters
+	matchSortedAsc   = 3  // the last value not above, the data being in ascending order
+	matchSortedDesc  = 4  // the last value not below, the data being in descending order
+)
+
+// findInVector returns the zero-based position of value in a one-row or one-column range, or -1.
+// With reverse set the last match wins instead of the first.
+func findInVector(vec Range, value any, mode int, reverse bool) int {
+	rows, _ := vec.Dims()
+	vertical := rows > 1
+
+	text, isText := value.(string)
+	wildcard := isText && mode == matchWildcard && hasWildcards(text)
+
+	exact, best := -1, -1
+	var bestValue any
+	vec.Each(func(row, col int, v any) bool {
+		if !sameKind(v, value) {
+			return true
+		}
+		pos := col
+		if vertical {
+			pos = row
+		}
+		order := compareValues(v, value)
+
+		switch mode {
+		case matchSortedAsc, matchSortedDesc:
+			if (mode == matchSortedAsc && order > 0) || (mode == matchSortedDesc && order < 0) {
+				return false
+			}
+			best = pos
+			return true
+		}
+
+		matched := order == 0
+		if wildcard {
+			matched = wildcardMatch(text, v.(string))
+		}
+		if matched {
+			exact = pos
+			return reverse
+		}
+
+		if (mode == matchNextSmaller && order < 0) || (mode == matchNextLarger && order > 0) {
+			closer := best < 0
+			if !closer {
+				diff := compareValues(v, bestValue)
+				closer = (mode == matchNextSmaller && diff > 0) || (mode == matchNextLarger && diff < 0) || (diff == 0 && reverse)
+			}
+			if closer {
+				best, bestValue = pos, v
+			}
+		}
+		return true
+	})
+
+	if exact >= 0 {
+		return exact
+	}
+	if mode == matchExact || mode == matchWildcard {
+		return -1
+	}
+	return best
+}
+
+// sameKind reports whether two values can be matched against each other: both numbers, both texts or both booleans
+func sameKind(a, b any) bool {
+	switch a.(type) {
+	case float64, int:
+		switch b.(type) {
+		case float64, int:
+			return true
+		}
+	case string:
+		_, ok := b.(string)
+		return ok
+	case bool:
+		_, ok := b.(bool)
+		return ok
+	}
+	return false
+}
+
+// hasWildcards reports whether text is a pattern for wildcardMatch rather than plain text
+func hasWildcards(text string) bool {
+	return strings.ContainsAny(text, "*?~")
+}
+
+// wildcardMatch matches text against a pattern where * stands for any run of characters and ? for one,
+// ignoring case. A ~ makes the *, ? or ~ after it stand for itself.
+func wildcardMatch(pattern, text string) bool {
+	type token struct {
+		r           rune
+		any, anyRun bool
+	}
+	var tokens []token
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '~' && i+1 < len(runes) && strings.ContainsRune("*?~", runes[i+1]):
+			i++
+			tokens = append(tokens, token{r: runes[i]})
+		case r == '*':
+			tokens = append(tokens, token{anyRun: true})
+		case r == '?':
+			tokens = append(tokens, token{any: true})
+		default:
+			tokens = append(tokens, token{r: unicode.ToLower(r)})
+		}
+	}
+
+	// Walk both, going back to the last * to let it take one more character when the rest fails to match
+	chars := []rune(strings.ToLower(text))
+	t, c := 0, 0
+	star, starChar := -1, 0
+	for c < len(chars) {
+		switch {
+		case t < len(tokens) && tokens[t].anyRun:
+			star, starChar = t, c
+			t++
+		case t < len(tokens) && (tokens[t].any || tokens[t].r == chars[c]):
+			t++
+			c++
+		case star >= 0:
+			starChar++
+			t, c = star+1, starChar
+		default:
+			return false
+		}
+	}
+	for t < len(tokens) && tokens[t].anyRun {
+		t++
+	}
+	return t == len(tokens)
+}
+
+// toBool reads a logical argument, accepting numbers like Excel does
+func toBool(v any) (bool, error) {
+	switch val := scalar(v).(type) {
+	case bool:
+		return val, nil
+	case nil:
+		return false, nil
+	}
+	f, err := toFloat(v)
+	if err != nil {
+		return false, newError(ErrValue, "cannot convert %v to a logical value", v)
+	}
+	return f != 0, nil
+}
+
+// toIndex reads a whole-number position argument
+func toIndex(v any) (int, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return 0, newError(ErrValue, "%v is not a number", v)
+	}
+	return int(f), nil
+}
+
+// lookupResult turns the cell found by a lookup into its value, a blank cell reading as 0
+func lookupResult(v any) any {
+	if v == nil {
+		return 0.0
+	}
+	return v
+}
+
+func LookupFunctions() map[string]ExprFunction {
+	return map[string]ExprFunction{
+		"VLOOKUP": func(args ...any) (any, error) {
+			return tableLookup("VLOOKUP", true, args)
+		},
+
+		"HLOOKUP": func(args ...any) (any, error) {
+			return tableLookup("HLOOKUP", false, args)
+		},
+
+		"MATCH": func(args ...any) (any, error) {
+			if err := validateArgs("MATCH", args, 2, 3); err != nil {
+				return nil, err
+			}
+			vec, ok := args[1].(Range)
+			if !ok {
+				return nil, newError(ErrNA, "MATCH: the lookup array must be a range")
+			}
+			if rows, cols := vec.Dims(); rows > 1 && cols > 1 {
+				return nil, newError(ErrNA, "MATCH: the lookup array must be a single row or column")
+			}
+
+			matchType := 1
+			if len(args) == 3 {
+				t, err := toIndex(args[2])
+				if err != nil {
+					return nil, err
+				}
+				matchType = t
+			}
+
+			mode := matchSortedAsc
+			switch {
+			case matchType == 0:
+				mode = matchWildcard
+			case matchType < 0:
+				mode = matchSortedDesc
+			}
+
+			pos := findInVector(vec, scalar(args[0]), mode, false)
+			if pos < 0 {
+				return nil, ErrNA
+			}
+			return float64(pos + 1), nil
+		},
+
+		"INDEX": func(args ...any) (any, error) {
+			if err := validateArgs("INDEX", args, 2, 3); err != nil {
+				return nil, err
+			}
+			r, ok := args[0].(Range)
+			if !ok {
+				if len(args) == 2 || args[2] == nil {
+					return args[0], nil
+				}
+				return nil, newError(ErrValue, "INDEX: the first argument must be a range")
+			}
+			rows, cols := r.Dims()
+
+			row, err := toIndex(args[1])
+			if err != nil {
+				return nil, err
+			}
+			col := 0
+			if len(args) == 3 {
+				if col, err = toIndex(args[2]); err != nil {
+					return nil, err
+				}
+			} else if rows == 1 {
+				// A single row is indexed along its columns
+				row, col = 1, row
+			} else if cols == 1 {
+				col = 1
+			}
+
+			if row < 0 || col < 0 || row > rows || col > cols {
+				return nil, newError(ErrRef, "INDEX: position outside of the range")
+			}
+			switch {
+			case row == 0 && col == 0:
+				return r, nil
+			case row == 0:
+				return columnOf(r, col-1), nil
+			case col == 0:
+				return rowOf(r, row-1), nil
+			}
+			return lookupResult(r.At(row-1, col-1)), nil
+		},
+
+		"XLOOKUP": func(args ...any) (any, error) {
+			if err := validateArgs("XLOOKUP", args, 3, 6); err != nil {
+				return nil, err
+			}
+			lookupArray, ok1 := args[1].(Range)
+			returnArray, ok2 := args[2].(Range)
+			if !ok1 || !ok2 {
+				return nil, newError(ErrValue, "XLOOKUP: the lookup and return arrays must be ranges")
+			}
+
+			lookRows, lookCols := lookupArray.Dims()
+			retRows, retCols := returnArray.Dims()
+			vertical := lookCols == 1 && lookRows > 1
+			switch {
+			case lookRows > 1 && lookCols > 1:
+				return nil, newError(ErrValue, "XLOOKUP: the lookup array must be a single row or column")
+			case vertical && retRows != lookRows, !vertical && retCols != lookCols:
+				return nil, newError(ErrValue, "XLOOKUP: the lookup and return arrays must have the same length")
+			}
+
+			mode := matchExact
+			if len(args) >= 5 && args[4] != nil {
+				m, err := toIndex(args[4])
+				if err != nil {
+					return nil, err
+				}
+				if m < -1 || m > 2 {
+					return nil, newError(ErrValue, "XLOOKUP: match mode must be -1, 0, 1 or 2")
+				}
+				mode = m
+			}
+			reverse := false
+			if len(args) == 6 && args[5] != nil {
+				s, err := toIndex(args[5])
+				if err != nil {
+					return nil, err
+				}
+				switch s {
+				case 1, 2:
+				case -1, -2:
+					reverse = true
+				default:
+					return nil, newError(ErrValue, "XLOOKUP: search mode must be 1, -1, 2 or -2")
+				}
+			}
+
+			pos := findInVector(lookupArray, scalar(args[0]), mode, reverse)
+			if pos < 0 {
+				if len(args) >= 4 && args[3] != nil {
+					return args[3], nil
+				}
+				return nil, ErrNA
+			}
+
+			if vertical {
+				if retCols == 1 {
+					return lookupResult(returnArray.At(pos, 0)), nil
+				}
+				return rowOf(returnArray, pos), nil
+			}
+			if retRows == 1 {
+				return lookupResult(returnArray.At(0, pos)), nil
+			}
+			return columnOf(returnArray, pos), nil
+		},
+
+		"INDIRECT": func(args ...any) (any, error) {
+			if err := validateArgs("INDIRECT", args, 2, 3); err != nil {
+				return nil, err
+			}
+			resolver, ok := args[0].(Resolver)
+			if !ok {
+				return nil, newError(ErrRef, "INDIRECT: no workbook to resolve references in")
+			}
+			if len(args) == 3 {
+				a1, err := toBool(args[2])
+				if err != nil {
+					return nil, err
+				}
+				if !a1 {
+					return nil, newError(ErrRef, "INDIRECT: R1C1 references are not supported")
+				}
+			}
+			return resolver.Reference(strings.TrimSpace(toString(scalar(args[1]))))
+		},
+
+		"OFFSET": func(args ...any) (any, error) {
+			if err := validateArgs("OFFSET", args, 4, 6); err != nil {
+				return nil, err
+			}
+			resolver, ok := args[0].(Resolver)
+			if !ok {
+				return nil, newError(ErrRef, "OFFSET: no workbook to resolve references in")
+			}
+			base, ok := args[1].(Range)
+			if !ok {
+				return nil, newError(ErrValue, "OFFSET: the first argument must be a reference")
+			}
+
+			rows, err := toIndex(args[2])
+			if err != nil {
+				return nil, err
+			}
+			cols, err := toIndex(args[3])
+			if err != nil {
+				return nil, err
+			}
+			height, width := base.Dims()
+			if len(args) >= 5 && args[4] != nil {
+				if height, err = toIndex(args[4]); err != nil {
+					return nil, err
+				}
+			}
+			if len(args) == 6 && args[5] != nil {
+				if width, err = toIndex(args[5]); err != nil {
+					return nil, err
+				}
+			}
+			if height < 1 || width < 1 {
+				return nil, newError(ErrRef, "OFFSET: height and width must be at least 1")
+			}
+
+			return resolver.Offset(base, rows, cols, height, width)
+		},
+	}
+}
+
+// tableLookup implements VLOOKUP (searching down the first column) and HLOOKUP (across the first row)
+func tableLookup(name string, vertical bool, args []any) (any, error) {
+	if err := validateArgs(name, args, 3, 4); err != nil {
+		return nil, err
+	}
+	table, ok := args[1].(Range)
+	if !ok {
+		return nil, newError(ErrValue, "%s: the table must be a range", name)
+	}
+
+	index, err := toIndex(args[2])
+	if err != nil {
+		return nil, err
+	}
+	rows, cols := table.Dims()
+	size := cols
+	if !vertical {
+		size = rows
+	}
+	if index < 1 {
+		return nil, newError(ErrValue, "%s: the index must be at least 1", name)
+	}
+	if index > size {
+		return nil, newError(ErrRef, "%s: the index is outside of the table", name)
+	}
+
+	approximate := true
+	if len(args) == 4 {
+		if approximate, err = toBool(args[3]); err != nil {
+			return nil, err
+		}
+	}
+	mode := matchWildcard
+	if approximate {
+		mode = matchSortedAsc
+	}
+
+	if vertical {
+		pos := findInVector(columnOf(table, 0), scalar(args[0]), mode, false)
+		if pos < 0 {
+			return nil, ErrNA
+		}
+		return lookupResult(table.At(pos, index-1)), nil
+	}
+
+	pos := findInVector(rowOf(table, 0), scalar(args[0]), mode, false)
+	if pos < 0 {
+		return nil, ErrNA
+	}
+	return lookupResult(table.At(index-1, pos)), nil
+}
@@ -55,7 +55,7 @@ func MathFunctions() map[string]ExprFunction {
 			}
 			tanVal := math.Tan(f)
 			if math.Abs(tanVal) < 1e-10 {
-				return math.Inf(1), fmt.Errorf("CTAN: division by zero")
+				return nil, newError(ErrDiv0, "CTAN: division by zero")
 			}
 			return 1 / tanVal, nil
 		},
@@ -126,7 +126,7 @@ func MathFunctions() map[string]ExprFunction {
 				return nil, fmt.Errorf("SEC: %v", err)
 			}
 			if math.Cos(f) == 0 {
-				return math.Inf(0), fmt.Errorf("division by zero")
+				return nil, newError(ErrDiv0, "division by zero")
 			}
 			return 1 / math.Cos(f), nil
 		},
@@ -139,7 +139,7 @@ func MathFunctions() map[string]ExprFunction {
 				return nil, fmt.Errorf("CSEC: %v", err)
 			}
 			if math.Sin(f) == 0 {
-				return math.Inf(0), fmt.Errorf("division by zero")
+				return nil, newError(ErrDiv0, "division by zero")
 			}
 			return 1 / math.Sin(f), nil
 		},
@@ -226,7 +226,7 @@ func MathFunctions() map[string]ExprFunction {
 				return nil, fmt.Errorf("CTANH: %v", err)
 			}
 			if math.Tanh(f) == 0 {
-				return math.Inf(0), fmt.Errorf("division by zero")
+				return nil, newError(ErrDiv0, "division by zero")
 			}
 			return 1 / math.Tanh(f), nil
 		},
@@ -241,7 +241,7 @@ func MathFunctions() map[string]ExprFunction {
 				return nil, fmt.Errorf("SECH: %v", err)
 			}
 			if math.Cosh(f) == 0 {
-				return math.Inf(0), fmt.Errorf("division by zero")
+				return nil, newError(ErrDiv0, "division by zero")
 			}
 			return 1 / math.Cosh(f), nil
 		},
@@ -254,7 +254,7 @@ func MathFunctions() map[string]ExprFunction {
 				return nil, fmt.Errorf("CSCH: %v", err)
 			}
 			if math.Sinh(f) == 0 {
-				return math.Inf(0), fmt.Errorf("division by zero")
+				return nil, newError(ErrDiv0, "division by zero")
 			}
 			return 1 / math.Sinh(f), nil
 		},
@@ -439,9 +439,16 @@ func MathFunctions() map[string]ExprFunction {
 			return math.Round(f), nil
 		},
 		"MIN": func(args ...any) (any, error) {
-			if err := validateArgs("MIN", args, 2, -1); err != nil {
+			if err := validateArgs("MIN", args, 1, -1); err != nil {
 				return nil, err
 			}
+			args, err := flattenArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			if len(args) == 0 {
+				return 0.0, nil
+			}
 			f, err := toFloat(args[0])
 			if err != nil {
 				return nil, fmt.Errorf("MIN: %v", err)
@@ -457,9 +464,16 @@ func MathFunctions() map[string]ExprFunction {
 			return minNR, nil
 		},
 		"MAX": func(args ...any) (any, error) {
-			if err := validateArgs("MAX", args, 2, -1); err != nil {
+			if err := validateArgs("MAX", args, 1, -1); err != nil {
 				return nil, err
 			}
+			args, err := flattenArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			if len(args) == 0 {
+				return 0.0, nil
+			}
 			f, err := toFloat(args[0])
 			if err != nil {
 				return nil, fmt.Errorf("MAX: %v", err)
@@ -794,6 +808,40 @@ func MathFunctions() map[string]ExprFunction {
 			return float64(a / gcd * b), nil
 		},
 
+		// Random numbers, drawn by the formula engine so a workbook can fix its seed
+		"RAND": func(args ...any) (any, error) {
+			if err := validateArgs("RAND", args, 1, 1); err != nil {
+				return nil, err
+			}
+			resolver, ok := args[0].(Resolver)
+			if !ok {
+				return nil, newError(ErrValue, "RAND: no workbook to draw random numbers from")
+			}
+			return resolver.Random(), nil
+		},
+		"RANDBETWEEN": func(args ...any) (any, error) {
+			if err := validateArgs("RANDBETWEEN", args, 3, 3); err != nil {
+				return nil, err
+			}
+			resolver, ok := args[0].(Resolver)
+			if !ok {
+				return nil, newError(ErrValue, "RANDBETWEEN: no workbook to draw random numbers from")
+			}
+			bottom, err := toFloat(args[1])
+			if err != nil {
+				return nil, fmt.Errorf("RANDBETWEEN: %v", err)
+			}
+			top, err := toFloat(args[2])
+			if err != nil {
+				return nil, fmt.Errorf("RANDBETWEEN: %v", err)
+			}
+			low, high := math.Ceil(bottom), math.Floor(top)
+			if low > high {
+				return nil, newError(ErrNum, "RANDBETWEEN: bottom must not be greater than top")
+			}
+			return low + math.Floor(resolver.Random()*(high-low+1)), nil
+		},
+
 		// Constants
 		"PI":  func(args ...any) (any, error) { return math.Pi, nil },
 		"E":   func(args ...any) (any, error) { return math.E, nil },
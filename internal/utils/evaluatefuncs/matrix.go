@@ -0,0 +1,259 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// matrix.go provides the functions treating a range as a matrix of numbers, for linear algebra
+
+package evaluatefuncs
+
+import (
+	"math"
+)
+
+// singularTolerance is how small a pivot may get, against the largest value of the matrix, before the
+// matrix counts as singular and has no inverse
+const singularTolerance = 1e-12
+
+// matrixOf reads a range, an array or a single value as a matrix. Every value must be a number: an
+// error inside is passed on, and a blank or text is #VALUE!.
+func matrixOf(name string, v any) ([][]float64, error) {
+	a, err := toArray(v)
+	if err != nil {
+		return nil, err
+	}
+	rows, cols := a.Dims()
+	if rows == 0 || cols == 0 {
+		return nil, newError(ErrValue, "%s: the matrix is empty", name)
+	}
+
+	m := make([][]float64, rows)
+	for r := range m {
+		m[r] = make([]float64, cols)
+		for c := range m[r] {
+			switch value := a[r][c].(type) {
+			case float64:
+				m[r][c] = value
+			case int:
+				m[r][c] = float64(value)
+			case ErrorValue:
+				return nil, value
+			default:
+				return nil, newError(ErrValue, "%s: the matrix holds a value which is not a number at row %d, column %d", name, r+1, c+1)
+			}
+		}
+	}
+	return m, nil
+}
+
+// squareMatrixOf reads an argument as a matrix with as many rows as columns
+func squareMatrixOf(name string, v any) ([][]float64, error) {
+	m, err := matrixOf(name, v)
+	if err != nil {
+		return nil, err
+	}
+	if len(m) != len(m[0]) {
+		return nil, newError(ErrValue, "%s: the matrix has %d rows and %d columns, it must be square", name, len(m), len(m[0]))
+	}
+	return m, nil
+}
+
+// toResult turns a matrix into the array a formula spills
+func toResult(m [][]float64) (Array, error) {
+	result, err := newArray(len(m), len(m[0]))
+	if err != nil {
+		return nil, err
+	}
+	for r := range m {
+		for c := range m[r] {
+			result[r][c] = m[r][c]
+		}
+	}
+	return result, nil
+}
+
+// largestAbs returns the largest absolute value of a matrix, the scale a pivot is measured against
+func largestAbs(m [][]float64) float64 {
+	largest := 0.0
+	for _, row := range m {
+		for _, v := range row {
+			largest = max(largest, math.Abs(v))
+		}
+	}
+	return largest
+}
+
+// pivot swaps the row with the largest value of column col, from row col down, into row col of the
+// matrices, returning whether rows were swapped
+func pivot(col int, matrices ...[][]float64) bool {
+	m := matrices[0]
+	best := col
+	for r := col + 1; r < len(m); r++ {
+		if math.Abs(m[r][col]) > math.Abs(m[best][col]) {
+			best = r
+		}
+	}
+	if best == col {
+		return false
+	}
+	for _, matrix := range matrices {
+		matrix[col], matrix[best] = matrix[best], matrix[col]
+	}
+	return true
+}
+
+// determinant computes the determinant of a square matrix by Gaussian elimination, changing m
+func determinant(m [][]float64) float64 {
+	det := 1.0
+	for col := range m {
+		if pivot(col, m) {
+			det = -det
+		}
+		if m[col][col] == 0 {
+			return 0
+		}
+		det *= m[col][col]
+		for r := col + 1; r < len(m); r++ {
+			factor := m[r][col] / m[col][col]
+			for c := col; c < len(m); c++ {
+				m[r][c] -= factor * m[col][c]
+			}
+		}
+	}
+	return det
+}
+
+// inverse computes the inverse of a square matrix by Gauss-Jordan elimination, changing m.
+// It reports false when the matrix is singular.
+func inverse(m [][]float64) ([][]float64, bool) {
+	n := len(m)
+	inv := make([][]float64, n)
+	for r := range inv {
+		inv[r] = make([]float64, n)
+		inv[r][r] = 1
+	}
+
+	tolerance := largestAbs(m) * singularTolerance
+	for col := 0; col < n; col++ {
+		pivot(col, m, inv)
+		p := m[col][col]
+		if math.Abs(p) <= tolerance {
+			return nil, false
+		}
+		for c := 0; c < n; c++ {
+			m[col][c] /= p
+			inv[col][c] /= p
+		}
+		for r := 0; r < n; r++ {
+			if r == col || m[r][col] == 0 {
+				continue
+			}
+			factor := m[r][col]
+			for c := 0; c < n; c++ {
+				m[r][c] -= factor * m[col][c]
+				inv[r][c] -= factor * inv[col][c]
+			}
+		}
+	}
+	return inv, true
+}
+
+func MatrixFunctions() map[string]ExprFunction {
+	return map[string]ExprFunction{
+		"MMULT": func(args ...any) (any, error) {
+			if err := validateArgs("MMULT", args, 2, 2); err != nil {
+				return nil, err
+			}
+			a, err := matrixOf("MMULT", args[0])
+			if err != nil {
+				return nil, err
+			}
+			b, err := matrixOf("MMULT", args[1])
+			if err != nil {
+				return nil, err
+			}
+			if len(a[0]) != len(b) {
+				return nil, newError(ErrValue, "MMULT: the first matrix has %d columns but the second %d rows", len(a[0]), len(b))
+			}
+
+			product := make([][]float64, len(a))
+			for r := range product {
+				product[r] = make([]float64, len(b[0]))
+				for c := range product[r] {
+					for k := range b {
+						product[r][c] += a[r][k] * b[k][c]
+					}
+				}
+			}
+			return toResult(product)
+		},
+
+		"MDETERM": func(args ...any) (any, error) {
+			if err := validateArgs("MDETERM", args, 1, 1); err != nil {
+				return nil, err
+			}
+			m, err := squareMatrixOf("MDETERM", args[0])
+			if err != nil {
+				return nil, err
+			}
+			return determinant(m), nil
+		},
+
+		"MINVERSE": func(args ...any) (any, error) {
+			if err := validateArgs("MINVERSE", args, 1, 1); err != nil {
+				return nil, err
+			}
+			m, err := squareMatrixOf("MINVERSE", args[0])
+			if err != nil {
+				return nil, err
+			}
+			inv, ok := inverse(m)
+			if !ok {
+				return nil, newError(ErrNum, "MINVERSE: the matrix is singular and has no inverse")
+			}
+			return toResult(inv)
+		},
+
+		"SUMPRODUCT": func(args ...any) (any, error) {
+			if err := validateArgs("SUMPRODUCT", args, 1, 255); err != nil {
+				return nil, err
+			}
+			arrays := make([]Array, len(args))
+			for i, arg := range args {
+				a, err := toArray(arg)
+				if err != nil {
+					return nil, err
+				}
+				arrays[i] = a
+			}
+			rows, cols := arrays[0].Dims()
+			for _, a := range arrays[1:] {
+				if r, c := a.Dims(); r != rows || c != cols {
+					return nil, newError(ErrValue, "SUMPRODUCT: the arrays are %d x %d and %d x %d, they must have the same size", rows, cols, r, c)
+				}
+			}
+
+			// Like Excel, a value which is not a number counts as zero
+			sum := 0.0
+			for r := 0; r < rows; r++ {
+				for c := 0; c < cols; c++ {
+					product := 1.0
+					for _, a := range arrays {
+						switch value := a[r][c].(type) {
+						case float64:
+							product *= value
+						case int:
+							product *= float64(value)
+						case ErrorValue:
+							return nil, value
+						default:
+							product = 0
+						}
+					}
+					sum += product
+				}
+			}
+			return sum, nil
+		},
+	}
+}
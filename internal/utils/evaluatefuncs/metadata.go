@@ -0,0 +1,204 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// metadata.go describes the registered functions, for the function browser, the formula editor and the
+// argument counts checked before a formula runs
+
+package evaluatefuncs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Function categories, shown by the function browser
+const (
+	CategoryMath        = "Math & Trigonometry"
+	CategoryStatistical = "Statistical"
+	CategoryConditional = "Conditional Aggregates"
+	CategoryFinancial   = "Financial"
+	CategoryText        = "Text"
+	CategoryDateTime    = "Date & Time"
+	CategoryLogical     = "Logical"
+	CategoryInformation = "Information"
+	CategoryErrors      = "Error Handling"
+	CategoryLookup      = "Lookup & Reference"
+	CategoryArray       = "Dynamic Arrays"
+	CategoryNames       = "Names & Functions"
+	CategoryEngineering = "Engineering"
+	CategoryBitwise     = "Bitwise"
+	CategoryConstants   = "Constants"
+	CategoryWorkbook    = "Workbook Functions"
+	CategoryPlugin      = "Plugins"
+)
+
+// FunctionInfo describes a function: what it does, its arguments and an example call
+type FunctionInfo struct {
+	Name        string
+	Category    string
+	Description string
+	Args        []ArgInfo
+	Example     string
+	Volatile    bool // its result changes without any of its arguments changing, like NOW
+}
+
+// ArgInfo describes one argument of a function
+type ArgInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Optional    bool   `json:"optional"`
+	Repeats     bool   `json:"repeats"` // it and the arguments after it may be given again, like the numbers of SUM
+}
+
+// arg, opt and more build the arguments of the catalog: required, optional, and repeated as often as needed
+func arg(name, description string) ArgInfo {
+	return ArgInfo{Name: name, Description: description}
+}
+
+func opt(name, description string) ArgInfo {
+	return ArgInfo{Name: name, Description: description, Optional: true}
+}
+
+func more(name, description string) ArgInfo {
+	return ArgInfo{Name: name, Description: description, Optional: true, Repeats: true}
+}
+
+// MinArgs returns how many arguments a call needs at least
+func (f FunctionInfo) MinArgs() int {
+	n := 0
+	for _, a := range f.Args {
+		if !a.Optional {
+			n++
+		}
+	}
+	return n
+}
+
+// MaxArgs returns how many arguments a call takes at most, -1 for any number
+func (f FunctionInfo) MaxArgs() int {
+	for _, a := range f.Args {
+		if a.Repeats {
+			return -1
+		}
+	}
+	return len(f.Args)
+}
+
+// Signature returns how a call is written, like SUMIF(range, criteria, [sum_range])
+func (f FunctionInfo) Signature() string {
+	parts := make([]string, 0, len(f.Args)+1)
+	_, last := f.repeated()
+	for i, a := range f.Args {
+		parts = append(parts, a.Label())
+		if i == last {
+			parts = append(parts, "...")
+		}
+	}
+	return fmt.Sprintf("%s(%s)", f.Name, strings.Join(parts, ", "))
+}
+
+// repeated returns the first and last of the arguments which may be given again, -1 when there are none
+func (f FunctionInfo) repeated() (int, int) {
+	first, last := -1, -1
+	for i, a := range f.Args {
+		if a.Repeats {
+			if first == -1 {
+				first = i
+			}
+			last = i
+		}
+	}
+	return first, last
+}
+
+// Label returns the argument as shown in a signature, in brackets when it can be left out
+func (a ArgInfo) Label() string {
+	if a.Optional {
+		return "[" + a.Name + "]"
+	}
+	return a.Name
+}
+
+// ArgAt returns which of Args the argument at pos of a call with count arguments is. Repeated arguments
+// cover every position up to the ones after them, like the calculation closing a LET.
+func (f FunctionInfo) ArgAt(pos, count int) (int, bool) {
+	first, last := f.repeated()
+	if first == -1 || pos < first {
+		return pos, pos >= 0 && pos < len(f.Args)
+	}
+	trailing := len(f.Args) - 1 - last
+	if tail := count - trailing; pos >= tail && tail > first {
+		return last + 1 + pos - tail, true
+	}
+	group := last - first + 1
+	return first + (pos-first)%group, true
+}
+
+// CheckArgs returns the #ARGS! error of a call with count arguments, or nil when the count suits the function
+func (f FunctionInfo) CheckArgs(count int) error {
+	return checkArity(f.Name, count, f.MinArgs(), f.MaxArgs())
+}
+
+// builtinInfo indexes the catalog by name
+var builtinInfo = func() map[string]FunctionInfo {
+	index := make(map[string]FunctionInfo, len(catalog))
+	for _, f := range catalog {
+		index[f.Name] = f
+	}
+	return index
+}()
+
+// LookupFunction returns the description of a built-in or plugin function
+func LookupFunction(name string) (FunctionInfo, bool) {
+	name = strings.ToUpper(name)
+	if f, ok := builtinInfo[name]; ok {
+		return f, true
+	}
+	for _, fn := range PluginManifest() {
+		if fn.Name == name {
+			return fn.info(), true
+		}
+	}
+	return FunctionInfo{}, false
+}
+
+// Functions returns the descriptions of every built-in and plugin function, in name order
+func Functions() []FunctionInfo {
+	functions := append([]FunctionInfo(nil), catalog...)
+	for _, fn := range PluginManifest() {
+		functions = append(functions, fn.info())
+	}
+	sort.SliceStable(functions, func(i, j int) bool { return functions[i].Name < functions[j].Name })
+	return functions
+}
+
+// IsVolatile reports whether a function must be recalculated even when nothing it reads has changed
+func IsVolatile(name string) bool {
+	return builtinInfo[strings.ToUpper(name)].Volatile
+}
+
+// info describes a plugin function, naming its arguments after their position when the manifest does not
+func (fn PluginFunction) info() FunctionInfo {
+	info := FunctionInfo{Name: fn.Name, Category: CategoryPlugin, Description: fn.Description, Args: fn.Args}
+	if len(info.Args) > 0 {
+		return info
+	}
+	maxArgs := fn.MaxArgs
+	if maxArgs == -1 {
+		maxArgs = fn.MinArgs
+	}
+	for i := 1; i <= maxArgs; i++ {
+		if i <= fn.MinArgs {
+			info.Args = append(info.Args, arg(fmt.Sprintf("arg%d", i), ""))
+		} else {
+			info.Args = append(info.Args, opt(fmt.Sprintf("arg%d", i), ""))
+		}
+	}
+	if fn.MaxArgs == -1 {
+		info.Args = append(info.Args, more(fmt.Sprintf("arg%d", maxArgs+1), ""))
+	}
+	return info
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// operators.go provides the arithmetic and comparison operators of formulas, so that
+// error values pass through them, blank cells count as zero and arrays are computed value by value
+
+package evaluatefuncs
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// BinaryOperator is an operator of the formula language evaluated by a function
+type BinaryOperator struct {
+	Symbol string // the operator as written in a formula
+	Name   string // the name the function is registered under
+	Fn     ExprFunction
+}
+
+func BinaryOperators() []BinaryOperator {
+	arithmetic := func(symbol, name string, apply func(x, y float64) (any, error)) BinaryOperator {
+		return BinaryOperator{Symbol: symbol, Name: name, Fn: elementwise(func(args ...any) (any, error) {
+			args = []any{scalar(args[0]), scalar(args[1])}
+			if ev, ok := operandError(args); ok {
+				return ev, nil
+			}
+			if symbol == "+" {
+				if s, ok := concatStrings(args[0], args[1]); ok {
+					return s, nil
+				}
+			}
+			x, okX := toOperand(args[0])
+			y, okY := toOperand(args[1])
+			if !okX || !okY {
+				return ErrValue, nil
+			}
+			result, err := apply(x, y)
+			if err != nil {
+				return ErrorOf(err), nil
+			}
+			if f, ok := result.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+				return ErrNum, nil
+			}
+			return result, nil
+		})}
+	}
+
+	comparison := func(symbol, name string, holds func(order int) bool) BinaryOperator {
+		return BinaryOperator{Symbol: symbol, Name: name, Fn: elementwise(func(args ...any) (any, error) {
+			args = []any{scalar(args[0]), scalar(args[1])}
+			if ev, ok := operandError(args); ok {
+				return ev, nil
+			}
+			return holds(compareValues(args[0], args[1])), nil
+		})}
+	}
+
+	power := func(x, y float64) (any, error) { return math.Pow(x, y), nil }
+
+	return []BinaryOperator{
+		arithmetic("+", "OP_ADD", func(x, y float64) (any, error) { return x + y, nil }),
+		arithmetic("-", "OP_SUB", func(x, y float64) (any, error) { return x - y, nil }),
+		arithmetic("*", "OP_MUL", func(x, y float64) (any, error) { return x * y, nil }),
+		arithmetic("/", "OP_DIV", func(x, y float64) (any, error) {
+			if y == 0 {
+				return nil, newError(ErrDiv0, "division by zero")
+			}
+			return x / y, nil
+		}),
+		arithmetic("%", "OP_MOD", func(x, y float64) (any, error) {
+			if y == 0 {
+				return nil, newError(ErrDiv0, "division by zero")
+			}
+			return math.Mod(x, y), nil
+		}),
+		arithmetic("^", "OP_POW", power),
+		arithmetic("**", "OP_POW2", power),
+
+		comparison("==", "OP_EQ", func(order int) bool { return order == 0 }),
+		comparison("!=", "OP_NE", func(order int) bool { return order != 0 }),
+		comparison("<", "OP_LT", func(order int) bool { return order < 0 }),
+		comparison("<=", "OP_LE", func(order int) bool { return order <= 0 }),
+		comparison(">", "OP_GT", func(order int) bool { return order > 0 }),
+		comparison(">=", "OP_GE", func(order int) bool { return order >= 0 }),
+	}
+}
+
+// operandError returns the first error value among the operands
+func operandError(args []any) (ErrorValue, bool) {
+	for _, arg := range args {
+		if ev, ok := arg.(ErrorValue); ok {
+			return ev, true
+		}
+	}
+	return ErrorValue{}, false
+}
+
+// toOperand converts an operand of an arithmetic operator to a number; blanks count as 0 and TRUE as 1
+func toOperand(v any) (float64, bool) {
+	switch val := v.(type) {
+	case nil:
+		return 0, true
+	case float64:
+		return val, true
+	case int:
+		return float64(val), true
+	case bool:
+		if val {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		s := strings.TrimSpace(val)
+		if s == "" {
+			return 0, true
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// concatStrings joins two texts with +, which formulas have always allowed
+func concatStrings(a, b any) (string, bool) {
+	sa, okA := a.(string)
+	sb, okB := b.(string)
+	if !okA || !okB {
+		return "", false
+	}
+	if _, err := strconv.ParseFloat(strings.TrimSpace(sa), 64); err == nil {
+		return "", false
+	}
+	if _, err := strconv.ParseFloat(strings.TrimSpace(sb), 64); err == nil {
+		return "", false
+	}
+	return sa + sb, true
+}
+
+// compareValues orders two values like a spreadsheet does: numbers before text before booleans,
+// text without regard to case, and a blank equal to 0 or to the empty text
+func compareValues(a, b any) int {
+	rank := func(v any) int {
+		switch v.(type) {
+		case float64, int, nil:
+			return 0
+		case string:
+			return 1
+		case bool:
+			return 2
+		}
+		return 3
+	}
+
+	if s, ok := a.(string); ok && s == "" && rank(b) == 0 {
+		a = 0.0
+	}
+	if s, ok := b.(string); ok && s == "" && rank(a) == 0 {
+		b = 0.0
+	}
+
+	ra, rb := rank(a), rank(b)
+	if ra != rb {
+		return ra - rb
+	}
+
+	switch ra {
+	case 0:
+		x, _ := toOperand(a)
+		y, _ := toOperand(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case 1:
+		return strings.Compare(strings.ToLower(a.(string)), strings.ToLower(b.(string)))
+	case 2:
+		x, y := a.(bool), b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(toString(a), toString(b))
+}
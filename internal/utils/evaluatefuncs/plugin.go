@@ -0,0 +1,438 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// plugin.go provides functions served by external executables, talking JSON over their stdin and stdout.
+//
+// Every executable in the plugin directory (~/.gosheet/plugins, or $GOSHEET_PLUGIN_DIR) is started once
+// and kept running. GoSheet writes one request per line and the plugin answers each with one line:
+//
+//	-> {"id":1,"method":"manifest"}
+//	<- {"id":1,"functions":[{"name":"VAT","min_args":1,"max_args":2,"description":"Adds VAT to an amount"}]}
+//	-> {"id":2,"method":"call","function":"VAT","args":[100,0.19]}
+//	<- {"id":2,"result":119}
+//	<- {"id":2,"error":{"code":"#NUM!","message":"VAT: the rate cannot be negative"}}
+//
+// Arguments and results are numbers, text, booleans or null for an empty cell. A range is an array of
+// rows, [[1,2],[3,4]], and an error value inside one is {"error":"#N/A"}. A result array spills like SORT's.
+
+package evaluatefuncs
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// pluginDirPath is where plugins are looked for, below the home directory
+const pluginDirPath = ".gosheet/plugins"
+
+var (
+	// pluginStartTimeout bounds how long a plugin may take to start and list its functions
+	pluginStartTimeout = 5 * time.Second
+
+	// pluginCallTimeout bounds a single call; a plugin going past it is stopped and started again on the next call
+	pluginCallTimeout = 3 * time.Second
+)
+
+// PluginFunction describes a function a plugin serves, as listed in its manifest
+type PluginFunction struct {
+	Name        string    `json:"name"`
+	MinArgs     int       `json:"min_args"`
+	MaxArgs     int       `json:"max_args"` // -1 for any number
+	Description string    `json:"description"`
+	Args        []ArgInfo `json:"args,omitempty"` // optional; when given, they set the argument counts
+	Plugin      string    `json:"-"`              // the file name of the executable serving it
+}
+
+type pluginRequest struct {
+	ID       int    `json:"id"`
+	Method   string `json:"method"`
+	Function string `json:"function,omitempty"`
+	Args     []any  `json:"args,omitempty"`
+}
+
+type pluginResponse struct {
+	ID        int              `json:"id"`
+	Functions []PluginFunction `json:"functions,omitempty"`
+	Result    any              `json:"result"`
+	Error     *pluginError     `json:"error,omitempty"`
+}
+
+type pluginError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// plugin is a running plugin executable; calls are made one at a time
+type plugin struct {
+	path string
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	nextID int
+}
+
+var (
+	pluginsMu     sync.Mutex
+	plugins       = make(map[string]*plugin) // by file name
+	pluginFuncs   []PluginFunction
+	pluginErrors  []error
+	pluginsLoaded bool
+)
+
+// PluginDir returns the directory plugins are loaded from
+func PluginDir() string {
+	if dir := os.Getenv("GOSHEET_PLUGIN_DIR"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, pluginDirPath)
+}
+
+// PluginFunctions returns the functions served by plugins, starting the plugins on first use.
+// A plugin cannot replace a built-in function, nor one already served by another plugin.
+func PluginFunctions() map[string]ExprFunction {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+
+	if !pluginsLoaded {
+		pluginsLoaded = true
+		loadPlugins(PluginDir())
+	}
+
+	functions := make(map[string]ExprFunction, len(pluginFuncs))
+	for _, fn := range pluginFuncs {
+		functions[fn.Name] = pluginCall(fn, plugins[fn.Plugin])
+	}
+	return functions
+}
+
+// PluginManifest returns the functions served by plugins, in name order
+func PluginManifest() []PluginFunction {
+	PluginFunctions()
+
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	return append([]PluginFunction(nil), pluginFuncs...)
+}
+
+// PluginErrors returns why plugins or some of their functions could not be loaded
+func PluginErrors() []error {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	return append([]error(nil), pluginErrors...)
+}
+
+// ClosePlugins stops every running plugin, when the application exits
+func ClosePlugins() {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	for _, p := range plugins {
+		p.mu.Lock()
+		p.stop()
+		p.mu.Unlock()
+	}
+}
+
+// loadPlugins starts every executable of dir and registers the functions they list
+func loadPlugins(dir string) {
+	if dir == "" {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			pluginErrors = append(pluginErrors, fmt.Errorf("plugins: %v", err))
+		}
+		return
+	}
+
+	builtins := builtinFunctions()
+	taken := make(map[string]string)
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if strings.HasPrefix(entry.Name(), ".") || !isExecutable(path) {
+			continue
+		}
+
+		p := &plugin{path: path}
+		resp, err := p.request(pluginRequest{Method: "manifest"}, pluginStartTimeout)
+		if err != nil {
+			p.stop()
+			pluginErrors = append(pluginErrors, fmt.Errorf("plugin %s: %v", entry.Name(), err))
+			continue
+		}
+
+		served := 0
+		for _, fn := range resp.Functions {
+			fn.Name = strings.ToUpper(strings.TrimSpace(fn.Name))
+			fn.Plugin = entry.Name()
+			switch {
+			case !validFunctionName(fn.Name):
+				pluginErrors = append(pluginErrors, fmt.Errorf("plugin %s: '%s' is not a valid function name", entry.Name(), fn.Name))
+				continue
+			case builtins[fn.Name] != nil:
+				pluginErrors = append(pluginErrors, fmt.Errorf("plugin %s: %s is already a built-in function", entry.Name(), fn.Name))
+				continue
+			case taken[fn.Name] != "":
+				pluginErrors = append(pluginErrors, fmt.Errorf("plugin %s: %s is already served by %s", entry.Name(), fn.Name, taken[fn.Name]))
+				continue
+			}
+			if len(fn.Args) > 0 {
+				fn.MinArgs, fn.MaxArgs = fn.info().MinArgs(), fn.info().MaxArgs()
+			}
+			if fn.MaxArgs < -1 || (fn.MaxArgs != -1 && fn.MaxArgs < fn.MinArgs) {
+				fn.MaxArgs = -1
+			}
+			taken[fn.Name] = entry.Name()
+			pluginFuncs = append(pluginFuncs, fn)
+			served++
+		}
+		if served == 0 {
+			p.stop()
+			continue
+		}
+		plugins[entry.Name()] = p
+	}
+
+	sort.Slice(pluginFuncs, func(i, j int) bool { return pluginFuncs[i].Name < pluginFuncs[j].Name })
+}
+
+// isExecutable reports whether path is a file the system can run
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+	return info.Mode().Perm()&0111 != 0
+}
+
+// validFunctionName reports whether name can be called in a formula, like VAT or STATS.ZSCORE
+func validFunctionName(name string) bool {
+	if name == "" || name[0] < 'A' || name[0] > 'Z' {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		ch := name[i]
+		if !((ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9') || ch == '_' || ch == '.') {
+			return false
+		}
+	}
+	return !strings.HasSuffix(name, ".")
+}
+
+// pluginCall returns the function calling fn in the plugin p serving it
+func pluginCall(fn PluginFunction, p *plugin) ExprFunction {
+	return func(args ...any) (any, error) {
+		if err := validateArgs(fn.Name, args, fn.MinArgs, fn.MaxArgs); err != nil {
+			return nil, err
+		}
+		encoded := make([]any, len(args))
+		for i, arg := range args {
+			value, err := encodePluginValue(arg)
+			if err != nil {
+				return nil, err
+			}
+			encoded[i] = value
+		}
+
+		resp, err := p.request(pluginRequest{Method: "call", Function: fn.Name, Args: encoded}, pluginCallTimeout)
+		if err != nil {
+			return nil, newError(ErrError, "%s: plugin %s: %v", fn.Name, fn.Plugin, err)
+		}
+		if resp.Error != nil {
+			code, ok := ParseError(resp.Error.Code)
+			if !ok {
+				code = ErrValue
+			}
+			reason := resp.Error.Message
+			if reason == "" {
+				reason = code.Code
+			}
+			return nil, ErrorValue{Code: code.Code, Reason: fmt.Sprintf("%s: %s", fn.Name, reason)}
+		}
+		return decodePluginValue(resp.Result)
+	}
+}
+
+// encodePluginValue turns an argument into what the plugin receives: ranges become arrays of rows
+func encodePluginValue(v any) (any, error) {
+	if ev, ok := v.(ErrorValue); ok {
+		return map[string]string{"error": ev.Code}, nil
+	}
+	if _, ok := v.(Range); !ok {
+		return v, nil
+	}
+	a, err := toArray(v)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([][]any, len(a))
+	for r, values := range a {
+		rows[r] = make([]any, len(values))
+		for c, value := range values {
+			if ev, ok := value.(ErrorValue); ok {
+				value = map[string]string{"error": ev.Code}
+			}
+			rows[r][c] = value
+		}
+	}
+	return rows, nil
+}
+
+// decodePluginValue turns a plugin's result into a cell value; an array of rows becomes an array which spills
+func decodePluginValue(v any) (any, error) {
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case float64, string, bool:
+		return val, nil
+	case map[string]any:
+		if code, ok := val["error"].(string); ok {
+			if ev, ok := ParseError(code); ok {
+				return ev, nil
+			}
+		}
+		return nil, newError(ErrValue, "the plugin returned an object GoSheet cannot show")
+	case []any:
+		if len(val) == 0 {
+			return nil, newError(ErrCalc, "the plugin returned an empty array")
+		}
+		// A flat list is a single row
+		rows := val
+		if _, nested := val[0].([]any); !nested {
+			rows = []any{val}
+		}
+		first, _ := rows[0].([]any)
+		result, err := newArray(len(rows), len(first))
+		if err != nil {
+			return nil, err
+		}
+		for r, row := range rows {
+			values, ok := row.([]any)
+			if !ok || len(values) != len(first) {
+				return nil, newError(ErrValue, "the plugin returned rows of different lengths")
+			}
+			for c, value := range values {
+				if _, nested := value.([]any); nested {
+					return nil, newError(ErrValue, "the plugin returned an array nested too deep")
+				}
+				decoded, err := decodePluginValue(value)
+				if err != nil {
+					return nil, err
+				}
+				result[r][c] = decoded
+			}
+		}
+		return result, nil
+	}
+	return nil, newError(ErrValue, "the plugin returned a value GoSheet cannot show")
+}
+
+// request sends one request to the plugin, starting it if needed, and waits for the answer up to timeout.
+// A plugin which does not answer in time is stopped, so a hung call does not hold back the next one.
+func (p *plugin) request(req pluginRequest, timeout time.Duration) (pluginResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cmd == nil {
+		if err := p.start(); err != nil {
+			return pluginResponse{}, err
+		}
+	}
+	p.nextID++
+	req.ID = p.nextID
+
+	line, err := json.Marshal(req)
+	if err != nil {
+		return pluginResponse{}, err
+	}
+	if _, err := p.stdin.Write(append(line, '\n')); err != nil {
+		p.stop()
+		return pluginResponse{}, fmt.Errorf("cannot write to the plugin: %v", err)
+	}
+
+	type answer struct {
+		resp pluginResponse
+		err  error
+	}
+	answers := make(chan answer, 1)
+	stdout := p.stdout
+	go func() {
+		var a answer
+		line, err := stdout.ReadBytes('\n')
+		if err != nil {
+			a.err = fmt.Errorf("the plugin stopped answering: %v", err)
+		} else if err := json.Unmarshal(line, &a.resp); err != nil {
+			a.err = fmt.Errorf("the plugin answered with invalid JSON: %v", err)
+		}
+		answers <- a
+	}()
+
+	select {
+	case a := <-answers:
+		if a.err != nil {
+			p.stop()
+			return pluginResponse{}, a.err
+		}
+		if a.resp.ID != req.ID {
+			p.stop()
+			return pluginResponse{}, fmt.Errorf("the plugin answered request %d instead of %d", a.resp.ID, req.ID)
+		}
+		return a.resp, nil
+	case <-time.After(timeout):
+		p.stop()
+		return pluginResponse{}, fmt.Errorf("no answer within %v", timeout)
+	}
+}
+
+// start runs the plugin executable; what it writes to stderr is dropped so it cannot garble the screen
+func (p *plugin) start() error {
+	cmd := exec.Command(p.path)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("cannot start: %v", err)
+	}
+	p.cmd, p.stdin, p.stdout = cmd, stdin, bufio.NewReader(stdout)
+	return nil
+}
+
+// stop ends the plugin process; the next request starts it again
+func (p *plugin) stop() {
+	if p.cmd == nil {
+		return
+	}
+	p.stdin.Close()
+	if p.cmd.Process != nil {
+		p.cmd.Process.Kill()
+	}
+	p.cmd.Wait()
+	p.cmd, p.stdin, p.stdout = nil, nil, nil
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package evaluatefuncs
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withStub builds the stub plugin into a directory of its own, points GOSHEET_PLUGIN_DIR at it and
+// forgets any plugins loaded before, so the next PluginFunctions starts the stub
+func withStub(t *testing.T) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("builds the stub plugin")
+	}
+
+	dir := t.TempDir()
+	name := "stub"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	build := exec.Command("go", "build", "-o", filepath.Join(dir, name), "../../../plugins/stub")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("cannot build the stub plugin: %v\n%s", err, out)
+	}
+	t.Setenv("GOSHEET_PLUGIN_DIR", dir)
+
+	resetPlugins()
+	t.Cleanup(resetPlugins)
+}
+
+func resetPlugins() {
+	ClosePlugins()
+
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	plugins = make(map[string]*plugin)
+	pluginFuncs, pluginErrors, pluginsLoaded = nil, nil, false
+}
+
+// withTimeouts shortens the plugin timeouts for the length of the test
+func withTimeouts(t *testing.T, start, call time.Duration) {
+	t.Helper()
+	oldStart, oldCall := pluginStartTimeout, pluginCallTimeout
+	pluginStartTimeout, pluginCallTimeout = start, call
+	t.Cleanup(func() { pluginStartTimeout, pluginCallTimeout = oldStart, oldCall })
+}
+
+func TestPluginManifest(t *testing.T) {
+	withStub(t)
+
+	if errs := PluginErrors(); len(errs) > 0 {
+		t.Fatalf("PluginErrors() = %v before loading", errs)
+	}
+
+	var names []string
+	for _, fn := range PluginManifest() {
+		names = append(names, fn.Name)
+		if fn.Plugin != "stub" && fn.Plugin != "stub.exe" {
+			t.Errorf("%s is served by %q, want the stub", fn.Name, fn.Plugin)
+		}
+		if fn.Name == "STUB.VAT" && (fn.MinArgs != 1 || fn.MaxArgs != 2) {
+			t.Errorf("STUB.VAT takes %d to %d arguments, want 1 to 2", fn.MinArgs, fn.MaxArgs)
+		}
+	}
+	if got, want := strings.Join(names, " "), "STUB.FAIL STUB.SLEEP STUB.SPLIT STUB.TOTAL STUB.VAT"; got != want {
+		t.Errorf("PluginManifest() = %s, want %s", got, want)
+	}
+	if errs := PluginErrors(); len(errs) > 0 {
+		t.Errorf("PluginErrors() = %v", errs)
+	}
+}
+
+func TestPluginCall(t *testing.T) {
+	withStub(t)
+	functions := PluginFunctions()
+
+	got, err := functions["STUB.VAT"](100.0, 0.5)
+	if err != nil || got != 150.0 {
+		t.Errorf("STUB.VAT(100, 0.5) = %v, %v, want 150", got, err)
+	}
+
+	got, err = functions["STUB.TOTAL"](1.0, "text", 2.5)
+	if err != nil || got != 3.5 {
+		t.Errorf("STUB.TOTAL(1, \"text\", 2.5) = %v, %v, want 3.5", got, err)
+	}
+
+	got, err = functions["STUB.SPLIT"]("a, b,c")
+	row, ok := got.(Array)
+	if err != nil || !ok || len(row) != 1 || len(row[0]) != 3 || row[0][0] != "a" || row[0][2] != "c" {
+		t.Errorf("STUB.SPLIT(\"a, b,c\") = %v, %v, want one row of a, b and c", got, err)
+	}
+
+	if _, err := functions["STUB.VAT"](); err == nil {
+		t.Error("STUB.VAT() succeeded, want an error for too few arguments")
+	}
+}
+
+func TestPluginErrorCodes(t *testing.T) {
+	withStub(t)
+	functions := PluginFunctions()
+
+	cases := []struct {
+		args []any
+		code string
+	}{
+		{args: nil, code: "#VALUE!"},
+		{args: []any{"#NUM!"}, code: "#NUM!"},
+		{args: []any{"#DIV/0!"}, code: "#DIV/0!"},
+		{args: []any{"#N/A"}, code: "#N/A"},
+
+		// A code GoSheet does not know becomes #VALUE!
+		{args: []any{"#BOGUS!"}, code: "#VALUE!"},
+	}
+
+	for _, tc := range cases {
+		_, err := functions["STUB.FAIL"](tc.args...)
+		ev, ok := err.(ErrorValue)
+		if !ok || ev.Code != tc.code {
+			t.Errorf("STUB.FAIL(%v) failed with %#v, want an ErrorValue of %s", tc.args, err, tc.code)
+			continue
+		}
+		if !strings.HasPrefix(ev.Reason, "STUB.FAIL: ") {
+			t.Errorf("STUB.FAIL(%v) gives the reason %q, want it to name the function", tc.args, ev.Reason)
+		}
+	}
+
+	// The rate check of STUB.VAT answers with #NUM!
+	if _, err := functions["STUB.VAT"](100.0, -1.0); err == nil || err.(ErrorValue).Code != "#NUM!" {
+		t.Errorf("STUB.VAT(100, -1) failed with %v, want #NUM!", err)
+	}
+}
+
+func TestPluginCallTimeout(t *testing.T) {
+	withStub(t)
+	withTimeouts(t, 5*time.Second, 200*time.Millisecond)
+	functions := PluginFunctions()
+
+	p := plugins[PluginManifest()[0].Plugin]
+	before := p.cmd.Process.Pid
+
+	began := time.Now()
+	_, err := functions["STUB.SLEEP"](2.0)
+	ev, ok := err.(ErrorValue)
+	if !ok || ev.Code != "#ERROR!" || !strings.Contains(ev.Reason, "no answer within") {
+		t.Fatalf("STUB.SLEEP(2) failed with %#v, want #ERROR! for no answer", err)
+	}
+	if elapsed := time.Since(began); elapsed > time.Second {
+		t.Errorf("STUB.SLEEP(2) took %v to time out, want about %v", elapsed, pluginCallTimeout)
+	}
+	if p.cmd != nil {
+		t.Error("the plugin kept running after a call timed out")
+	}
+
+	// The next call starts the plugin again
+	got, err := functions["STUB.VAT"](100.0, 0.0)
+	if err != nil || got != 100.0 {
+		t.Fatalf("STUB.VAT(100, 0) after a timeout = %v, %v, want 100", got, err)
+	}
+	if p.cmd == nil || p.cmd.Process.Pid == before {
+		t.Error("the plugin was not started again after a call timed out")
+	}
+}
+
+func TestPluginStartTimeout(t *testing.T) {
+	withStub(t)
+	withTimeouts(t, 200*time.Millisecond, 3*time.Second)
+	t.Setenv("STUB_START_DELAY", "2s")
+
+	began := time.Now()
+	if manifest := PluginManifest(); len(manifest) > 0 {
+		t.Errorf("PluginManifest() = %v for a plugin too slow to start, want nothing", manifest)
+	}
+	if elapsed := time.Since(began); elapsed > time.Second {
+		t.Errorf("loading took %v, want about %v", elapsed, pluginStartTimeout)
+	}
+
+	errs := PluginErrors()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "no answer within") {
+		t.Errorf("PluginErrors() = %v, want the start timeout", errs)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// range.go defines how a block of cells is handed to a function
+
+package evaluatefuncs
+
+// Range is a block of cells passed to a function as a single argument, like A1:B10 or A:A.
+// It is read lazily, so a whole column costs only as much as the cells that hold a value.
+type Range interface {
+	// Dims returns the height and width of the block
+	Dims() (rows, cols int)
+	// At returns the value at the zero-based offset inside the block, nil for an empty cell
+	At(row, col int) any
+	// Each calls fn for every non-empty cell, row by row, until fn returns false
+	Each(fn func(row, col int, value any) bool)
+}
+
+// flattenArgs replaces every range argument by the values of its non-empty cells.
+// The first error value met is returned as well, so aggregates can pass it on.
+func flattenArgs(args []any) ([]any, error) {
+	flat := make([]any, 0, len(args))
+	var firstErr error
+	keep := func(value any) {
+		if ev, ok := value.(ErrorValue); ok && firstErr == nil {
+			firstErr = ev
+		}
+		flat = append(flat, value)
+	}
+
+	for _, arg := range args {
+		r, ok := arg.(Range)
+		if !ok {
+			keep(arg)
+			continue
+		}
+		r.Each(func(_, _ int, value any) bool {
+			keep(value)
+			return true
+		})
+	}
+	return flat, firstErr
+}
@@ -14,13 +14,36 @@ import (
 type ExprFunction func(args ...any) (any, error)
 
 func GovalFuncs() map[string]ExprFunction {
+	functions := builtinFunctions()
+	for name, fn := range PluginFunctions() {
+		if _, exists := functions[name]; !exists {
+			functions[name] = fn
+		}
+	}
+
+	for name, fn := range functions {
+		functions[name] = propagateErrors(name, fn)
+	}
+
+	return functions
+}
+
+// builtinFunctions returns the functions compiled into GoSheet, without the ones served by plugins
+func builtinFunctions() map[string]ExprFunction {
 	functions := make(map[string]ExprFunction)
 
 	mergeFunctions(functions, MathFunctions())
+	mergeFunctions(functions, MatrixFunctions())
 	mergeFunctions(functions, StatisticalFunctions())
+	mergeFunctions(functions, ConditionalFunctions())
+	mergeFunctions(functions, FinancialFunctions())
 	mergeFunctions(functions, StringFunctions())
 	mergeFunctions(functions, DateTimeFunctions())
 	mergeFunctions(functions, LogicalFunctions())
+	mergeFunctions(functions, ErrorFunctions())
+	mergeFunctions(functions, LookupFunctions())
+	mergeFunctions(functions, InformationFunctions())
+	mergeFunctions(functions, ArrayFunctions())
 
 	return functions
 }
@@ -7,14 +7,24 @@
 
 package evaluatefuncs
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+)
 
 func StatisticalFunctions() map[string]ExprFunction {
 	return map[string]ExprFunction{
 		"AVG": func(args ...any) (any, error) {
-			if err := validateArgs("AVG", args, 2, -1); err != nil {
+			if err := validateArgs("AVG", args, 1, -1); err != nil {
 				return nil, err
 			}
+			args, err := flattenArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			if len(args) == 0 {
+				return nil, newError(ErrDiv0, "AVG: division by zero")
+			}
 			sum := 0.0
 			for _, arg := range args {
 				f, _ := toFloat(arg)
@@ -27,6 +37,7 @@ func StatisticalFunctions() map[string]ExprFunction {
 			if err := validateArgs("COUNT", args, 1, -1); err != nil {
 				return nil, err
 			}
+			args, _ = flattenArgs(args)
 			count := 0
 			for _, arg := range args {
 				if _, ok := arg.(float64); ok {
@@ -37,7 +48,11 @@ func StatisticalFunctions() map[string]ExprFunction {
 		},
 
 		"SUM": func(args ...any) (any, error) {
-			if err := validateArgs("SUM", args, 2, -1); err != nil {
+			if err := validateArgs("SUM", args, 1, -1); err != nil {
+				return nil, err
+			}
+			args, err := flattenArgs(args)
+			if err != nil {
 				return nil, err
 			}
 			sum := 0.0
@@ -49,9 +64,16 @@ func StatisticalFunctions() map[string]ExprFunction {
 		},
 
 		"PRODUCT": func(args ...any) (any, error) {
-			if err := validateArgs("PRODUCT", args, 2, -1); err != nil {
+			if err := validateArgs("PRODUCT", args, 1, -1); err != nil {
 				return nil, err
 			}
+			args, err := flattenArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			if len(args) == 0 {
+				return 0.0, nil
+			}
 			product := 1.0
 			for _, arg := range args {
 				f, _ := toFloat(arg)
@@ -64,13 +86,16 @@ func StatisticalFunctions() map[string]ExprFunction {
 			if err := validateArgs("CHOOSE", args, 2, -1); err != nil {
 				return nil, err
 			}
+			if ev, ok := args[0].(ErrorValue); ok {
+				return ev, nil
+			}
 			index, err := toFloat(args[0])
 			if err != nil {
 				return nil, fmt.Errorf("CHOOSE: %v", err)
 			}
 			idx := int(index)
 			if idx < 1 || idx >= len(args) {
-				return nil, fmt.Errorf("index out of range")
+				return nil, newError(ErrValue, "CHOOSE: index out of range")
 			}
 			return args[idx], nil
 		},
@@ -98,5 +123,356 @@ func StatisticalFunctions() map[string]ExprFunction {
 			s := toString(args[0])
 			return s == "", nil
 		},
+
+		"MEDIAN": func(args ...any) (any, error) {
+			if err := validateArgs("MEDIAN", args, 1, -1); err != nil {
+				return nil, err
+			}
+			numbers, err := numbersOf(args)
+			if err != nil {
+				return nil, err
+			}
+			return percentile("MEDIAN", numbers, 0.5)
+		},
+
+		"MODE": func(args ...any) (any, error) {
+			if err := validateArgs("MODE", args, 1, -1); err != nil {
+				return nil, err
+			}
+			numbers, err := numbersOf(args)
+			if err != nil {
+				return nil, err
+			}
+			counts := make(map[float64]int)
+			bestCount := 1
+			for _, n := range numbers {
+				counts[n]++
+				bestCount = max(bestCount, counts[n])
+			}
+			// On a tie the value seen first wins, like Excel
+			for _, n := range numbers {
+				if bestCount > 1 && counts[n] == bestCount {
+					return n, nil
+				}
+			}
+			return nil, newError(ErrNA, "MODE: no value repeats")
+		},
+
+		"VAR.S": func(args ...any) (any, error) {
+			if err := validateArgs("VAR.S", args, 1, -1); err != nil {
+				return nil, err
+			}
+			numbers, err := numbersOf(args)
+			if err != nil {
+				return nil, err
+			}
+			return variance("VAR.S", numbers, 1)
+		},
+
+		"VAR.P": func(args ...any) (any, error) {
+			if err := validateArgs("VAR.P", args, 1, -1); err != nil {
+				return nil, err
+			}
+			numbers, err := numbersOf(args)
+			if err != nil {
+				return nil, err
+			}
+			return variance("VAR.P", numbers, 0)
+		},
+
+		"STDEV.S": func(args ...any) (any, error) {
+			if err := validateArgs("STDEV.S", args, 1, -1); err != nil {
+				return nil, err
+			}
+			numbers, err := numbersOf(args)
+			if err != nil {
+				return nil, err
+			}
+			v, err := variance("STDEV.S", numbers, 1)
+			if err != nil {
+				return nil, err
+			}
+			return math.Sqrt(v), nil
+		},
+
+		"STDEV.P": func(args ...any) (any, error) {
+			if err := validateArgs("STDEV.P", args, 1, -1); err != nil {
+				return nil, err
+			}
+			numbers, err := numbersOf(args)
+			if err != nil {
+				return nil, err
+			}
+			v, err := variance("STDEV.P", numbers, 0)
+			if err != nil {
+				return nil, err
+			}
+			return math.Sqrt(v), nil
+		},
+
+		"PERCENTILE": func(args ...any) (any, error) {
+			if err := validateArgs("PERCENTILE", args, 2, 2); err != nil {
+				return nil, err
+			}
+			numbers, err := numbersOf(args[:1])
+			if err != nil {
+				return nil, err
+			}
+			k, err := toFloat(args[1])
+			if err != nil {
+				return nil, fmt.Errorf("PERCENTILE: %v", err)
+			}
+			return percentile("PERCENTILE", numbers, k)
+		},
+
+		"QUARTILE": func(args ...any) (any, error) {
+			if err := validateArgs("QUARTILE", args, 2, 2); err != nil {
+				return nil, err
+			}
+			numbers, err := numbersOf(args[:1])
+			if err != nil {
+				return nil, err
+			}
+			quart, err := toIndex(args[1])
+			if err != nil {
+				return nil, err
+			}
+			if quart < 0 || quart > 4 {
+				return nil, newError(ErrNum, "QUARTILE: quart must be between 0 and 4")
+			}
+			return percentile("QUARTILE", numbers, float64(quart)/4)
+		},
+
+		"RANK": func(args ...any) (any, error) {
+			if err := validateArgs("RANK", args, 2, 3); err != nil {
+				return nil, err
+			}
+			number, err := toFloat(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("RANK: %v", err)
+			}
+			numbers, err := numbersOf(args[1:2])
+			if err != nil {
+				return nil, err
+			}
+			ascending := false
+			if len(args) == 3 {
+				if ascending, err = toBool(args[2]); err != nil {
+					return nil, err
+				}
+			}
+			rank, found := 1, false
+			for _, n := range numbers {
+				switch {
+				case n == number:
+					found = true
+				case ascending && n < number, !ascending && n > number:
+					rank++
+				}
+			}
+			if !found {
+				return nil, newError(ErrNA, "RANK: %v is not in the list", number)
+			}
+			return float64(rank), nil
+		},
+
+		"LARGE": func(args ...any) (any, error) {
+			return kth("LARGE", args, true)
+		},
+
+		"SMALL": func(args ...any) (any, error) {
+			return kth("SMALL", args, false)
+		},
+
+		"CORREL": func(args ...any) (any, error) {
+			if err := validateArgs("CORREL", args, 2, 2); err != nil {
+				return nil, err
+			}
+			ys, xs, err := pairedNumbers("CORREL", args[0], args[1])
+			if err != nil {
+				return nil, err
+			}
+			vy, err := variance("CORREL", ys, 0)
+			if err != nil {
+				return nil, err
+			}
+			vx, _ := variance("CORREL", xs, 0)
+			if vy == 0 || vx == 0 {
+				return nil, newError(ErrDiv0, "CORREL: a range does not vary")
+			}
+			return covariance(ys, xs) / math.Sqrt(vy*vx), nil
+		},
+
+		"COVAR": func(args ...any) (any, error) {
+			if err := validateArgs("COVAR", args, 2, 2); err != nil {
+				return nil, err
+			}
+			ys, xs, err := pairedNumbers("COVAR", args[0], args[1])
+			if err != nil {
+				return nil, err
+			}
+			if len(ys) == 0 {
+				return nil, newError(ErrDiv0, "COVAR: no pairs of numbers")
+			}
+			return covariance(ys, xs), nil
+		},
+
+		"SLOPE": func(args ...any) (any, error) {
+			if err := validateArgs("SLOPE", args, 2, 2); err != nil {
+				return nil, err
+			}
+			ys, xs, err := pairedNumbers("SLOPE", args[0], args[1])
+			if err != nil {
+				return nil, err
+			}
+			slope, _, err := linearFit("SLOPE", ys, xs)
+			if err != nil {
+				return nil, err
+			}
+			return slope, nil
+		},
+
+		"INTERCEPT": func(args ...any) (any, error) {
+			if err := validateArgs("INTERCEPT", args, 2, 2); err != nil {
+				return nil, err
+			}
+			ys, xs, err := pairedNumbers("INTERCEPT", args[0], args[1])
+			if err != nil {
+				return nil, err
+			}
+			_, intercept, err := linearFit("INTERCEPT", ys, xs)
+			if err != nil {
+				return nil, err
+			}
+			return intercept, nil
+		},
+
+		"FORECAST": func(args ...any) (any, error) {
+			if err := validateArgs("FORECAST", args, 3, 3); err != nil {
+				return nil, err
+			}
+			x, err := toFloat(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("FORECAST: %v", err)
+			}
+			ys, xs, err := pairedNumbers("FORECAST", args[1], args[2])
+			if err != nil {
+				return nil, err
+			}
+			slope, intercept, err := linearFit("FORECAST", ys, xs)
+			if err != nil {
+				return nil, err
+			}
+			return intercept + slope*x, nil
+		},
+
+		"TREND": func(args ...any) (any, error) {
+			if err := validateArgs("TREND", args, 3, 4); err != nil {
+				return nil, err
+			}
+			ys, xs, err := pairedNumbers("TREND", args[0], args[1])
+			if err != nil {
+				return nil, err
+			}
+			x, err := toFloat(args[2])
+			if err != nil {
+				return nil, fmt.Errorf("TREND: %v", err)
+			}
+			withConst := true
+			if len(args) == 4 {
+				if withConst, err = toBool(args[3]); err != nil {
+					return nil, err
+				}
+			}
+			if withConst {
+				slope, intercept, err := linearFit("TREND", ys, xs)
+				if err != nil {
+					return nil, err
+				}
+				return intercept + slope*x, nil
+			}
+			// Forced through the origin
+			sxy, sxx := 0.0, 0.0
+			for i := range ys {
+				sxy += xs[i] * ys[i]
+				sxx += xs[i] * xs[i]
+			}
+			if sxx == 0 {
+				return nil, newError(ErrDiv0, "TREND: the x values are all zero")
+			}
+			return sxy / sxx * x, nil
+		},
+
+		"NORM.DIST": func(args ...any) (any, error) {
+			if err := validateArgs("NORM.DIST", args, 4, 4); err != nil {
+				return nil, err
+			}
+			params := make([]float64, 3)
+			for i := range params {
+				f, err := toFloat(args[i])
+				if err != nil {
+					return nil, fmt.Errorf("NORM.DIST: %v", err)
+				}
+				params[i] = f
+			}
+			x, mu, sigma := params[0], params[1], params[2]
+			cumulative, err := toBool(args[3])
+			if err != nil {
+				return nil, err
+			}
+			if sigma <= 0 {
+				return nil, newError(ErrNum, "NORM.DIST: standard deviation must be positive")
+			}
+			z := (x - mu) / sigma
+			if cumulative {
+				return 0.5 * math.Erfc(-z/math.Sqrt2), nil
+			}
+			return math.Exp(-z*z/2) / (sigma * math.Sqrt(2*math.Pi)), nil
+		},
+
+		"NORM.INV": func(args ...any) (any, error) {
+			if err := validateArgs("NORM.INV", args, 3, 3); err != nil {
+				return nil, err
+			}
+			params := make([]float64, 3)
+			for i := range params {
+				f, err := toFloat(args[i])
+				if err != nil {
+					return nil, fmt.Errorf("NORM.INV: %v", err)
+				}
+				params[i] = f
+			}
+			p, mu, sigma := params[0], params[1], params[2]
+			if p <= 0 || p >= 1 {
+				return nil, newError(ErrNum, "NORM.INV: probability must be between 0 and 1")
+			}
+			if sigma <= 0 {
+				return nil, newError(ErrNum, "NORM.INV: standard deviation must be positive")
+			}
+			return mu + sigma*math.Sqrt2*math.Erfinv(2*p-1), nil
+		},
+
+		"T.DIST": func(args ...any) (any, error) {
+			if err := validateArgs("T.DIST", args, 3, 3); err != nil {
+				return nil, err
+			}
+			x, err := toFloat(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("T.DIST: %v", err)
+			}
+			df, err := toFloat(args[1])
+			if err != nil {
+				return nil, fmt.Errorf("T.DIST: %v", err)
+			}
+			cumulative, err := toBool(args[2])
+			if err != nil {
+				return nil, err
+			}
+			df = math.Trunc(df)
+			if df < 1 {
+				return nil, newError(ErrNum, "T.DIST: degrees of freedom must be at least 1")
+			}
+			return studentT(x, df, cumulative), nil
+		},
 	}
 }
@@ -0,0 +1,242 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// statistical_helpers.go provides the number collection and distribution math behind the statistical functions
+
+package evaluatefuncs
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// numbersOf collects the numbers of the arguments like Excel does: inside a range only number cells count,
+// so blanks, text and logical values are skipped. A plain argument counts when it reads as a number.
+func numbersOf(args []any) ([]float64, error) {
+	var numbers []float64
+	var firstErr error
+	for _, arg := range args {
+		if r, ok := arg.(Range); ok {
+			r.Each(func(_, _ int, value any) bool {
+				switch v := value.(type) {
+				case float64:
+					numbers = append(numbers, v)
+				case ErrorValue:
+					firstErr = v
+					return false
+				}
+				return true
+			})
+			if firstErr != nil {
+				return nil, firstErr
+			}
+			continue
+		}
+		if n, ok := numberOf(arg); ok {
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers, nil
+}
+
+// numberOf reads a plain argument as a number, accepting numeric text
+func numberOf(v any) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case int:
+		return float64(val), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// pairedNumbers collects the positions where both ranges hold a number, as CORREL, SLOPE and friends need
+func pairedNumbers(name string, ys, xs any) ([]float64, []float64, error) {
+	yr, xr := asRange(ys), asRange(xs)
+	yRows, yCols := yr.Dims()
+	xRows, xCols := xr.Dims()
+	if yRows*yCols != xRows*xCols {
+		return nil, nil, newError(ErrNA, "%s: the ranges hold a different number of cells", name)
+	}
+
+	var yv, xv []float64
+	var firstErr error
+	yr.Each(func(row, col int, value any) bool {
+		if ev, ok := value.(ErrorValue); ok {
+			firstErr = ev
+			return false
+		}
+		y, ok := value.(float64)
+		if !ok {
+			return true
+		}
+		// Walk both ranges in the same order even when their shapes differ, like a row against a column
+		pos := row*yCols + col
+		other := xr.At(pos/xCols, pos%xCols)
+		if ev, ok := other.(ErrorValue); ok {
+			firstErr = ev
+			return false
+		}
+		if x, ok := other.(float64); ok {
+			yv = append(yv, y)
+			xv = append(xv, x)
+		}
+		return true
+	})
+	return yv, xv, firstErr
+}
+
+func mean(numbers []float64) float64 {
+	sum := 0.0
+	for _, n := range numbers {
+		sum += n
+	}
+	return sum / float64(len(numbers))
+}
+
+// variance returns the sum of squared deviations divided by the count less ddof
+func variance(name string, numbers []float64, ddof int) (float64, error) {
+	if len(numbers)-ddof <= 0 {
+		return 0, newError(ErrDiv0, "%s: not enough numbers", name)
+	}
+	m := mean(numbers)
+	sum := 0.0
+	for _, n := range numbers {
+		sum += (n - m) * (n - m)
+	}
+	return sum / float64(len(numbers)-ddof), nil
+}
+
+// covariance returns the population covariance of two equally long samples
+func covariance(ys, xs []float64) float64 {
+	my, mx := mean(ys), mean(xs)
+	sum := 0.0
+	for i := range ys {
+		sum += (ys[i] - my) * (xs[i] - mx)
+	}
+	return sum / float64(len(ys))
+}
+
+// linearFit returns the least squares line through the points
+func linearFit(name string, ys, xs []float64) (slope, intercept float64, err error) {
+	if len(ys) == 0 {
+		return 0, 0, newError(ErrDiv0, "%s: no pairs of numbers", name)
+	}
+	vx, _ := variance(name, xs, 0)
+	if vx == 0 {
+		return 0, 0, newError(ErrDiv0, "%s: the x values do not vary", name)
+	}
+	slope = covariance(ys, xs) / vx
+	return slope, mean(ys) - slope*mean(xs), nil
+}
+
+// percentile interpolates linearly between the closest ranks, k being in [0, 1]
+func percentile(name string, numbers []float64, k float64) (float64, error) {
+	if len(numbers) == 0 || k < 0 || k > 1 {
+		return 0, newError(ErrNum, "%s: invalid percentile", name)
+	}
+	sorted := append([]float64(nil), numbers...)
+	sort.Float64s(sorted)
+	pos := k * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	if lower == len(sorted)-1 {
+		return sorted[lower], nil
+	}
+	return sorted[lower] + (pos-float64(lower))*(sorted[lower+1]-sorted[lower]), nil
+}
+
+// kth returns the k-th largest or smallest number, k counting from 1
+func kth(name string, args []any, largest bool) (any, error) {
+	if err := validateArgs(name, args, 2, 2); err != nil {
+		return nil, err
+	}
+	numbers, err := numbersOf(args[:1])
+	if err != nil {
+		return nil, err
+	}
+	k, err := toIndex(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if k < 1 || k > len(numbers) {
+		return nil, newError(ErrNum, "%s: k is outside of 1..%d", name, len(numbers))
+	}
+	sort.Float64s(numbers)
+	if largest {
+		return numbers[len(numbers)-k], nil
+	}
+	return numbers[k-1], nil
+}
+
+// studentT returns the density or the cumulative distribution of Student's t at x with df degrees of freedom
+func studentT(x, df float64, cumulative bool) float64 {
+	if !cumulative {
+		lg1, _ := math.Lgamma((df + 1) / 2)
+		lg2, _ := math.Lgamma(df / 2)
+		return math.Exp(lg1-lg2) / math.Sqrt(df*math.Pi) * math.Pow(1+x*x/df, -(df+1)/2)
+	}
+	tail := 0.5 * incompleteBeta(df/(df+x*x), df/2, 0.5)
+	if x > 0 {
+		return 1 - tail
+	}
+	return tail
+}
+
+// incompleteBeta returns the regularized incomplete beta function I_x(a, b)
+func incompleteBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	// The continued fraction converges fast only below the mean, use the symmetry above it
+	if x > (a+1)/(a+b+2) {
+		return 1 - front*betaFraction(1-x, b, a)/b
+	}
+	return front * betaFraction(x, a, b) / a
+}
+
+// betaFraction evaluates the continued fraction of the incomplete beta function with Lentz's method
+func betaFraction(x, a, b float64) float64 {
+	const tiny = 1e-300
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	result := d
+	for m := 1; m <= 300; m++ {
+		fm := float64(m)
+		for _, num := range []float64{
+			fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm)),
+			-(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1)),
+		} {
+			d = 1 + num*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + num/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			result *= d * c
+		}
+		if math.Abs(d*c-1) < 1e-15 {
+			break
+		}
+	}
+	return result
+}
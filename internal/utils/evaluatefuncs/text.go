@@ -171,6 +171,10 @@ func StringFunctions() map[string]ExprFunction {
 			if err := validateArgs("CONCAT", args, 1, -1); err != nil {
 				return nil, err
 			}
+			args, err := flattenArgs(args)
+			if err != nil {
+				return nil, err
+			}
 			result := ""
 			for _, arg := range args {
 				result += toString(arg)
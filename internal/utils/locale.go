@@ -0,0 +1,208 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// locale.go provides the locales formulas and numbers are typed in. Formulas are stored in the canonical
+// form, with . decimals and , between arguments, and shown in the locale only while they are edited.
+
+package utils
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Locale is how numbers and formulas are written in a country
+type Locale struct {
+	Name               string
+	DecimalSeparator   rune
+	ArgumentSeparator  rune
+	ThousandsSeparator rune
+}
+
+// Locales are the locales which may be chosen, the canonical one first
+var Locales = []Locale{
+	{Name: "en-US", DecimalSeparator: '.', ArgumentSeparator: ',', ThousandsSeparator: ','},
+	{Name: "de-DE", DecimalSeparator: ',', ArgumentSeparator: ';', ThousandsSeparator: '.'},
+	{Name: "fr-FR", DecimalSeparator: ',', ArgumentSeparator: ';', ThousandsSeparator: ' '},
+}
+
+// CurrentLocale is the locale formulas and numbers are typed in
+var CurrentLocale = Locales[0]
+
+// FindLocale returns the locale called name, written like de-DE, de_DE or de_DE.UTF-8
+func FindLocale(name string) (Locale, bool) {
+	name, _, _ = strings.Cut(strings.TrimSpace(name), ".")
+	name = strings.ReplaceAll(name, "_", "-")
+	for _, l := range Locales {
+		if strings.EqualFold(l.Name, name) {
+			return l, true
+		}
+	}
+	return Locale{}, false
+}
+
+// SetLocale makes the locale called name the current one, reporting whether there is such a locale
+func SetLocale(name string) bool {
+	l, ok := FindLocale(name)
+	if ok {
+		CurrentLocale = l
+	}
+	return ok
+}
+
+// SystemLocale returns the locale numbers are written in by the environment, like de_DE.UTF-8 from LANG
+func SystemLocale() string {
+	for _, variable := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if value := os.Getenv(variable); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// canonical reports whether the locale writes formulas the way they are stored
+func (l Locale) canonical() bool {
+	return l.DecimalSeparator == '.' && l.ArgumentSeparator == ','
+}
+
+// LocalizeFormula writes a stored formula the way it is typed in the locale
+func (l Locale) LocalizeFormula(formula string) string {
+	if l.canonical() {
+		return formula
+	}
+	return translateFormula(formula, '.', ',', l.DecimalSeparator, l.ArgumentSeparator)
+}
+
+// CanonicalFormula writes a formula typed in the locale the way it is stored
+func (l Locale) CanonicalFormula(formula string) string {
+	if l.canonical() {
+		return formula
+	}
+	return translateFormula(formula, l.DecimalSeparator, l.ArgumentSeparator, '.', ',')
+}
+
+// LocalizeNumber writes a number stored like 1234.5 with the decimal separator of the locale
+func (l Locale) LocalizeNumber(s string) string {
+	if l.DecimalSeparator == '.' {
+		return s
+	}
+	if _, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err != nil {
+		return s
+	}
+	return strings.Replace(s, ".", string(l.DecimalSeparator), 1)
+}
+
+// CanonicalNumber writes a number typed in the locale, like 1.234,5 in de-DE, the way cells store it, 1234.5.
+// Anything else is returned as it is.
+func (l Locale) CanonicalNumber(s string) string {
+	if l.DecimalSeparator == '.' {
+		return s
+	}
+	val, err := l.ParseNumber(s)
+	if err != nil {
+		return s
+	}
+	return strconv.FormatFloat(val, 'f', -1, 64)
+}
+
+// ParseNumber reads a number typed in the locale, like 1.234,5 in de-DE. A thousands separator only
+// counts as one before a group of three digits.
+func (l Locale) ParseNumber(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if l.DecimalSeparator != '.' {
+		s = stripThousands(s, l.ThousandsSeparator)
+		s = strings.Replace(s, string(l.DecimalSeparator), ".", 1)
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// stripThousands drops the separators of s which stand between a digit and a group of three digits
+func stripThousands(s string, separator rune) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if r == separator && i > 0 && isDigitRune(runes[i-1]) && digitGroup(runes[i+1:]) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// digitGroup reports whether rest starts with exactly three digits
+func digitGroup(rest []rune) bool {
+	if len(rest) < 3 || (len(rest) > 3 && isDigitRune(rest[3])) {
+		return false
+	}
+	return isDigitRune(rest[0]) && isDigitRune(rest[1]) && isDigitRune(rest[2])
+}
+
+// translateFormula rewrites the decimal separators of the numbers and the separators between arguments
+// of formula, leaving strings, quoted sheet names and the words of references and functions as they are
+func translateFormula(formula string, fromDecimal, fromSeparator, toDecimal, toSeparator rune) string {
+	runes := []rune(formula)
+	var b strings.Builder
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '"' || r == '\'':
+			// Strings and quoted sheet names are copied whole; a doubled quote is copied as the next one.
+			// A string may hold \" and \\, which the formula engine reads as escaped characters.
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				if r == '"' && runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(runes))
+			b.WriteString(string(runes[i:end]))
+			i = end
+		case isWordStart(r):
+			// Cell references, names and dotted function names may hold digits and dots of their own
+			start := i
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			b.WriteString(string(runes[start:i]))
+		case isDigitRune(r) || (r == fromDecimal && i+1 < len(runes) && isDigitRune(runes[i+1]) && (i == 0 || !isWordRune(runes[i-1]))):
+			for i < len(runes) && isDigitRune(runes[i]) {
+				b.WriteRune(runes[i])
+				i++
+			}
+			if i+1 < len(runes) && runes[i] == fromDecimal && isDigitRune(runes[i+1]) {
+				b.WriteRune(toDecimal)
+				i++
+				for i < len(runes) && isDigitRune(runes[i]) {
+					b.WriteRune(runes[i])
+					i++
+				}
+			}
+		case r == fromSeparator:
+			b.WriteRune(toSeparator)
+			i++
+		default:
+			b.WriteRune(r)
+			i++
+		}
+	}
+	return b.String()
+}
+
+func isDigitRune(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// isWordStart reports whether r starts the word of a reference, a name or a function
+func isWordStart(r rune) bool {
+	return (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || r == '_' || r == '$' || r > 127
+}
+
+// isWordRune reports whether r belongs to the word of a reference, a name or a function
+func isWordRune(r rune) bool {
+	return isWordStart(r) || isDigitRune(r) || r == '.' || r == '!'
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+package utils
+
+import "testing"
+
+func TestLocalizeFormula(t *testing.T) {
+	german, _ := FindLocale("de-DE")
+
+	cases := []struct {
+		formula   string
+		localized string
+	}{
+		{formula: "$=SUM(A1, 2.5)", localized: "$=SUM(A1; 2,5)"},
+		{formula: "$=ROUNDTO(A1*1.19, 2)", localized: "$=ROUNDTO(A1*1,19; 2)"},
+		{formula: "$=IF(A1>.5, 1E3, 0)", localized: "$=IF(A1>,5; 1E3; 0)"},
+		{formula: "$=STDEV.S(A1:A9)", localized: "$=STDEV.S(A1:A9)"},
+		{formula: "$=Sheet2!B2+0.1", localized: "$=Sheet2!B2+0,1"},
+
+		// Strings and quoted sheet names keep their separators and decimals
+		{formula: `$=CONCAT("a,b", "1.5")`, localized: `$=CONCAT("a,b"; "1.5")`},
+		{formula: `$=CONCAT("a\"b,c", 1.5)`, localized: `$=CONCAT("a\"b,c"; 1,5)`},
+		{formula: `$=CONCAT("a\\", 1.5)`, localized: `$=CONCAT("a\\"; 1,5)`},
+		{formula: `$=LEN("say \"1.5, 2\"")`, localized: `$=LEN("say \"1.5, 2\"")`},
+		{formula: "$=SUM('Q1, 2.5'!A1, 0.5)", localized: "$=SUM('Q1, 2.5'!A1; 0,5)"},
+		{formula: "$=SUM('Bob''s, 1.5'!A1:A2, 1.5)", localized: "$=SUM('Bob''s, 1.5'!A1:A2; 1,5)"},
+	}
+
+	for _, tc := range cases {
+		if got := german.LocalizeFormula(tc.formula); got != tc.localized {
+			t.Errorf("LocalizeFormula(%q) = %q, want %q", tc.formula, got, tc.localized)
+		}
+		if got := german.CanonicalFormula(tc.localized); got != tc.formula {
+			t.Errorf("CanonicalFormula(%q) = %q, want %q", tc.localized, got, tc.formula)
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	cases := []struct {
+		locale string
+		text   string
+		want   float64
+		ok     bool
+	}{
+		{locale: "en-US", text: "1234.5", want: 1234.5, ok: true},
+		{locale: "en-US", text: "1,5", ok: false},
+		{locale: "de-DE", text: "1.234,5", want: 1234.5, ok: true},
+		{locale: "de-DE", text: "1.234", want: 1234, ok: true},
+		{locale: "de-DE", text: "2,5", want: 2.5, ok: true},
+		{locale: "fr-FR", text: "1 234,5", want: 1234.5, ok: true},
+	}
+
+	for _, tc := range cases {
+		l, _ := FindLocale(tc.locale)
+		got, err := l.ParseNumber(tc.text)
+		if (err == nil) != tc.ok || got != tc.want {
+			t.Errorf("%s ParseNumber(%q) = %v, %v", tc.locale, tc.text, got, err)
+		}
+	}
+}
+
+func TestIsNumber(t *testing.T) {
+	defer func(l Locale) { CurrentLocale = l }(CurrentLocale)
+	SetLocale("de-DE")
+
+	cases := map[string]bool{"1234,5": true, "$12": true, "1.234,5": true, "2,5": true, "2,5,1": false, "": false}
+	for text, want := range cases {
+		if got := IsNumber(text, '$'); got != want {
+			t.Errorf("IsNumber(%q) = %v, want %v", text, got, want)
+		}
+	}
+
+	// XLSX files store numbers the canonical way whatever the locale
+	cases = map[string]bool{"1234.5": true, "$12": true, "1,234": false, "2,5": false, "": false}
+	for text, want := range cases {
+		if got := IsCanonicalNumber(text, '$'); got != want {
+			t.Errorf("IsCanonicalNumber(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
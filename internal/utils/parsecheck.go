@@ -18,8 +18,25 @@ var Separators = []rune{'Ø', '.', ',', '`', '\'', '"', '_'};
 var DecimalSeparators = Separators
 var FinancialSigns = []rune{'$', '€', '£', '¥', '₩', '₹', '₽', 'R', '₱', '₿', 'Ξ'}
 
-// Checks if a given string is a number
+// Checks if a given string is a number, written the way the current locale writes numbers
 func IsNumber(s string, financialsign rune) bool {
+    _, err := ParseNumber(s, financialsign)
+    return err == nil
+}
+
+// Reads a number written the way the current locale writes numbers, like 1.234,5 in de-DE
+func ParseNumber(s string, financialsign rune) (float64, error) {
+    s = strings.TrimSpace(s)
+	s = strings.Trim(s, string(financialsign))
+    if s == "" {
+        return 0, strconv.ErrSyntax
+    }
+    return CurrentLocale.ParseNumber(s)
+}
+
+// Checks if a given string is a number written the canonical way, like 1234.5 whatever the locale, the way
+// XLSX files store numbers
+func IsCanonicalNumber(s string, financialsign rune) bool {
     s = strings.TrimSpace(s)
 	s = strings.Trim(s, string(financialsign))
     if s == "" {
@@ -39,6 +39,9 @@ var (
 	DEFAULT_VIEWPORT_ROWS int32
 
 	DEFAULT_RECENT_FILES_NUMBER int = 10
+
+	DEFAULT_MAX_ITERATIONS int = 100
+	DEFAULT_MAX_CHANGE float64 = 0.001
 )
 
 type ColorRGB [3]uint8
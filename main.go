@@ -14,6 +14,7 @@ import (
 	"gosheet/internal/services/table"
 	"gosheet/internal/services/ui/file"
 	"gosheet/internal/utils"
+	"gosheet/internal/utils/evaluatefuncs"
 	"log"
 	"os"
 
@@ -29,6 +30,11 @@ func main() {
 
 	utils.UpdateNrCellsOnScrn()
 
+	// Formulas and numbers are typed in the locale chosen last, or else in the one of the system
+	if !utils.SetLocale(fileop.GetLocaleSetting()) {
+		utils.SetLocale(utils.SystemLocale())
+	}
+
 	app := tview.NewApplication()
 	
 	defer func() {
@@ -99,6 +105,11 @@ func main() {
 		panic(err)
 	}
 
+	evaluatefuncs.ClosePlugins()
+	for _, err := range evaluatefuncs.PluginErrors() {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
 	if len(utils.TOBEPRINTED) > 0 { fmt.Println(utils.TOBEPRINTED) }
 
 
@@ -0,0 +1,166 @@
+// Copyright (c) 2025 @drclcomputers. All rights reserved.
+//
+// This work is licensed under the terms of the MIT license.
+// For a copy, see <https://opensource.org/licenses/MIT>.
+
+// A stub GoSheet plugin, serving a few functions over the stdin/stdout JSON protocol.
+// Build it into the plugin directory to try it out:
+//
+//	go build -o ~/.gosheet/plugins/stub ./plugins/stub
+//
+// Setting STUB_START_DELAY to a duration, like 10s, makes it wait that long before answering, to try out
+// the start timeout.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+type argument struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Optional    bool   `json:"optional,omitempty"`
+}
+
+type function struct {
+	Name        string     `json:"name"`
+	MinArgs     int        `json:"min_args"`
+	MaxArgs     int        `json:"max_args"`
+	Description string     `json:"description"`
+	Args        []argument `json:"args,omitempty"`
+}
+
+type request struct {
+	ID       int    `json:"id"`
+	Method   string `json:"method"`
+	Function string `json:"function"`
+	Args     []any  `json:"args"`
+}
+
+type callError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type response struct {
+	ID        int        `json:"id"`
+	Functions []function `json:"functions,omitempty"`
+	Result    any        `json:"result,omitempty"`
+	Error     *callError `json:"error,omitempty"`
+}
+
+var manifest = []function{
+	{Name: "STUB.VAT", MinArgs: 1, MaxArgs: 2, Description: "Adds VAT to an amount, 19% unless a rate is given", Args: []argument{
+		{Name: "amount", Description: "the net amount"},
+		{Name: "rate", Description: "the VAT rate, 0.19 by default", Optional: true},
+	}},
+	{Name: "STUB.TOTAL", MinArgs: 1, MaxArgs: -1, Description: "Sums the numbers of its arguments and ranges"},
+	{Name: "STUB.SPLIT", MinArgs: 1, MaxArgs: 2, Description: "Splits a text at a separator (a comma by default) into a row"},
+	{Name: "STUB.SLEEP", MinArgs: 1, MaxArgs: 1, Description: "Waits the given seconds before answering, to try out timeouts"},
+	{Name: "STUB.FAIL", MinArgs: 0, MaxArgs: 1, Description: "Fails with the given error code, #VALUE! by default"},
+}
+
+func main() {
+	if delay, err := time.ParseDuration(os.Getenv("STUB_START_DELAY")); err == nil {
+		time.Sleep(delay)
+	}
+
+	in := bufio.NewScanner(os.Stdin)
+	in.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	out := json.NewEncoder(os.Stdout)
+
+	for in.Scan() {
+		var req request
+		if err := json.Unmarshal(in.Bytes(), &req); err != nil {
+			fmt.Fprintln(os.Stderr, "stub:", err)
+			continue
+		}
+
+		resp := response{ID: req.ID}
+		switch req.Method {
+		case "manifest":
+			resp.Functions = manifest
+		case "call":
+			result, err := call(req.Function, req.Args)
+			if err != nil {
+				resp.Error = err
+			} else {
+				resp.Result = result
+			}
+		default:
+			resp.Error = &callError{Code: "#VALUE!", Message: "unknown method " + req.Method}
+		}
+		out.Encode(resp)
+	}
+}
+
+func call(name string, args []any) (any, *callError) {
+	switch name {
+	case "STUB.VAT":
+		amount, ok := args[0].(float64)
+		if !ok {
+			return nil, &callError{Code: "#VALUE!", Message: "the amount must be a number"}
+		}
+		rate := 0.19
+		if len(args) > 1 {
+			if rate, ok = args[1].(float64); !ok || rate < 0 {
+				return nil, &callError{Code: "#NUM!", Message: "the rate must be a number of at least 0"}
+			}
+		}
+		return amount * (1 + rate), nil
+
+	case "STUB.TOTAL":
+		total := 0.0
+		var add func(v any)
+		add = func(v any) {
+			switch val := v.(type) {
+			case float64:
+				total += val
+			case []any:
+				for _, item := range val {
+					add(item)
+				}
+			}
+		}
+		for _, arg := range args {
+			add(arg)
+		}
+		return total, nil
+
+	case "STUB.SPLIT":
+		text, _ := args[0].(string)
+		sep := ","
+		if len(args) > 1 {
+			if s, ok := args[1].(string); ok && s != "" {
+				sep = s
+			}
+		}
+		parts := strings.Split(text, sep)
+		row := make([]any, len(parts))
+		for i, part := range parts {
+			row[i] = strings.TrimSpace(part)
+		}
+		return row, nil
+
+	case "STUB.SLEEP":
+		seconds, _ := args[0].(float64)
+		time.Sleep(time.Duration(seconds * float64(time.Second)))
+		return seconds, nil
+
+	case "STUB.FAIL":
+		code := "#VALUE!"
+		if len(args) > 0 {
+			if s, ok := args[0].(string); ok && s != "" {
+				code = s
+			}
+		}
+		return nil, &callError{Code: code, Message: "failed on request"}
+	}
+	return nil, &callError{Code: "#NAME?", Message: "unknown function " + name}
+}